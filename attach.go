@@ -0,0 +1,69 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// AttachContainer ~ Attaches streams to the main process of a running container, like `docker attach`.
+// Blocks until the container closes its output or the context is done
+func AttachContainer(ctx context.Context, containerID string, streams AttachStreams) error {
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	tty := containerJSON.Config != nil && containerJSON.Config.Tty
+
+	resp, err := DockerClient.ContainerAttach(ctx, containerID, container.AttachOptions{
+		Stream: true,
+		Stdin:  streams.Stdin != nil,
+		Stdout: streams.Stdout != nil,
+		Stderr: streams.Stderr != nil,
+	})
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO ATTACH TO CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer resp.Close()
+
+	if streams.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, streams.Stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	stdout := streams.Stdout
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	stderr := streams.Stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	outputDone := make(chan error, 1)
+	go func() {
+		var copyErr error
+		// TTY containers do not multiplex their output, everything arrives on stdout
+		if tty {
+			_, copyErr = io.Copy(stdout, resp.Reader)
+		} else {
+			_, copyErr = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+		}
+		outputDone <- copyErr
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case copyErr := <-outputDone:
+		if copyErr != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO COPY OUTPUT OF CONTAINER WITH ID: " + containerID + " => " + copyErr.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// AuditSink ~ Stores audit records
+type AuditSink interface {
+	Record(record AuditRecord) error
+}
+
+// AuditSinkFunc ~ Adapts a function to an AuditSink
+type AuditSinkFunc func(record AuditRecord) error
+
+// Record ~ Implements AuditSink
+func (f AuditSinkFunc) Record(record AuditRecord) error {
+	return f(record)
+}
+
+// JSONLinesSink ~ Writes every audit record as a line of JSON
+type JSONLinesSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesSink ~ Creates a sink writing JSON lines to w
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// NewFileAuditSink ~ Creates a sink appending JSON lines to the file at path, creating it with mode 0600 if needed
+func NewFileAuditSink(path string) (*JSONLinesSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesSink{w: file, closer: file}, nil
+}
+
+// Record ~ Implements AuditSink
+func (s *JSONLinesSink) Record(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close ~ Closes the file of a sink created with NewFileAuditSink, does nothing otherwise
+func (s *JSONLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+type actorKey struct{}
+
+// WithActor ~ Returns a context that attributes the operations run with it to actor in the audit log
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext ~ Returns the actor set with WithActor, if any
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// Auditor ~ Records every mutating operation of the package to a sink. Every operation reported to observers changes daemon state,
+// reads such as inspects and lists are never reported. Operations made of several daemon calls are recorded once under their own
+// name: SystemPrune for its prunes, BackupVolume and RestoreVolume for their helper containers, PullImage for a pull through a mirror
+// (the tag back to the original name is recorded as TagImage). Calls made directly on DockerClient bypass the auditor
+type Auditor struct {
+	sink AuditSink
+	opts AuditOptions
+}
+
+// NewAuditor ~ Creates an auditor recording to sink
+func NewAuditor(sink AuditSink, opts AuditOptions) *Auditor {
+	return &Auditor{sink: sink, opts: opts}
+}
+
+// EnableAudit ~ Creates an auditor recording to sink and starts observing the package with it. Stop it with RemoveObserver
+func EnableAudit(sink AuditSink, opts AuditOptions) *Auditor {
+	auditor := NewAuditor(sink, opts)
+	AddObserver(auditor)
+	return auditor
+}
+
+// OperationStarted ~ Implements Observer
+func (a *Auditor) OperationStarted(ctx context.Context, op Operation) context.Context {
+	return ctx
+}
+
+// OperationFinished ~ Implements Observer
+func (a *Auditor) OperationFinished(ctx context.Context, op Operation) {
+	record := AuditRecord{
+		Time:       op.Start,
+		Actor:      a.opts.DefaultActor,
+		Operation:  op.Name,
+		Target:     op.Target,
+		Image:      op.Image,
+		Parameters: op.Parameters,
+		DurationMs: op.Duration.Milliseconds(),
+		Result:     "success",
+	}
+	if actor, ok := ActorFromContext(ctx); ok {
+		record.Actor = actor
+	}
+	if op.Err != nil {
+		record.Result = "error"
+		record.Error = op.Err.Error()
+		record.ErrorType = ErrorType(op.Err)
+	}
+
+	if err := a.sink.Record(record); err != nil && a.opts.OnError != nil {
+		a.opts.OnError(record, err)
+	}
+}
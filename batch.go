@@ -0,0 +1,102 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BatchError ~ The failures of a batch operation, keyed by the container or image they concern
+type BatchError struct {
+	Operation string
+	Total     int
+	Failures  map[string]error
+}
+
+func (e *BatchError) Error() string {
+	targets := make([]string, 0, len(e.Failures))
+	for target := range e.Failures {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	failures := make([]string, 0, len(targets))
+	for _, target := range targets {
+		failures = append(failures, target+" => "+e.Failures[target].Error())
+	}
+	return "[ERR:] [BATCH] => " + e.Operation + " FAILED FOR " + fmt.Sprint(len(e.Failures)) + " OF " + fmt.Sprint(e.Total) + " => " + strings.Join(failures, " | ")
+}
+
+// Unwrap ~ Exposes the individual failures to errors.Is and errors.As
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// StopContainers ~ Stops containers with at most concurrency stops in flight. Returns the containers that stopped and a *BatchError for the rest
+func StopContainers(ctx context.Context, containerIDs []string, concurrency int) ([]string, error) {
+	return runBatch(ctx, "STOP CONTAINERS", containerIDs, concurrency, func(ctx context.Context, containerID string) error {
+		return StopContainerContext(ctx, containerID)
+	})
+}
+
+// PurgeContainers ~ Purges containers with at most concurrency removals in flight. Returns the containers purged and a *BatchError for the rest
+func PurgeContainers(ctx context.Context, containerIDs []string, concurrency int) ([]string, error) {
+	return runBatch(ctx, "PURGE CONTAINERS", containerIDs, concurrency, func(ctx context.Context, containerID string) error {
+		return PurgeContainerContext(ctx, containerID)
+	})
+}
+
+// PullImages ~ Pulls images with at most concurrency pulls in flight. Returns the images pulled and a *BatchError for the rest
+func PullImages(ctx context.Context, images []string, concurrency int) ([]string, error) {
+	return runBatch(ctx, "PULL IMAGES", images, concurrency, PullImage)
+}
+
+// runBatch applies fn to every target on a bounded number of goroutines. Targets not yet started when the context is done fail with its error
+func runBatch(ctx context.Context, operation string, targets []string, concurrency int, fn func(ctx context.Context, target string) error) ([]string, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]error, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = errors.New("[ERR:] [BATCH] => NOT STARTED => " + err.Error())
+					continue
+				}
+				results[i] = fn(ctx, targets[i])
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var succeeded []string
+	failures := map[string]error{}
+	for i, err := range results {
+		if err != nil {
+			failures[targets[i]] = err
+		} else {
+			succeeded = append(succeeded, targets[i])
+		}
+	}
+	if len(failures) > 0 {
+		return succeeded, &BatchError{Operation: operation, Total: len(targets), Failures: failures}
+	}
+	return succeeded, nil
+}
@@ -0,0 +1,155 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/archive"
+)
+
+// BuildImages ~ Builds images with at most concurrency builds in flight. The build output of every image is written to progress line by line,
+// prefixed with "[<first tag>] ". progress may be nil. Results are in the order of specs, the error aggregates every failed build
+func BuildImages(ctx context.Context, specs []BuildSpec, concurrency int, progress io.Writer) ([]BuildResult, error) {
+	var mu sync.Mutex
+	results := make([]BuildResult, len(specs))
+	names := make([]string, len(specs))
+	index := map[string]int{}
+	for i, spec := range specs {
+		if len(spec.Tags) == 0 {
+			return nil, errors.New("[ERR:] [DOCKER] => EVERY BUILD SPEC NEEDS AT LEAST ONE TAG")
+		}
+		names[i] = spec.Tags[0]
+		if _, ok := index[names[i]]; ok {
+			return nil, errors.New("[ERR:] [DOCKER] => IMAGE " + names[i] + " IS BUILT BY MORE THAN ONE SPEC")
+		}
+		index[names[i]] = i
+	}
+
+	_, err := runBatch(ctx, "BUILD IMAGES", names, concurrency, func(ctx context.Context, name string) error {
+		i := index[name]
+		out := &prefixedWriter{prefix: "[" + name + "] ", w: progress, mu: &mu}
+		start := time.Now()
+		if dryRun(OpBuildImage, name, name) {
+			results[i] = BuildResult{Image: name}
+			return nil
+		}
+		ctx, done := beginOperation(ctx, OpBuildImage, name, name)
+		imageID, err := buildImage(ctx, specs[i], out)
+		done(err)
+		out.flush()
+		results[i] = BuildResult{Image: name, ImageID: imageID, Duration: time.Since(start), Err: err}
+		return err
+	})
+	return results, err
+}
+
+// buildImage builds a single spec, writing the build output to out, and returns the ID of the image
+func buildImage(ctx context.Context, spec BuildSpec, out io.Writer) (string, error) {
+	name := spec.Tags[0]
+	buildCtx, err := archive.Tar(spec.Context, archive.Uncompressed)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO CREATE BUILD CONTEXT FOR IMAGE " + name + " => " + err.Error())
+	}
+	defer buildCtx.Close()
+
+	dockerfile := spec.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	buildArgs := make(map[string]*string, len(spec.BuildArgs))
+	for key, value := range spec.BuildArgs {
+		value := value
+		buildArgs[key] = &value
+	}
+
+	res, err := DockerClient.ImageBuild(ctx, buildCtx, types.ImageBuildOptions{
+		Dockerfile: dockerfile,
+		Tags:       spec.Tags,
+		BuildArgs:  buildArgs,
+		Labels:     withManagedLabel(spec.Labels),
+		NoCache:    spec.NoCache,
+		PullParent: spec.PullParent,
+		Remove:     true,
+	})
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO BUILD IMAGE " + name + " => " + err.Error())
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var buildOut ImageBuildOut
+		if err := decoder.Decode(&buildOut); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", errors.New("[ERR:] [DOCKER] => FAILED TO READ BUILD OUTPUT OF IMAGE " + name + " => " + err.Error())
+		}
+		if message := buildOut.errorMessage(); message != "" {
+			return "", errors.New("[ERR:] [DOCKER] => FAILED TO BUILD IMAGE " + name + " => " + message)
+		}
+		switch {
+		case buildOut.Stream != "":
+			io.WriteString(out, buildOut.Stream)
+		case buildOut.Status != "":
+			io.WriteString(out, strings.TrimSpace(buildOut.Id+" "+buildOut.Status+" "+buildOut.Progress)+"\n")
+		}
+	}
+
+	inspect, _, err := DockerClient.ImageInspectWithRaw(ctx, name)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT BUILT IMAGE " + name + " => " + err.Error())
+	}
+	return inspect.ID, nil
+}
+
+// errorMessage returns the error reported by a build or pull stream message, from Error or else from ErrorDetail
+func (out ImageBuildOut) errorMessage() string {
+	if out.Error != "" {
+		return out.Error
+	}
+	return out.ErrorDetail.Message
+}
+
+// prefixedWriter writes complete lines to w with a prefix, sharing mu with the writers of other builds so lines never interleave
+type prefixedWriter struct {
+	prefix string
+	w      io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (p *prefixedWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.buf[:i])
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+func (p *prefixedWriter) flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(p.buf)
+		p.buf = nil
+	}
+}
+
+func (p *prefixedWriter) writeLine(line []byte) {
+	if p.w == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.w.Write(append(append([]byte(p.prefix), line...), '\n'))
+}
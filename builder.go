@@ -0,0 +1,192 @@
+package containers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+// containerNamePattern is the name format accepted by the daemon
+var containerNamePattern = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ContainerBuilder ~ Builds a ContainerCreateConfig step by step. Errors are collected and reported by Build
+type ContainerBuilder struct {
+	config *ContainerCreateConfig
+	errs   []error
+}
+
+// NewContainer ~ Starts building the config of a container with the given name
+func NewContainer(name string) *ContainerBuilder {
+	return &ContainerBuilder{
+		config: &ContainerCreateConfig{
+			Name:             name,
+			Config:           &container.Config{},
+			HostConfig:       &container.HostConfig{},
+			NetworkingConfig: &network.NetworkingConfig{},
+		},
+	}
+}
+
+// Image ~ Sets the image of the container
+func (b *ContainerBuilder) Image(image string) *ContainerBuilder {
+	b.config.Config.Image = image
+	return b
+}
+
+// Cmd ~ Sets the command of the container
+func (b *ContainerBuilder) Cmd(cmd ...string) *ContainerBuilder {
+	b.config.Config.Cmd = cmd
+	return b
+}
+
+// Entrypoint ~ Sets the entrypoint of the container
+func (b *ContainerBuilder) Entrypoint(entrypoint ...string) *ContainerBuilder {
+	b.config.Config.Entrypoint = entrypoint
+	return b
+}
+
+// Env ~ Adds environment variables in KEY=VALUE form
+func (b *ContainerBuilder) Env(env ...string) *ContainerBuilder {
+	for _, e := range env {
+		if key, _, found := strings.Cut(e, "="); !found || key == "" {
+			b.errs = append(b.errs, errors.New("INVALID ENVIRONMENT VARIABLE "+e+", EXPECTED KEY=VALUE"))
+			continue
+		}
+		b.config.Config.Env = append(b.config.Config.Env, e)
+	}
+	return b
+}
+
+// EnvFile ~ Adds the environment variables of a .env file, see LoadEnvFile
+func (b *ContainerBuilder) EnvFile(path string) *ContainerBuilder {
+	env, err := LoadEnvFile(path)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.config.Config.Env = append(b.config.Config.Env, EnvMapToSlice(env)...)
+	return b
+}
+
+// Label ~ Adds a label to the container
+func (b *ContainerBuilder) Label(key string, value string) *ContainerBuilder {
+	if b.config.Config.Labels == nil {
+		b.config.Config.Labels = map[string]string{}
+	}
+	b.config.Config.Labels[key] = value
+	return b
+}
+
+// WorkingDir ~ Sets the working directory of the container
+func (b *ContainerBuilder) WorkingDir(dir string) *ContainerBuilder {
+	b.config.Config.WorkingDir = dir
+	return b
+}
+
+// User ~ Sets the user the container runs as
+func (b *ContainerBuilder) User(user string) *ContainerBuilder {
+	b.config.Config.User = user
+	return b
+}
+
+// Port ~ Publishes a TCP container port on a host port
+func (b *ContainerBuilder) Port(hostPort int, containerPort int) *ContainerBuilder {
+	if hostPort < 0 || hostPort > 65535 || containerPort < 1 || containerPort > 65535 {
+		b.errs = append(b.errs, errors.New("INVALID PORT MAPPING "+fmt.Sprint(hostPort)+":"+fmt.Sprint(containerPort)))
+		return b
+	}
+	return b.PublishPort(strconv.Itoa(hostPort) + ":" + strconv.Itoa(containerPort) + "/tcp")
+}
+
+// PublishPort ~ Publishes ports from a docker style spec, see PublishPort
+func (b *ContainerBuilder) PublishPort(spec string) *ContainerBuilder {
+	if err := PublishPort(b.config, spec); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// Volume ~ Mounts a host path or a named volume at target
+func (b *ContainerBuilder) Volume(source string, target string) *ContainerBuilder {
+	if source == "" || !strings.HasPrefix(target, "/") {
+		b.errs = append(b.errs, errors.New("INVALID VOLUME "+source+":"+target+", EXPECTED A SOURCE AND AN ABSOLUTE TARGET"))
+		return b
+	}
+	b.config.HostConfig.Binds = append(b.config.HostConfig.Binds, source+":"+target)
+	return b
+}
+
+// Mount ~ Adds typed mounts, see BindMount, VolumeMount and TmpfsMount
+func (b *ContainerBuilder) Mount(mounts ...mount.Mount) *ContainerBuilder {
+	for _, m := range mounts {
+		if !strings.HasPrefix(m.Target, "/") {
+			b.errs = append(b.errs, errors.New("INVALID MOUNT TARGET "+m.Target+", EXPECTED AN ABSOLUTE PATH"))
+			continue
+		}
+		AddMounts(b.config, m)
+	}
+	return b
+}
+
+// Restart ~ Sets the restart policy, see RestartAlways, RestartOnFailure and RestartUnlessStopped
+func (b *ContainerBuilder) Restart(policy container.RestartPolicy) *ContainerBuilder {
+	if err := container.ValidateRestartPolicy(policy); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	SetRestartPolicy(b.config, policy)
+	return b
+}
+
+// Healthcheck ~ Sets the healthcheck, see HealthcheckCmd and HealthcheckShell
+func (b *ContainerBuilder) Healthcheck(healthcheck *container.HealthConfig) *ContainerBuilder {
+	SetHealthcheck(b.config, healthcheck)
+	return b
+}
+
+// Network ~ Connects the container to a network on creation
+func (b *ContainerBuilder) Network(name string, aliases ...string) *ContainerBuilder {
+	if b.config.NetworkingConfig.EndpointsConfig == nil {
+		b.config.NetworkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{}
+	}
+	b.config.NetworkingConfig.EndpointsConfig[name] = &network.EndpointSettings{Aliases: aliases}
+	if b.config.HostConfig.NetworkMode == "" {
+		b.config.HostConfig.NetworkMode = container.NetworkMode(name)
+	}
+	return b
+}
+
+// With ~ Applies options to the config, e.g. WithMemoryLimit or WithCPUs
+func (b *ContainerBuilder) With(opts ...ContainerOption) *ContainerBuilder {
+	for _, opt := range opts {
+		if err := opt(b.config); err != nil {
+			b.errs = append(b.errs, err)
+		}
+	}
+	return b
+}
+
+// Build ~ Validates and returns the config
+func (b *ContainerBuilder) Build() (*ContainerCreateConfig, error) {
+	errs := b.errs
+	if b.config.Name != "" && !containerNamePattern.MatchString(b.config.Name) {
+		errs = append(errs, errors.New("INVALID CONTAINER NAME "+b.config.Name))
+	}
+	if b.config.Config.Image == "" {
+		errs = append(errs, errors.New("NO IMAGE SET"))
+	}
+	if len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return nil, errors.New("[ERR:] [DOCKER] => INVALID CONFIG FOR CONTAINER " + b.config.Name + " => " + strings.Join(messages, " | "))
+	}
+	return b.config, nil
+}
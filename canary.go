@@ -0,0 +1,119 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Canary ~ Runs image as canary containers next to the stable replicas, observes them and either promotes the image to every replica
+// through RollingUpdate or discards the canaries and leaves the replicas untouched. The canaries copy the config of the first replica
+// without its host port bindings, they only receive traffic through the network alias
+func Canary(ctx context.Context, replicas []string, image string, opts CanaryOptions) (CanaryResult, error) {
+	if len(replicas) == 0 {
+		return CanaryResult{}, errors.New("[ERR:] [CANARY] => NO REPLICAS TO RUN A CANARY AGAINST")
+	}
+	if opts.Weight < 1 {
+		opts.Weight = 1
+	}
+	if opts.HealthTimeout == 0 {
+		opts.HealthTimeout = time.Minute
+	}
+	if opts.HealthInterval == 0 {
+		opts.HealthInterval = time.Second
+	}
+
+	template, err := DockerClient.ContainerInspect(ctx, replicas[0])
+	if err != nil {
+		return CanaryResult{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT REPLICA " + replicas[0] + " => " + err.Error())
+	}
+	templateImage := imageConfigOf(ctx, template.Image)
+	if err := EnsureImage(ctx, image); err != nil {
+		return CanaryResult{}, err
+	}
+
+	var canaryIDs []string
+	defer func() {
+		for _, id := range canaryIDs {
+			PurgeContainerContext(context.WithoutCancel(ctx), id)
+		}
+	}()
+
+	for i := 1; i <= opts.Weight; i++ {
+		config := configFromInspect(template, templateImage)
+		config.Name = replicas[0] + "-canary-" + fmt.Sprint(i)
+		config.Config.Image = image
+		if config.HostConfig != nil {
+			config.HostConfig.PortBindings = nil
+		}
+		joinsAlias := opts.Network != "" && opts.Alias != ""
+		if joinsAlias && config.HostConfig != nil && string(config.HostConfig.NetworkMode) == opts.Network {
+			config.NetworkingConfig = withEndpointAlias(config.NetworkingConfig, opts.Network, opts.Alias)
+			joinsAlias = false
+		}
+
+		created, err := CreateContainerContext(ctx, config)
+		if err != nil {
+			return CanaryResult{}, err
+		}
+		canaryIDs = append(canaryIDs, created.ID)
+		if err := StartContainerContext(ctx, created); err != nil {
+			return CanaryResult{}, err
+		}
+		if joinsAlias {
+			if err := ConnectNetwork(ctx, opts.Network, created.ID, EndpointOptions{Aliases: []string{opts.Alias}}); err != nil {
+				return CanaryResult{}, err
+			}
+		}
+	}
+
+	for _, id := range canaryIDs {
+		if err := waitForReady(ctx, id, opts.HealthTimeout, opts.HealthInterval); err != nil {
+			return CanaryResult{Reason: err.Error()}, nil
+		}
+	}
+
+	if opts.Observe > 0 {
+		select {
+		case <-ctx.Done():
+			return CanaryResult{}, errors.New("[ERR:] [CANARY] => OBSERVATION OF " + image + " CANCELLED => " + ctx.Err().Error())
+		case <-time.After(opts.Observe):
+		}
+	}
+
+	// A canary that crashed while it was observed is discarded even if Check would pass it
+	for _, id := range canaryIDs {
+		containerJSON, err := inspectContainer(ctx, id)
+		if err != nil {
+			return CanaryResult{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CANARY WITH ID: " + id + " => " + err.Error())
+		}
+		if containerJSON.State == nil || !containerJSON.State.Running {
+			return CanaryResult{Reason: "CANARY WITH ID: " + id + " STOPPED WHILE OBSERVED"}, nil
+		}
+		if containerJSON.State.Health != nil && containerJSON.State.Health.Status != types.Healthy {
+			return CanaryResult{Reason: "CANARY WITH ID: " + id + " BECAME " + containerJSON.State.Health.Status + " WHILE OBSERVED"}, nil
+		}
+	}
+	if opts.Check != nil {
+		if err := opts.Check(ctx, canaryIDs); err != nil {
+			return CanaryResult{Reason: err.Error()}, nil
+		}
+	}
+
+	// The canaries have served their purpose, the replicas take over the new image
+	for _, id := range canaryIDs {
+		if err := PurgeContainerContext(ctx, id); err != nil {
+			return CanaryResult{}, err
+		}
+	}
+	canaryIDs = nil
+
+	ids, err := RollingUpdate(ctx, replicas, image, opts.Rollout)
+	if err != nil {
+		return CanaryResult{ReplicaIDs: ids}, err
+	}
+	return CanaryResult{Promoted: true, ReplicaIDs: ids}, nil
+}
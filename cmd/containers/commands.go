@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-units"
+)
+
+func buildCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	var tags, buildArgs listFlag
+	flags.Var(&tags, "t", "name and tag of the image, repeatable")
+	flags.Var(&buildArgs, "build-arg", "build argument KEY=VALUE, repeatable")
+	dockerfile := flags.String("f", "", "path of the Dockerfile within the context")
+	noCache := flags.Bool("no-cache", false, "do not use the build cache")
+	pull := flags.Bool("pull", false, "always pull the base image")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || len(tags) == 0 {
+		return errors.New("[ERR:] [CLI] => USAGE: containers build -t TAG [flags] CONTEXT")
+	}
+	buildArgMap, err := keyValues(buildArgs)
+	if err != nil {
+		return err
+	}
+	results, err := containers.BuildImages(ctx, []containers.BuildSpec{{
+		Context:    flags.Arg(0),
+		Dockerfile: *dockerfile,
+		Tags:       tags,
+		BuildArgs:  buildArgMap,
+		NoCache:    *noCache,
+		PullParent: *pull,
+	}}, 1, os.Stderr)
+	if err != nil {
+		return err
+	}
+	fmt.Println(results[0].ImageID)
+	return nil
+}
+
+func runCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	var env, envFiles listFlag
+	flags.Var(&env, "e", "environment variable KEY=VALUE, repeatable")
+	flags.Var(&envFiles, "env-file", "file with variables for the spec file, repeatable")
+	specFile := flags.String("spec", "", "container definition file")
+	remove := flags.Bool("rm", false, "remove the container once it exits")
+	pull := flags.String("pull", "", "pull policy: Always, IfNotPresent or Never")
+	flags.Parse(args)
+
+	spec := containers.RunSpec{Env: env, PullPolicy: *pull}
+	if *specFile != "" {
+		config, err := containers.LoadSpecWithOptions(*specFile, containers.SpecOptions{EnvFiles: envFiles})
+		if err != nil {
+			return err
+		}
+		spec.Create = config
+	}
+	if flags.NArg() > 0 {
+		spec.Image = flags.Arg(0)
+		spec.Cmd = flags.Args()[1:]
+	}
+	if spec.Image == "" && spec.Create == nil {
+		return errors.New("[ERR:] [CLI] => USAGE: containers run [--spec FILE] [flags] [IMAGE] [COMMAND...]")
+	}
+
+	run := containers.Run
+	if *remove {
+		run = containers.RunAndRemove
+	}
+	result, err := run(ctx, spec)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stdout, result.Stdout)
+	fmt.Fprint(os.Stderr, result.Stderr)
+	if result.ExitCode != 0 {
+		containers.CloseDockerClient()
+		os.Exit(int(result.ExitCode))
+	}
+	return nil
+}
+
+func deployCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	var envFiles listFlag
+	flags.Var(&envFiles, "env-file", "file with variables for the spec file, repeatable")
+	specFile := flags.String("spec", "", "container definition file")
+	networkName := flags.String("network", "", "network to hand the alias over on")
+	alias := flags.String("alias", "", "alias handed over from the old container to the new one")
+	timeout := flags.Duration("timeout", 0, "how long the new container has to become healthy")
+	flags.Parse(args)
+
+	if *specFile == "" {
+		return errors.New("[ERR:] [CLI] => USAGE: containers deploy --spec FILE [flags]")
+	}
+	config, err := containers.LoadSpecWithOptions(*specFile, containers.SpecOptions{EnvFiles: envFiles})
+	if err != nil {
+		return err
+	}
+	id, err := containers.Deploy(ctx, config, containers.DeployOptions{Network: *networkName, Alias: *alias, HealthTimeout: *timeout})
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+func stackCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("[ERR:] [CLI] => USAGE: containers stack up|down|ps -f FILE [flags]")
+	}
+	action := args[0]
+	flags := flag.NewFlagSet("stack "+action, flag.ExitOnError)
+	var profiles, envFiles listFlag
+	flags.Var(&profiles, "profile", "profile to activate, repeatable")
+	flags.Var(&envFiles, "env-file", "file with variables for the stack file, repeatable")
+	stackFile := flags.String("f", "", "stack definition file")
+	flags.Parse(args[1:])
+
+	if *stackFile == "" {
+		return errors.New("[ERR:] [CLI] => USAGE: containers stack " + action + " -f FILE [flags]")
+	}
+	stack, err := containers.LoadStackWithOptions(*stackFile, containers.SpecOptions{EnvFiles: envFiles, Profiles: profiles})
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		return stack.StartAll(ctx)
+	case "down":
+		if err := stack.StopAll(ctx); err != nil {
+			return err
+		}
+		// Converging to nothing removes every container of the stack, whatever its profile
+		_, err := containers.NewReconciler(stack.Name).Apply(ctx, nil)
+		return err
+	case "ps":
+		statuses, err := stack.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MEMBER\tCONTAINER\tSTATE\tHEALTH")
+		for _, status := range statuses {
+			id := status.ContainerID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			fmt.Fprintln(w, status.Name+"\t"+id+"\t"+status.State+"\t"+status.Health)
+		}
+		return w.Flush()
+	}
+	return errors.New("[ERR:] [CLI] => UNKNOWN STACK ACTION " + action + ", EXPECTED up, down OR ps")
+}
+
+func pruneCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("prune", flag.ExitOnError)
+	all := flags.Bool("all", false, "remove every unused image, not only dangling ones")
+	volumes := flags.Bool("volumes", false, "remove unused anonymous volumes")
+	until := flags.String("until", "", "only remove resources older than this, e.g. 24h")
+	flags.Parse(args)
+
+	report, err := containers.SystemPrune(ctx, containers.SystemPruneOptions{AllImages: *all, Volumes: *volumes, Until: *until})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("removed %d containers, %d images, %d networks and %d volumes, reclaimed %s\n",
+		len(report.Containers.ContainersDeleted), len(report.Images.ImagesDeleted), len(report.Networks.NetworksDeleted),
+		len(report.Volumes.VolumesDeleted), units.BytesSize(float64(report.SpaceReclaimed)))
+	return nil
+}
+
+func logsCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ExitOnError)
+	follow := flags.Bool("f", false, "follow the logs")
+	tail := flags.String("tail", "all", "number of lines to show from the end")
+	timestamps := flags.Bool("timestamps", false, "prefix lines with their timestamp")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 || (flags.NArg() > 1 && !*follow) {
+		return errors.New("[ERR:] [CLI] => USAGE: containers logs [flags] CONTAINER | containers logs -f [flags] CONTAINER...")
+	}
+	containerID := flags.Arg(0)
+	opts := container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: *tail, Timestamps: *timestamps}
+
+	if flags.NArg() > 1 {
+		// Color the prefixes only on a terminal
+		info, _ := os.Stdout.Stat()
+		router := containers.NewLogRouter(containers.LogRouterOptions{
+			Writers:    []io.Writer{os.Stdout},
+			Logs:       opts,
+			Color:      info != nil && info.Mode()&os.ModeCharDevice != 0,
+			Timestamps: *timestamps,
+		})
+		return router.Run(ctx, flags.Args()...)
+	}
+
+	if *follow {
+		return containers.StreamContainerLogs(ctx, containerID, opts, func(line containers.LogLine) {
+			out := os.Stdout
+			if line.Stream == containers.LogStreamStderr {
+				out = os.Stderr
+			}
+			if *timestamps {
+				fmt.Fprint(out, line.Timestamp.Format(time.RFC3339Nano)+" ")
+			}
+			fmt.Fprintln(out, line.Message)
+		})
+	}
+
+	containerJSON, err := containers.DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	logs, err := containers.DockerClient.ContainerLogs(ctx, containerID, opts)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer logs.Close()
+	// TTY containers do not multiplex their output
+	if containerJSON.Config != nil && containerJSON.Config.Tty {
+		_, err = os.Stdout.ReadFrom(logs)
+	} else {
+		_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, logs)
+	}
+	return err
+}
+
+func statsCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	stream := flags.Bool("stream", false, "keep printing samples until interrupted")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("[ERR:] [CLI] => USAGE: containers stats [--stream] CONTAINER")
+	}
+	containerID := flags.Arg(0)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+	show := func(stats containers.ContainerStats) {
+		fmt.Fprintln(w, strconv.FormatFloat(stats.CPUPercent, 'f', 2, 64)+"%\t"+
+			units.BytesSize(float64(stats.MemoryUsage))+" / "+units.BytesSize(float64(stats.MemoryLimit))+"\t"+
+			strconv.FormatFloat(stats.MemoryPercent, 'f', 2, 64)+"%\t"+
+			units.HumanSize(float64(stats.NetworkRx))+" / "+units.HumanSize(float64(stats.NetworkTx))+"\t"+
+			units.HumanSize(float64(stats.BlockRead))+" / "+units.HumanSize(float64(stats.BlockWrite))+"\t"+
+			strconv.FormatUint(stats.PIDs, 10))
+		w.Flush()
+	}
+
+	if *stream {
+		return containers.StreamContainerStats(ctx, containerID, show)
+	}
+	stats, err := containers.GetContainerStats(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	show(stats)
+	return nil
+}
@@ -0,0 +1,99 @@
+// Command containers exposes the high-level operations of the containers package to shell scripts.
+//
+//	containers build -t TAG [-f DOCKERFILE] [--build-arg KEY=VALUE] [--no-cache] [--pull] CONTEXT
+//	containers run [--spec FILE] [-e KEY=VALUE] [--rm] [IMAGE] [COMMAND...]
+//	containers deploy --spec FILE [--network NETWORK --alias ALIAS] [--timeout DURATION]
+//	containers stack up|down|ps -f FILE [--profile PROFILE]
+//	containers prune [--all] [--volumes] [--until DURATION]
+//	containers logs [-f] [--tail N] [--timestamps] CONTAINER
+//	containers stats [--stream] CONTAINER
+//
+// Spec and stack files are read with LoadSpecWithOptions and LoadStackWithOptions, --env-file adds variables to them
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/G-MAKROGLOU/containers"
+)
+
+// commands ~ The subcommands by name
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"build":  buildCommand,
+	"run":    runCommand,
+	"deploy": deployCommand,
+	"stack":  stackCommand,
+	"prune":  pruneCommand,
+	"logs":   logsCommand,
+	"stats":  statsCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		usage()
+		return
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "unknown command "+os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := containers.InitializeDockerClient(); err != nil {
+		fail(err)
+	}
+	defer containers.CloseDockerClient()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := command(ctx, os.Args[2:]); err != nil {
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: containers <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands: build, run, deploy, stack, prune, logs, stats")
+	fmt.Fprintln(os.Stderr, "run containers <command> -h for the flags of a command")
+}
+
+// fail reports err and exits, closing the client first since deferred calls do not run on os.Exit
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	if containers.DockerClient != nil {
+		containers.CloseDockerClient()
+	}
+	os.Exit(1)
+}
+
+// listFlag ~ A flag that can be repeated, collecting every value
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// keyValues turns KEY=VALUE flags into a map
+func keyValues(values []string) (map[string]string, error) {
+	pairs := make(map[string]string, len(values))
+	for _, value := range values {
+		key, v, found := strings.Cut(value, "=")
+		if !found || key == "" {
+			return nil, errors.New("[ERR:] [CLI] => EXPECTED KEY=VALUE, GOT " + value)
+		}
+		pairs[key] = v
+	}
+	return pairs, nil
+}
@@ -0,0 +1,273 @@
+// Package compose drives docker-compose.yml projects through the containers package
+package compose
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/G-MAKROGLOU/containers/internal/yamlenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Project ~ A parsed compose file. Names of networks and volumes are the keys of the file, not the prefixed daemon names
+type Project struct {
+	Name       string
+	WorkingDir string
+	Services   map[string]Service
+	Networks   map[string]Network
+	Volumes    map[string]Volume
+}
+
+// Service ~ A service of a compose file
+type Service struct {
+	Image         string               `yaml:"image,omitempty"`
+	Build         *Build               `yaml:"build,omitempty"`
+	ContainerName string               `yaml:"container_name,omitempty"`
+	Command       stringOrList         `yaml:"command,omitempty"`
+	Entrypoint    stringOrList         `yaml:"entrypoint,omitempty"`
+	Environment   mappingOrList        `yaml:"environment,omitempty"`
+	EnvFile       stringOrList         `yaml:"env_file,omitempty"`
+	Ports         []string             `yaml:"ports,omitempty"`
+	Volumes       []string             `yaml:"volumes,omitempty"`
+	Networks      serviceNetworks      `yaml:"networks,omitempty"`
+	DependsOn     dependsOn            `yaml:"depends_on,omitempty"`
+	Healthcheck   *Healthcheck         `yaml:"healthcheck,omitempty"`
+	Restart       string               `yaml:"restart,omitempty"`
+	Labels        mappingOrList        `yaml:"labels,omitempty"`
+	WorkingDir    string               `yaml:"working_dir,omitempty"`
+	User          string               `yaml:"user,omitempty"`
+	Tty           bool                 `yaml:"tty,omitempty"`
+	StdinOpen     bool                 `yaml:"stdin_open,omitempty"`
+	Extra         map[string]yaml.Node `yaml:",inline"`
+}
+
+// Build ~ The build section of a service. The short syntax sets only the context
+type Build struct {
+	Context    string `yaml:"context,omitempty"`
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+}
+
+// Healthcheck ~ The healthcheck section of a service
+type Healthcheck struct {
+	Test        healthTest `yaml:"test,omitempty"`
+	Interval    string     `yaml:"interval,omitempty"`
+	Timeout     string     `yaml:"timeout,omitempty"`
+	Retries     int        `yaml:"retries,omitempty"`
+	StartPeriod string     `yaml:"start_period,omitempty"`
+	Disable     bool       `yaml:"disable,omitempty"`
+}
+
+// Network ~ A top level network of a compose file
+type Network struct {
+	Driver     string            `yaml:"driver,omitempty"`
+	External   bool              `yaml:"external,omitempty"`
+	Internal   bool              `yaml:"internal,omitempty"`
+	Attachable bool              `yaml:"attachable,omitempty"`
+	Name       string            `yaml:"name,omitempty"`
+	Labels     mappingOrList     `yaml:"labels,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+}
+
+// Volume ~ A top level volume of a compose file
+type Volume struct {
+	Driver     string            `yaml:"driver,omitempty"`
+	External   bool              `yaml:"external,omitempty"`
+	Name       string            `yaml:"name,omitempty"`
+	Labels     mappingOrList     `yaml:"labels,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+}
+
+// Dependency ~ A depends_on entry. Condition is service_started, service_healthy or service_completed_successfully
+type Dependency struct {
+	Condition string `yaml:"condition,omitempty"`
+}
+
+type composeFile struct {
+	Name     string              `yaml:"name,omitempty"`
+	Services map[string]Service  `yaml:"services,omitempty"`
+	Networks map[string]*Network `yaml:"networks,omitempty"`
+	Volumes  map[string]*Volume  `yaml:"volumes,omitempty"`
+}
+
+// Load ~ Parses a compose file. An empty project name falls back to the name in the file, then to the name of its directory
+func Load(path string, projectName string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO READ COMPOSE FILE " + path + " => " + err.Error())
+	}
+	workingDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO RESOLVE DIRECTORY OF COMPOSE FILE " + path + " => " + err.Error())
+	}
+	return Parse(data, workingDir, projectName)
+}
+
+// Parse ~ Parses the contents of a compose file. Relative paths are resolved against workingDir and ${VAR} references against
+// the .env file of workingDir and the process environment
+func Parse(data []byte, workingDir string, projectName string) (*Project, error) {
+	dotEnv := map[string]string{}
+	if _, err := os.Stat(filepath.Join(workingDir, ".env")); err == nil {
+		loaded, err := containers.LoadEnvFile(filepath.Join(workingDir, ".env"))
+		if err != nil {
+			return nil, err
+		}
+		dotEnv = loaded
+	}
+	lookup := func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := dotEnv[key]
+		return value, ok
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO PARSE COMPOSE FILE => " + err.Error())
+	}
+	yamlenv.Expand(&document, func(s string) string { return containers.ExpandEnv(s, lookup) })
+	var file composeFile
+	if document.Kind != 0 {
+		if err := document.Decode(&file); err != nil {
+			return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO PARSE COMPOSE FILE => " + err.Error())
+		}
+	}
+
+	if projectName == "" {
+		projectName = file.Name
+	}
+	if projectName == "" {
+		projectName = filepath.Base(workingDir)
+	}
+	project := &Project{
+		Name:       normalizeProjectName(projectName),
+		WorkingDir: workingDir,
+		Services:   file.Services,
+		Networks:   map[string]Network{},
+		Volumes:    map[string]Volume{},
+	}
+	for name, network := range file.Networks {
+		if network == nil {
+			network = &Network{}
+		}
+		project.Networks[name] = *network
+	}
+	for name, volume := range file.Volumes {
+		if volume == nil {
+			volume = &Volume{}
+		}
+		project.Volumes[name] = *volume
+	}
+
+	if err := project.validate(); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// validate checks the references between services, networks and volumes
+func (p *Project) validate() error {
+	if len(p.Services) == 0 {
+		return errors.New("[ERR:] [COMPOSE] => PROJECT " + p.Name + " HAS NO SERVICES")
+	}
+	var problems []string
+	for name, service := range p.Services {
+		if service.Image == "" && service.Build == nil {
+			problems = append(problems, "SERVICE "+name+" HAS NEITHER AN IMAGE NOR A BUILD")
+		}
+		// BuildImage always reads the Dockerfile at the root of the context
+		if service.Build != nil && service.Build.Dockerfile != "" && service.Build.Dockerfile != "Dockerfile" {
+			problems = append(problems, "SERVICE "+name+" USES UNSUPPORTED DOCKERFILE "+service.Build.Dockerfile)
+		}
+		for key := range service.Extra {
+			problems = append(problems, "SERVICE "+name+" USES UNSUPPORTED KEY "+key)
+		}
+		for dependency := range service.DependsOn {
+			if _, ok := p.Services[dependency]; !ok {
+				problems = append(problems, "SERVICE "+name+" DEPENDS ON UNKNOWN SERVICE "+dependency)
+			}
+		}
+		for network := range service.Networks {
+			if _, ok := p.Networks[network]; !ok && network != "default" {
+				problems = append(problems, "SERVICE "+name+" USES UNDECLARED NETWORK "+network)
+			}
+		}
+		for _, volume := range service.Volumes {
+			source, _, _ := strings.Cut(volume, ":")
+			if isNamedVolume(source) {
+				if _, ok := p.Volumes[source]; !ok {
+					problems = append(problems, "SERVICE "+name+" USES UNDECLARED VOLUME "+source)
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("[ERR:] [COMPOSE] => INVALID PROJECT " + p.Name + " => " + strings.Join(problems, " | "))
+	}
+	if _, err := p.ServiceOrder(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ServiceOrder ~ Returns the service names ordered so that every service comes after the services it depends on
+func (p *Project) ServiceOrder() ([]string, error) {
+	names := make([]string, 0, len(p.Services))
+	for name := range p.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var order []string
+	state := map[string]int{} // 1 visiting, 2 done
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return errors.New("[ERR:] [COMPOSE] => DEPENDENCY CYCLE " + strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		dependencies := make([]string, 0, len(p.Services[name].DependsOn))
+		for dependency := range p.Services[name].DependsOn {
+			dependencies = append(dependencies, dependency)
+		}
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// normalizeProjectName lowercases a project name and drops the characters compose does not allow
+func normalizeProjectName(name string) string {
+	var normalized strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
+
+// isNamedVolume reports whether the source of a short volume syntax is a volume name rather than a host path
+func isNamedVolume(source string) bool {
+	return source != "" && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, "~")
+}
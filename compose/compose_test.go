@@ -0,0 +1,75 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExpansion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("TAG=1.25\nPORT=8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PORT", "9090")
+	t.Setenv("GREETING", "a: b")
+
+	project, err := Parse([]byte(`
+services:
+  web:
+    image: nginx:${TAG}
+    command: sh -c 'echo $$HOME ${GREETING}'
+    ports: ["${PORT}:80"]
+    environment:
+      GREETING: ${GREETING}
+      LITERAL: $$NOT_EXPANDED
+      FALLBACK: ${MISSING:-default}
+`), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Name != normalizeProjectName(filepath.Base(dir)) {
+		t.Errorf("project name = %q", project.Name)
+	}
+	web := project.Services["web"]
+	if web.Image != "nginx:1.25" {
+		t.Errorf("image = %q", web.Image)
+	}
+	if want := []string{"sh", "-c", "echo $HOME a: b"}; !reflect.DeepEqual([]string(web.Command), want) {
+		t.Errorf("command = %q, want %q", web.Command, want)
+	}
+	// The process environment wins over .env
+	if want := []string{"9090:80"}; !reflect.DeepEqual(web.Ports, want) {
+		t.Errorf("ports = %q, want %q", web.Ports, want)
+	}
+	if want := (mappingOrList{"GREETING": "a: b", "LITERAL": "$NOT_EXPANDED", "FALLBACK": "default"}); !reflect.DeepEqual(web.Environment, want) {
+		t.Errorf("environment = %q, want %q", web.Environment, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"no services", "name: empty\n", "HAS NO SERVICES"},
+		{"no image", "services:\n  web: {}\n", "HAS NEITHER AN IMAGE NOR A BUILD"},
+		{"unknown dependency", "services:\n  web:\n    image: nginx\n    depends_on: [db]\n", "DEPENDS ON UNKNOWN SERVICE db"},
+		{"undeclared volume", "services:\n  web:\n    image: nginx\n    volumes: [\"data:/data\"]\n", "UNDECLARED VOLUME data"},
+		{"unsupported key", "services:\n  web:\n    image: nginx\n    deploy: {}\n", "UNSUPPORTED KEY deploy"},
+		{"dependency cycle", "services:\n  a:\n    image: x\n    depends_on: [b]\n  b:\n    image: x\n    depends_on: [a]\n", "CYCLE"},
+		{"unterminated quote", "services:\n  web:\n    image: nginx\n    command: echo 'open\n", "UNTERMINATED SINGLE QUOTE"},
+		{"not yaml", "services: [", "FAILED TO PARSE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.file), t.TempDir(), "test")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"gopkg.in/yaml.v3"
+)
+
+// FromContainers ~ Inspects containers and returns a project that reproduces them: images, commands, environment, ports, volumes,
+// networks, healthchecks, restart policies and labels. Settings inherited from the image are left out. Containers of a compose project
+// keep their service names, others are named after the container. Volumes and networks keep their daemon names
+func FromContainers(ctx context.Context, projectName string, containerIDs []string) (*Project, error) {
+	project := &Project{
+		Name:     normalizeProjectName(projectName),
+		Services: map[string]Service{},
+		Networks: map[string]Network{},
+		Volumes:  map[string]Volume{},
+	}
+	for _, id := range containerIDs {
+		containerJSON, err := containers.DockerClient.ContainerInspect(ctx, id)
+		if err != nil {
+			return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO INSPECT CONTAINER WITH ID: " + id + " => " + err.Error())
+		}
+		imageConfig := &container.Config{}
+		if imageJSON, _, err := containers.DockerClient.ImageInspectWithRaw(ctx, containerJSON.Image); err == nil && imageJSON.Config != nil {
+			imageConfig = &container.Config{
+				Cmd:         imageJSON.Config.Cmd,
+				Entrypoint:  imageJSON.Config.Entrypoint,
+				Env:         imageJSON.Config.Env,
+				Labels:      imageJSON.Config.Labels,
+				WorkingDir:  imageJSON.Config.WorkingDir,
+				User:        imageJSON.Config.User,
+				Healthcheck: imageJSON.Config.Healthcheck,
+			}
+		}
+
+		name, service := serviceFromContainer(containerJSON, imageConfig)
+		if _, ok := project.Services[name]; ok {
+			return nil, errors.New("[ERR:] [COMPOSE] => MORE THAN ONE CONTAINER MAPS TO SERVICE " + name)
+		}
+		for _, m := range containerJSON.Mounts {
+			if m.Type == mount.TypeVolume && !isAnonymousVolume(m.Name) {
+				project.Volumes[m.Name] = Volume{Name: m.Name}
+			}
+		}
+		for networkName := range service.Networks {
+			project.Networks[networkName] = Network{Name: networkName}
+		}
+		project.Services[name] = service
+	}
+	return project, nil
+}
+
+// Marshal ~ Renders a project as a compose file
+func (p *Project) Marshal() ([]byte, error) {
+	file := composeFile{Name: p.Name, Services: p.Services}
+	if len(p.Networks) > 0 {
+		file.Networks = map[string]*Network{}
+		for name, network := range p.Networks {
+			network := network
+			file.Networks[name] = &network
+		}
+	}
+	if len(p.Volumes) > 0 {
+		file.Volumes = map[string]*Volume{}
+		for name, volume := range p.Volumes {
+			volume := volume
+			file.Volumes[name] = &volume
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO RENDER COMPOSE FILE => " + err.Error())
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, errors.New("[ERR:] [COMPOSE] => FAILED TO RENDER COMPOSE FILE => " + err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// serviceFromContainer turns the inspect data of a container into a service, leaving out what the image already sets
+func serviceFromContainer(containerJSON types.ContainerJSON, imageConfig *container.Config) (string, Service) {
+	containerName := strings.TrimPrefix(containerJSON.Name, "/")
+	name := containerName
+	service := Service{}
+	cfg := containerJSON.Config
+	if cfg == nil {
+		cfg = &container.Config{}
+	}
+	if composeService := cfg.Labels[ServiceLabel]; composeService != "" {
+		name = composeService
+	} else {
+		service.ContainerName = containerName
+	}
+
+	service.Image = cfg.Image
+	if !slices.Equal(cfg.Entrypoint, imageConfig.Entrypoint) {
+		service.Entrypoint = stringOrList(escapeDollars(cfg.Entrypoint))
+	}
+	if !slices.Equal(cfg.Cmd, imageConfig.Cmd) {
+		service.Command = stringOrList(escapeDollars(cfg.Cmd))
+	}
+	if cfg.WorkingDir != imageConfig.WorkingDir {
+		service.WorkingDir = cfg.WorkingDir
+	}
+	if cfg.User != imageConfig.User {
+		service.User = cfg.User
+	}
+	service.Tty = cfg.Tty
+	service.StdinOpen = cfg.OpenStdin
+
+	imageEnv := map[string]bool{}
+	for _, variable := range imageConfig.Env {
+		imageEnv[variable] = true
+	}
+	for _, variable := range cfg.Env {
+		if !imageEnv[variable] {
+			if service.Environment == nil {
+				service.Environment = mappingOrList{}
+			}
+			key, value, _ := strings.Cut(variable, "=")
+			service.Environment[key] = strings.ReplaceAll(value, "$", "$$")
+		}
+	}
+
+	for key, value := range cfg.Labels {
+		if strings.HasPrefix(key, "com.docker.compose.") {
+			continue
+		}
+		if imageValue, ok := imageConfig.Labels[key]; ok && imageValue == value {
+			continue
+		}
+		if service.Labels == nil {
+			service.Labels = mappingOrList{}
+		}
+		service.Labels[key] = strings.ReplaceAll(value, "$", "$$")
+	}
+
+	if hc := cfg.Healthcheck; hc != nil && len(hc.Test) > 0 && !equalHealthchecks(hc, imageConfig.Healthcheck) {
+		healthcheck := &Healthcheck{Retries: hc.Retries}
+		if hc.Test[0] == "NONE" {
+			healthcheck.Disable = true
+		} else {
+			healthcheck.Test = healthTest(escapeDollars(hc.Test))
+		}
+		if hc.Interval > 0 {
+			healthcheck.Interval = hc.Interval.String()
+		}
+		if hc.Timeout > 0 {
+			healthcheck.Timeout = hc.Timeout.String()
+		}
+		if hc.StartPeriod > 0 {
+			healthcheck.StartPeriod = hc.StartPeriod.String()
+		}
+		service.Healthcheck = healthcheck
+	}
+
+	if hc := containerJSON.HostConfig; hc != nil {
+		switch policy := hc.RestartPolicy; policy.Name {
+		case container.RestartPolicyAlways, container.RestartPolicyUnlessStopped:
+			service.Restart = string(policy.Name)
+		case container.RestartPolicyOnFailure:
+			service.Restart = "on-failure"
+			if policy.MaximumRetryCount > 0 {
+				service.Restart += ":" + strconv.Itoa(policy.MaximumRetryCount)
+			}
+		}
+		for port, bindings := range hc.PortBindings {
+			containerPort := port.Port()
+			if port.Proto() != "tcp" {
+				containerPort += "/" + port.Proto()
+			}
+			if len(bindings) == 0 {
+				service.Ports = append(service.Ports, containerPort)
+			}
+			for _, binding := range bindings {
+				spec := containerPort
+				if binding.HostPort != "" {
+					spec = binding.HostPort + ":" + spec
+				}
+				if hostIP := binding.HostIP; hostIP != "" && hostIP != "0.0.0.0" && hostIP != "::" {
+					if strings.Contains(hostIP, ":") {
+						hostIP = "[" + hostIP + "]"
+					}
+					spec = hostIP + ":" + spec
+				}
+				service.Ports = append(service.Ports, spec)
+			}
+		}
+		sort.Strings(service.Ports)
+	}
+
+	for _, m := range containerJSON.Mounts {
+		var spec string
+		switch m.Type {
+		case mount.TypeVolume:
+			spec = m.Destination
+			if !isAnonymousVolume(m.Name) {
+				spec = m.Name + ":" + spec
+			}
+		case mount.TypeBind:
+			spec = m.Source + ":" + m.Destination
+		default:
+			continue
+		}
+		if !m.RW && strings.Contains(spec, ":") {
+			spec += ":ro"
+		}
+		service.Volumes = append(service.Volumes, spec)
+	}
+	sort.Strings(service.Volumes)
+
+	if containerJSON.NetworkSettings != nil {
+		for networkName, endpoint := range containerJSON.NetworkSettings.Networks {
+			if networkName == "bridge" || networkName == "host" || networkName == "none" {
+				continue
+			}
+			attachment := ServiceNetwork{}
+			if endpoint != nil {
+				for _, alias := range endpoint.Aliases {
+					// The daemon adds the short container ID, the container name and the compose service name as aliases
+					if !strings.HasPrefix(containerJSON.ID, alias) && alias != containerName && alias != name {
+						attachment.Aliases = append(attachment.Aliases, alias)
+					}
+				}
+				if endpoint.IPAMConfig != nil {
+					attachment.IPv4Address = endpoint.IPAMConfig.IPv4Address
+					attachment.IPv6Address = endpoint.IPAMConfig.IPv6Address
+				}
+			}
+			if service.Networks == nil {
+				service.Networks = serviceNetworks{}
+			}
+			service.Networks[networkName] = attachment
+		}
+	}
+	return name, service
+}
+
+// escapeDollars escapes the $ of values as $$, so loading the exported file does not expand them as variables
+func escapeDollars(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = strings.ReplaceAll(value, "$", "$$")
+	}
+	return escaped
+}
+
+// isAnonymousVolume reports whether a volume name was generated by the daemon, which names anonymous volumes with 64 hex digits
+func isAnonymousVolume(name string) bool {
+	if len(name) != 64 {
+		return false
+	}
+	for _, r := range name {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalHealthchecks(a *container.HealthConfig, b *container.HealthConfig) bool {
+	if b == nil {
+		return false
+	}
+	return slices.Equal(a.Test, b.Test) && a.Interval == b.Interval && a.Timeout == b.Timeout && a.StartPeriod == b.StartPeriod && a.Retries == b.Retries
+}
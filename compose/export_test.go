@@ -0,0 +1,79 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestServiceFromContainer(t *testing.T) {
+	imageConfig := &container.Config{Env: []string{"PATH=/usr/bin"}, Cmd: []string{"nginx"}, Labels: map[string]string{"maintainer": "x"}}
+	containerJSON := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:   "0123456789ab",
+			Name: "/web-1",
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+				PortBindings: nat.PortMap{
+					"80/tcp": {{HostIP: "::1", HostPort: "8080"}, {HostIP: "0.0.0.0", HostPort: "80"}},
+					"53/udp": {{HostIP: "127.0.0.1", HostPort: "53"}},
+				},
+			},
+		},
+		Config: &container.Config{
+			Image:  "nginx",
+			Env:    []string{"PATH=/usr/bin", "PRICE=$5"},
+			Cmd:    []string{"sh", "-c", "echo $HOME"},
+			Labels: map[string]string{"maintainer": "x", "cost": "$$", ServiceLabel: "web"},
+			Healthcheck: &container.HealthConfig{
+				Test: []string{"CMD-SHELL", "test -n \"$PORT\""},
+			},
+		},
+	}
+
+	name, service := serviceFromContainer(containerJSON, imageConfig)
+	if name != "web" {
+		t.Errorf("name = %q", name)
+	}
+	want := Service{
+		Image:       "nginx",
+		Command:     stringOrList{"sh", "-c", "echo $$HOME"},
+		Environment: mappingOrList{"PRICE": "$$5"},
+		Labels:      mappingOrList{"cost": "$$$$"},
+		Healthcheck: &Healthcheck{Test: healthTest{"CMD-SHELL", "test -n \"$$PORT\""}},
+		Restart:     "on-failure:3",
+		Ports:       []string{"127.0.0.1:53:53/udp", "80:80", "[::1]:8080:80"},
+	}
+	if !reflect.DeepEqual(service, want) {
+		t.Errorf("got %+v, want %+v", service, want)
+	}
+
+	// Loading the exported file gives back the values of the container
+	data, err := (&Project{Name: "test", Services: map[string]Service{name: service}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := Parse(data, t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := project.Services["web"]
+	if want := []string{"sh", "-c", "echo $HOME"}; !reflect.DeepEqual([]string(loaded.Command), want) {
+		t.Errorf("command = %q, want %q", loaded.Command, want)
+	}
+	if want := (mappingOrList{"PRICE": "$5"}); !reflect.DeepEqual(loaded.Environment, want) {
+		t.Errorf("environment = %q, want %q", loaded.Environment, want)
+	}
+	if want := (mappingOrList{"cost": "$$"}); !reflect.DeepEqual(loaded.Labels, want) {
+		t.Errorf("labels = %q, want %q", loaded.Labels, want)
+	}
+	if want := (healthTest{"CMD-SHELL", "test -n \"$PORT\""}); !reflect.DeepEqual(loaded.Healthcheck.Test, want) {
+		t.Errorf("healthcheck test = %q, want %q", loaded.Healthcheck.Test, want)
+	}
+	if want := []string{"127.0.0.1:53:53/udp", "80:80", "[::1]:8080:80"}; !reflect.DeepEqual(loaded.Ports, want) {
+		t.Errorf("ports = %q, want %q", loaded.Ports, want)
+	}
+}
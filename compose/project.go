@@ -0,0 +1,534 @@
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// Labels set on everything a project creates, the same ones docker compose uses
+const (
+	ProjectLabel = "com.docker.compose.project"
+	ServiceLabel = "com.docker.compose.service"
+)
+
+// depends_on conditions
+const (
+	ConditionStarted               = "service_started"
+	ConditionHealthy               = "service_healthy"
+	ConditionCompletedSuccessfully = "service_completed_successfully"
+)
+
+// DependencyTimeout ~ How long Up waits for a dependency to become healthy or to complete
+var DependencyTimeout = 2 * time.Minute
+
+// ContainerName ~ Returns the name of the container of a service
+func (p *Project) ContainerName(service string) string {
+	if name := p.Services[service].ContainerName; name != "" {
+		return name
+	}
+	return p.Name + "-" + service + "-1"
+}
+
+// NetworkName ~ Returns the daemon name of a network of the project
+func (p *Project) NetworkName(name string) string {
+	network := p.Networks[name]
+	if network.Name != "" {
+		return network.Name
+	}
+	if network.External {
+		return name
+	}
+	return p.Name + "_" + name
+}
+
+// VolumeName ~ Returns the daemon name of a volume of the project
+func (p *Project) VolumeName(name string) string {
+	volume := p.Volumes[name]
+	if volume.Name != "" {
+		return volume.Name
+	}
+	if volume.External {
+		return name
+	}
+	return p.Name + "_" + name
+}
+
+// ImageName ~ Returns the image a service runs. Services that only build get "<project>-<service>"
+func (p *Project) ImageName(service string) string {
+	if image := p.Services[service].Image; image != "" {
+		return image
+	}
+	return p.Name + "-" + service
+}
+
+// serviceNetworks returns the networks of a service sorted by name. Services without networks join the default network
+func (p *Project) serviceNetworks(service string) []string {
+	attached := p.Services[service].Networks
+	if len(attached) == 0 {
+		return []string{"default"}
+	}
+	names := make([]string, 0, len(attached))
+	for name := range attached {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContainerConfig ~ Translates a service into the config of its container. The first network of the service is set as the
+// network mode, the rest are connected by Up once the container exists
+func (p *Project) ContainerConfig(service string) (*containers.ContainerCreateConfig, error) {
+	s, ok := p.Services[service]
+	if !ok {
+		return nil, errors.New("[ERR:] [COMPOSE] => UNKNOWN SERVICE " + service)
+	}
+
+	env := map[string]string{}
+	for _, file := range s.EnvFile {
+		loaded, err := containers.LoadEnvFile(p.resolvePath(file))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range loaded {
+			env[key] = value
+		}
+	}
+	for key, value := range s.Environment {
+		env[key] = value
+	}
+
+	labels := map[string]string{}
+	for key, value := range s.Labels {
+		labels[key] = value
+	}
+	labels[ProjectLabel] = p.Name
+	labels[ServiceLabel] = service
+
+	config := &containers.ContainerCreateConfig{
+		Name: p.ContainerName(service),
+		Config: &container.Config{
+			Image:      p.ImageName(service),
+			Cmd:        []string(s.Command),
+			Entrypoint: []string(s.Entrypoint),
+			Env:        containers.EnvMapToSlice(env),
+			Labels:     labels,
+			WorkingDir: s.WorkingDir,
+			User:       s.User,
+			Tty:        s.Tty,
+			OpenStdin:  s.StdinOpen,
+		},
+		HostConfig: &container.HostConfig{},
+	}
+
+	for _, port := range s.Ports {
+		if err := containers.PublishPort(config, port); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, volume := range s.Volumes {
+		m, err := p.volumeMount(volume)
+		if err != nil {
+			return nil, errors.New("[ERR:] [COMPOSE] => INVALID VOLUME OF SERVICE " + service + " => " + err.Error())
+		}
+		containers.AddMounts(config, m)
+	}
+
+	if s.Restart != "" {
+		policy, err := restartPolicy(s.Restart)
+		if err != nil {
+			return nil, errors.New("[ERR:] [COMPOSE] => INVALID RESTART POLICY OF SERVICE " + service + " => " + err.Error())
+		}
+		containers.SetRestartPolicy(config, policy)
+	}
+
+	if s.Healthcheck != nil {
+		healthcheck, err := healthConfig(*s.Healthcheck)
+		if err != nil {
+			return nil, errors.New("[ERR:] [COMPOSE] => INVALID HEALTHCHECK OF SERVICE " + service + " => " + err.Error())
+		}
+		containers.SetHealthcheck(config, healthcheck)
+	}
+
+	primary := p.serviceNetworks(service)[0]
+	networkName := p.NetworkName(primary)
+	config.HostConfig.NetworkMode = container.NetworkMode(networkName)
+	attachment := s.Networks[primary]
+	config.NetworkingConfig = &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			networkName: endpointSettings(service, attachment),
+		},
+	}
+
+	return config, nil
+}
+
+// Up ~ Creates the networks and volumes of the project and converges every service in dependency order. Services whose
+// container already matches the file are left running, the rest are recreated
+func (p *Project) Up(ctx context.Context) error {
+	order, err := p.ServiceOrder()
+	if err != nil {
+		return err
+	}
+
+	if err := p.createNetworks(ctx); err != nil {
+		return err
+	}
+	if err := p.createVolumes(ctx); err != nil {
+		return err
+	}
+
+	ids := map[string]string{}
+	for _, service := range order {
+		if err := p.waitForDependencies(ctx, service, ids); err != nil {
+			return err
+		}
+		id, err := p.upService(ctx, service)
+		if err != nil {
+			return err
+		}
+		ids[service] = id
+	}
+	return nil
+}
+
+// Down ~ Stops and removes the containers of the project in reverse dependency order, then its networks and, with removeVolumes
+// set, its volumes. External networks and volumes are never removed
+func (p *Project) Down(ctx context.Context, removeVolumes bool) error {
+	order, err := p.ServiceOrder()
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for i := len(order) - 1; i >= 0; i-- {
+		name := p.ContainerName(order[i])
+		existing, err := containers.DockerClient.ContainerInspect(ctx, name)
+		if client.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, "FAILED TO INSPECT CONTAINER "+name+" => "+err.Error())
+			continue
+		}
+		if existing.State != nil && existing.State.Running {
+			if err := containers.StopContainerContext(ctx, existing.ID); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+		}
+		if err := containers.PurgeContainerContext(ctx, existing.ID); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	for _, name := range p.usedNetworks() {
+		if p.Networks[name].External {
+			continue
+		}
+		if _, err := containers.DockerClient.NetworkInspect(ctx, p.NetworkName(name), network.InspectOptions{}); client.IsErrNotFound(err) {
+			continue
+		}
+		if err := containers.RemoveNetwork(ctx, p.NetworkName(name)); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if removeVolumes {
+		for name, volume := range p.Volumes {
+			if volume.External {
+				continue
+			}
+			if _, err := containers.DockerClient.VolumeInspect(ctx, p.VolumeName(name)); client.IsErrNotFound(err) {
+				continue
+			}
+			if err := containers.RemoveVolume(ctx, p.VolumeName(name), false); err != nil {
+				failures = append(failures, err.Error())
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [COMPOSE] => FAILED TO TAKE DOWN PROJECT " + p.Name + " => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// upService builds or pulls the image of a service, converges its container and makes sure it is running
+func (p *Project) upService(ctx context.Context, service string) (string, error) {
+	s := p.Services[service]
+	if s.Build != nil {
+		if err := containers.BuildImageContext(ctx, p.resolvePath(s.Build.Context), p.ImageName(service)); err != nil {
+			return "", err
+		}
+	} else if err := containers.EnsureImage(ctx, s.Image); err != nil {
+		return "", err
+	}
+
+	config, err := p.ContainerConfig(service)
+	if err != nil {
+		return "", err
+	}
+	id, changes, err := containers.RecreateContainer(ctx, config)
+	if err != nil {
+		return "", err
+	}
+
+	if len(changes) > 0 {
+		// RecreateContainer only knows the primary network, a fresh container still has to join the others
+		for _, name := range p.serviceNetworks(service)[1:] {
+			attachment := s.Networks[name]
+			err := containers.ConnectNetwork(ctx, p.NetworkName(name), id, containers.EndpointOptions{
+				Aliases:     append([]string{service}, attachment.Aliases...),
+				IPv4Address: attachment.IPv4Address,
+				IPv6Address: attachment.IPv6Address,
+			})
+			if err != nil {
+				return id, err
+			}
+		}
+		return id, nil
+	}
+
+	existing, err := containers.DockerClient.ContainerInspect(ctx, id)
+	if err != nil {
+		return id, errors.New("[ERR:] [COMPOSE] => FAILED TO INSPECT CONTAINER OF SERVICE " + service + " => " + err.Error())
+	}
+	if existing.State == nil || !existing.State.Running {
+		if err := containers.StartContainerContext(ctx, container.CreateResponse{ID: id}); err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
+
+// waitForDependencies blocks until every dependency of a service satisfies its depends_on condition
+func (p *Project) waitForDependencies(ctx context.Context, service string, ids map[string]string) error {
+	for dependency, condition := range p.Services[service].DependsOn {
+		id := ids[dependency]
+		switch condition.Condition {
+		case ConditionStarted:
+		case ConditionHealthy:
+			if err := containers.WaitForHealthy(ctx, id, DependencyTimeout, time.Second); err != nil {
+				return errors.New("[ERR:] [COMPOSE] => DEPENDENCY " + dependency + " OF SERVICE " + service + " DID NOT BECOME HEALTHY => " + err.Error())
+			}
+		case ConditionCompletedSuccessfully:
+			waitCtx, cancel := context.WithTimeout(ctx, DependencyTimeout)
+			exitCode, err := containers.WaitForExit(waitCtx, id)
+			cancel()
+			if err != nil {
+				return errors.New("[ERR:] [COMPOSE] => DEPENDENCY " + dependency + " OF SERVICE " + service + " DID NOT COMPLETE => " + err.Error())
+			}
+			if exitCode != 0 {
+				return errors.New("[ERR:] [COMPOSE] => DEPENDENCY " + dependency + " OF SERVICE " + service + " EXITED WITH CODE " + fmt.Sprint(exitCode))
+			}
+		default:
+			return errors.New("[ERR:] [COMPOSE] => UNKNOWN CONDITION " + condition.Condition + " FOR DEPENDENCY " + dependency + " OF SERVICE " + service)
+		}
+	}
+	return nil
+}
+
+// createNetworks makes sure every network a service uses exists. External networks must already exist
+func (p *Project) createNetworks(ctx context.Context) error {
+	for _, name := range p.usedNetworks() {
+		declared := p.Networks[name]
+		networkName := p.NetworkName(name)
+		if declared.External {
+			if _, err := containers.InspectNetwork(ctx, networkName); err != nil {
+				return errors.New("[ERR:] [COMPOSE] => EXTERNAL NETWORK " + networkName + " IS NOT AVAILABLE => " + err.Error())
+			}
+			continue
+		}
+		labels := map[string]string{ProjectLabel: p.Name}
+		for key, value := range declared.Labels {
+			labels[key] = value
+		}
+		_, _, err := containers.EnsureNetwork(ctx, networkName, containers.NetworkOptions{
+			Driver:     declared.Driver,
+			Internal:   declared.Internal,
+			Attachable: declared.Attachable,
+			Labels:     labels,
+			Options:    declared.DriverOpts,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createVolumes makes sure every declared volume exists. External volumes must already exist
+func (p *Project) createVolumes(ctx context.Context) error {
+	for name, declared := range p.Volumes {
+		volumeName := p.VolumeName(name)
+		if declared.External {
+			if _, err := containers.DockerClient.VolumeInspect(ctx, volumeName); err != nil {
+				return errors.New("[ERR:] [COMPOSE] => EXTERNAL VOLUME " + volumeName + " IS NOT AVAILABLE => " + err.Error())
+			}
+			continue
+		}
+		labels := map[string]string{ProjectLabel: p.Name}
+		for key, value := range declared.Labels {
+			labels[key] = value
+		}
+		// Creating a volume that already exists returns the existing one
+		_, err := containers.CreateVolume(ctx, volumeName, containers.VolumeOptions{
+			Driver:     declared.Driver,
+			DriverOpts: declared.DriverOpts,
+			Labels:     labels,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usedNetworks returns the sorted names of the networks at least one service joins
+func (p *Project) usedNetworks() []string {
+	used := map[string]bool{}
+	for service := range p.Services {
+		for _, name := range p.serviceNetworks(service) {
+			used[name] = true
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// volumeMount translates the short volume syntax source:target[:mode]. Sources starting with ., / or ~ are bind mounts,
+// other sources are named volumes of the project and a lone target is an anonymous volume
+func (p *Project) volumeMount(spec string) (mount.Mount, error) {
+	parts := strings.Split(spec, ":")
+	readOnly := false
+	if len(parts) == 3 {
+		for _, option := range strings.Split(parts[2], ",") {
+			switch option {
+			case "ro":
+				readOnly = true
+			case "rw", "z", "Z":
+			default:
+				return mount.Mount{}, errors.New("UNSUPPORTED VOLUME MODE " + option + " IN " + spec)
+			}
+		}
+		parts = parts[:2]
+	}
+
+	switch len(parts) {
+	case 1:
+		return containers.VolumeMount("", parts[0], false), nil
+	case 2:
+		if isNamedVolume(parts[0]) {
+			return containers.VolumeMount(p.VolumeName(parts[0]), parts[1], readOnly), nil
+		}
+		return containers.BindMount(p.resolvePath(parts[0]), parts[1], readOnly, ""), nil
+	}
+	return mount.Mount{}, errors.New("MALFORMED VOLUME " + spec)
+}
+
+// resolvePath resolves a path of the compose file against the project directory and the home directory
+func (p *Project) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.WorkingDir, path)
+}
+
+// restartPolicy translates the restart key of a service
+func restartPolicy(restart string) (container.RestartPolicy, error) {
+	switch restart {
+	case "no":
+		return containers.RestartNo(), nil
+	case "always":
+		return containers.RestartAlways(), nil
+	case "unless-stopped":
+		return containers.RestartUnlessStopped(), nil
+	case "on-failure":
+		return containers.RestartOnFailure(0), nil
+	}
+	if retries, found := strings.CutPrefix(restart, "on-failure:"); found {
+		maxRetries, err := strconv.Atoi(retries)
+		if err != nil {
+			return container.RestartPolicy{}, errors.New("MALFORMED RETRY COUNT " + retries)
+		}
+		return containers.RestartOnFailure(maxRetries), nil
+	}
+	return container.RestartPolicy{}, errors.New("UNKNOWN POLICY " + restart)
+}
+
+// healthConfig translates the healthcheck section of a service
+func healthConfig(healthcheck Healthcheck) (*container.HealthConfig, error) {
+	if healthcheck.Disable || (len(healthcheck.Test) > 0 && healthcheck.Test[0] == "NONE") {
+		return containers.HealthcheckNone(), nil
+	}
+
+	var opts containers.HealthcheckOptions
+	durations := []struct {
+		value  string
+		target *time.Duration
+	}{
+		{healthcheck.Interval, &opts.Interval},
+		{healthcheck.Timeout, &opts.Timeout},
+		{healthcheck.StartPeriod, &opts.StartPeriod},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, err
+		}
+		*d.target = parsed
+	}
+	opts.Retries = healthcheck.Retries
+
+	if len(healthcheck.Test) == 0 {
+		return nil, errors.New("MISSING TEST")
+	}
+	switch healthcheck.Test[0] {
+	case "CMD":
+		return containers.HealthcheckCmd(opts, healthcheck.Test[1:]...), nil
+	case "CMD-SHELL":
+		return containers.HealthcheckShell(strings.Join(healthcheck.Test[1:], " "), opts), nil
+	}
+	return nil, errors.New("TEST MUST START WITH CMD, CMD-SHELL OR NONE")
+}
+
+// endpointSettings builds the endpoint of a service on a network. The service name is always an alias so services reach
+// each other by name
+func endpointSettings(service string, attachment ServiceNetwork) *network.EndpointSettings {
+	settings := &network.EndpointSettings{
+		Aliases: append([]string{service}, attachment.Aliases...),
+	}
+	if attachment.IPv4Address != "" || attachment.IPv6Address != "" {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: attachment.IPv4Address,
+			IPv6Address: attachment.IPv6Address,
+		}
+	}
+	return settings
+}
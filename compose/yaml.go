@@ -0,0 +1,227 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stringOrList accepts both `cmd arg` and `["cmd", "arg"]`. The string form is split into words like a shell would
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		words, err := splitWords(node.Value)
+		if err != nil {
+			return errors.New(strings.ToUpper(err.Error()) + " ON LINE " + fmt.Sprint(node.Line))
+		}
+		*s = words
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*s = list
+		return nil
+	}
+	return errors.New("EXPECTED A STRING OR A LIST ON LINE " + fmt.Sprint(node.Line))
+}
+
+// splitWords splits a command into words the way a POSIX shell does, honoring single quotes, double quotes and backslash escapes
+// but expanding nothing
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				// Inside double quotes a backslash only escapes the characters that are special there
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case c == '\\':
+			if i+1 < len(s) {
+				i++
+				word.WriteByte(s[i])
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// mappingOrList accepts both `KEY: value` mappings and `["KEY=value"]` lists
+type mappingOrList map[string]string
+
+func (m *mappingOrList) UnmarshalYAML(node *yaml.Node) error {
+	result := map[string]string{}
+	switch node.Kind {
+	case yaml.MappingNode:
+		var raw map[string]interface{}
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for key, value := range raw {
+			if value == nil {
+				result[key] = ""
+			} else {
+				result[key] = fmt.Sprint(value)
+			}
+		}
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, entry := range list {
+			key, value, _ := strings.Cut(entry, "=")
+			result[key] = value
+		}
+	default:
+		return errors.New("EXPECTED A MAPPING OR A LIST ON LINE " + fmt.Sprint(node.Line))
+	}
+	*m = result
+	return nil
+}
+
+// Slice returns the entries as a sorted KEY=value list
+func (m mappingOrList) Slice() []string {
+	entries := make([]string, 0, len(m))
+	for key, value := range m {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// serviceNetworks accepts both a list of network names and a mapping with aliases
+type serviceNetworks map[string]ServiceNetwork
+
+// ServiceNetwork ~ The attachment of a service to a network
+type ServiceNetwork struct {
+	Aliases     []string `yaml:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address,omitempty"`
+	IPv6Address string   `yaml:"ipv6_address,omitempty"`
+}
+
+func (n *serviceNetworks) UnmarshalYAML(node *yaml.Node) error {
+	result := map[string]ServiceNetwork{}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, name := range list {
+			result[name] = ServiceNetwork{}
+		}
+	case yaml.MappingNode:
+		var raw map[string]*ServiceNetwork
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for name, network := range raw {
+			if network == nil {
+				network = &ServiceNetwork{}
+			}
+			result[name] = *network
+		}
+	default:
+		return errors.New("EXPECTED A LIST OR A MAPPING OF NETWORKS ON LINE " + fmt.Sprint(node.Line))
+	}
+	*n = result
+	return nil
+}
+
+// dependsOn accepts both a list of service names and a mapping with conditions
+type dependsOn map[string]Dependency
+
+func (d *dependsOn) UnmarshalYAML(node *yaml.Node) error {
+	result := map[string]Dependency{}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, name := range list {
+			result[name] = Dependency{Condition: ConditionStarted}
+		}
+	case yaml.MappingNode:
+		var raw map[string]Dependency
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		for name, dependency := range raw {
+			if dependency.Condition == "" {
+				dependency.Condition = ConditionStarted
+			}
+			result[name] = dependency
+		}
+	default:
+		return errors.New("EXPECTED A LIST OR A MAPPING OF DEPENDENCIES ON LINE " + fmt.Sprint(node.Line))
+	}
+	*d = result
+	return nil
+}
+
+// UnmarshalYAML accepts the short build syntax, a plain context path
+func (b *Build) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+	type plain Build
+	return node.Decode((*plain)(b))
+}
+
+// healthTest accepts the list form of a healthcheck test and treats a plain string as CMD-SHELL
+type healthTest []string
+
+func (t *healthTest) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = []string{"CMD-SHELL", node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
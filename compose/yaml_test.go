@@ -0,0 +1,35 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "nginx -g 'daemon off;'", want: []string{"nginx", "-g", "daemon off;"}},
+		{in: `sh -c "echo \"hi\" \$HOME"`, want: []string{"sh", "-c", `echo "hi" $HOME`}},
+		{in: `echo "a\nb"`, want: []string{"echo", `a\nb`}},
+		{in: `echo a\ b`, want: []string{"echo", "a b"}},
+		{in: "  spaced\t\tout\n", want: []string{"spaced", "out"}},
+		{in: `pre'fix'"ed"`, want: []string{"prefixed"}},
+		{in: `empty '' ""`, want: []string{"empty", "", ""}},
+		{in: "echo 'open", wantErr: true},
+		{in: `echo "open`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitWords(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitWords(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,916 @@
+//go:build containerd
+
+package containerd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	ctrd "github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/oci"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+const (
+	// DefaultAddress ~ The socket of a system containerd
+	DefaultAddress = "/run/containerd/containerd.sock"
+	// DefaultNamespace ~ The namespace containers and images are kept in when Options name none
+	DefaultNamespace = "containers"
+	// DefaultStateDir ~ Where logs and volumes are kept when Options name no directory
+	DefaultStateDir = "/var/lib/containers-containerd"
+)
+
+// Options ~ Where to reach containerd and where to keep state. Zero values take the defaults, StopTimeout defaults to 10 seconds
+type Options struct {
+	Address     string
+	Namespace   string
+	StateDir    string
+	StopTimeout time.Duration
+}
+
+// Backend ~ A containers.ContainerManager backed by containerd
+type Backend struct {
+	client      *ctrd.Client
+	namespace   string
+	stateDir    string
+	stopTimeout time.Duration
+}
+
+var _ containers.ContainerManager = (*Backend)(nil)
+
+// New ~ Connects to containerd and prepares the state directory
+func New(opts Options) (*Backend, error) {
+	if opts.Address == "" {
+		opts.Address = DefaultAddress
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+	if opts.StateDir == "" {
+		opts.StateDir = DefaultStateDir
+	}
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = 10 * time.Second
+	}
+
+	for _, dir := range []string{filepath.Join(opts.StateDir, "logs"), filepath.Join(opts.StateDir, "volumes")} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, errors.New("[ERR:] [CONTAINERD] => FAILED TO CREATE STATE DIRECTORY " + dir + " => " + err.Error())
+		}
+	}
+	client, err := ctrd.New(opts.Address, ctrd.WithDefaultNamespace(opts.Namespace))
+	if err != nil {
+		return nil, errors.New("[ERR:] [CONTAINERD] => FAILED TO CONNECT TO " + opts.Address + " => " + err.Error())
+	}
+	return &Backend{client: client, namespace: opts.Namespace, stateDir: opts.StateDir, stopTimeout: opts.StopTimeout}, nil
+}
+
+// Close ~ Closes the connection to containerd
+func (b *Backend) Close() error {
+	if err := b.client.Close(); err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO CLOSE CLIENT => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) BuildImage(path string, imageName string) error {
+	return unsupported("BUILDING IMAGES")
+}
+
+func (b *Backend) EnsureImage(ctx context.Context, imageName string) error {
+	ctx = b.withNamespace(ctx)
+	ref, err := normalizeImage(imageName)
+	if err != nil {
+		return err
+	}
+	if _, err := b.client.GetImage(ctx, ref); err == nil {
+		return nil
+	}
+	if _, err := b.client.Pull(ctx, ref, ctrd.WithPullUnpack); err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO PULL IMAGE " + imageName + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) DeleteImage(imageName string) (bool, error) {
+	ctx := b.withNamespace(context.Background())
+	ref, err := normalizeImage(imageName)
+	if err != nil {
+		return false, err
+	}
+	if _, err := b.client.GetImage(ctx, ref); err != nil {
+		return false, nil
+	}
+	if err := b.client.ImageService().Delete(ctx, ref); err != nil {
+		return true, errors.New("[ERR:] [CONTAINERD] => FAILED TO DELETE IMAGE: " + imageName + " | => " + err.Error())
+	}
+	return true, nil
+}
+
+// PruneDanglingImages has nothing to do, every image in containerd is kept under a name
+func (b *Backend) PruneDanglingImages() (image.PruneReport, error) {
+	return image.PruneReport{}, nil
+}
+
+func (b *Backend) CreateContainer(config *containers.ContainerCreateConfig) (container.CreateResponse, error) {
+	ctx := b.withNamespace(context.Background())
+	fail := func(reason string) (container.CreateResponse, error) {
+		return container.CreateResponse{}, errors.New("[ERR:] [CONTAINERD] => FAILED TO CREATE CONTAINER " + config.Name + " => " + reason)
+	}
+	if config.Name == "" {
+		return fail("CONTAINERD NEEDS A CONTAINER NAME")
+	}
+	if config.Config == nil || config.Config.Image == "" {
+		return fail("NO IMAGE")
+	}
+	ref, err := normalizeImage(config.Config.Image)
+	if err != nil {
+		return fail(err.Error())
+	}
+	img, err := b.client.GetImage(ctx, ref)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfig(img),
+		oci.WithHostNamespace(specs.NetworkNamespace),
+		oci.WithHostHostsFile,
+		oci.WithHostResolvconf,
+		oci.WithHostname(config.Name),
+	}
+	cfg := config.Config
+	switch {
+	case len(cfg.Entrypoint) > 0:
+		specOpts = append(specOpts, oci.WithProcessArgs(append(append([]string{}, cfg.Entrypoint...), cfg.Cmd...)...))
+	case len(cfg.Cmd) > 0:
+		specOpts = append(specOpts, oci.WithImageConfigArgs(img, cfg.Cmd))
+	}
+	if len(cfg.Env) > 0 {
+		specOpts = append(specOpts, oci.WithEnv(cfg.Env))
+	}
+	if cfg.WorkingDir != "" {
+		specOpts = append(specOpts, oci.WithProcessCwd(cfg.WorkingDir))
+	}
+	if cfg.User != "" {
+		specOpts = append(specOpts, oci.WithUser(cfg.User))
+	}
+	if cfg.Hostname != "" {
+		specOpts = append(specOpts, oci.WithHostname(cfg.Hostname))
+	}
+	if cfg.Tty {
+		specOpts = append(specOpts, oci.WithTTY)
+	}
+	mounts, err := b.specMounts(config.HostConfig)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if len(mounts) > 0 {
+		specOpts = append(specOpts, oci.WithMounts(mounts))
+	}
+
+	_, err = b.client.NewContainer(ctx, config.Name,
+		ctrd.WithImage(img),
+		ctrd.WithNewSnapshot(config.Name+"-snapshot", img),
+		ctrd.WithNewSpec(specOpts...),
+		ctrd.WithContainerLabels(cfg.Labels),
+	)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	var warnings []string
+	if config.HostConfig != nil && len(config.HostConfig.PortBindings) > 0 {
+		warnings = append(warnings, "port bindings are ignored, containers share the host network")
+	}
+	return container.CreateResponse{ID: config.Name, Warnings: warnings}, nil
+}
+
+func (b *Backend) StartContainer(cont container.CreateResponse) error {
+	ctx := b.withNamespace(context.Background())
+	if err := b.start(ctx, cont.ID); err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO START CONTAINER WITH ID: " + cont.ID + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) StopContainer(containerID string) error {
+	ctx := b.withNamespace(context.Background())
+	if err := b.stop(ctx, containerID, b.stopTimeout); err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO STOP CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	ctx = b.withNamespace(ctx)
+	err := b.stop(ctx, containerID, timeout)
+	if err == nil {
+		err = b.start(ctx, containerID)
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO RESTART CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) PauseContainer(ctx context.Context, containerID string) error {
+	ctx = b.withNamespace(ctx)
+	task, err := b.task(ctx, containerID)
+	if err == nil {
+		err = task.Pause(ctx)
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO PAUSE CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) UnpauseContainer(ctx context.Context, containerID string) error {
+	ctx = b.withNamespace(ctx)
+	task, err := b.task(ctx, containerID)
+	if err == nil {
+		err = task.Resume(ctx)
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO UNPAUSE CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) KillContainer(ctx context.Context, containerID string, signal string) error {
+	ctx = b.withNamespace(ctx)
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	sig, err := ctrd.ParseSignal(signal)
+	if err == nil {
+		var task ctrd.Task
+		task, err = b.task(ctx, containerID)
+		if err == nil {
+			err = task.Kill(ctx, sig)
+		}
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO SEND " + signal + " TO CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// RenameContainer is not supported, the name of a containerd container is its ID
+func (b *Backend) RenameContainer(ctx context.Context, containerID string, newName string) error {
+	return unsupported("RENAMING CONTAINERS")
+}
+
+func (b *Backend) PurgeContainer(containerID string) error {
+	ctx := b.withNamespace(context.Background())
+	err := b.stop(ctx, containerID, 0)
+	if err == nil {
+		var cont ctrd.Container
+		cont, err = b.client.LoadContainer(ctx, containerID)
+		if err == nil {
+			err = cont.Delete(ctx, ctrd.WithSnapshotCleanup)
+		}
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO PURGE CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	os.Remove(b.logPath(containerID))
+	return nil
+}
+
+// InspectContainer leaves out IPs and the start time, containers share the host network and containerd does not record starts
+func (b *Backend) InspectContainer(ctx context.Context, containerID string) (containers.ContainerInfo, error) {
+	ctx = b.withNamespace(ctx)
+	fail := func(err error) (containers.ContainerInfo, error) {
+		return containers.ContainerInfo{}, errors.New("[ERR:] [CONTAINERD] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	cont, err := b.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return fail(err)
+	}
+	meta, err := cont.Info(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	spec, err := cont.Spec(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	info := containers.ContainerInfo{
+		ID:        meta.ID,
+		Name:      meta.ID,
+		Image:     meta.Image,
+		State:     "created",
+		CreatedAt: meta.CreatedAt,
+		IPs:       map[string]string{},
+		Labels:    meta.Labels,
+	}
+	if spec.Process != nil {
+		info.Env = spec.Process.Env
+	}
+	// Volumes are bind mounts of directories below the state directory, see specMounts
+	for _, m := range spec.Mounts {
+		if m.Type != "bind" {
+			continue
+		}
+		mountInfo := containers.MountInfo{Type: string(mount.TypeBind), Source: m.Source, Destination: m.Destination}
+		if filepath.Dir(m.Source) == b.volumePath("") {
+			mountInfo.Type = string(mount.TypeVolume)
+			mountInfo.Name = filepath.Base(m.Source)
+		}
+		for _, option := range m.Options {
+			if option == "ro" {
+				mountInfo.ReadOnly = true
+			}
+		}
+		info.Mounts = append(info.Mounts, mountInfo)
+	}
+
+	task, err := b.task(ctx, containerID)
+	if err != nil && !errdefs.IsNotFound(err) {
+		return fail(err)
+	}
+	if err == nil {
+		status, err := task.Status(ctx)
+		if err != nil {
+			return fail(err)
+		}
+		switch status.Status {
+		case ctrd.Running:
+			info.State = "running"
+		case ctrd.Paused, ctrd.Pausing:
+			info.State = "paused"
+		case ctrd.Stopped:
+			info.State = "exited"
+			info.ExitCode = int64(status.ExitStatus)
+			info.FinishedAt = status.ExitTime
+		}
+	}
+	return info, nil
+}
+
+// GetContainerHealthStatus reports the state of a container that does not run, containerd does not run healthchecks
+func (b *Backend) GetContainerHealthStatus(containerID string) (containers.HealthStatus, error) {
+	info, err := b.InspectContainer(context.Background(), containerID)
+	if err != nil {
+		return "", err
+	}
+	if info.State != "running" {
+		return containers.HealthStatus(info.State), nil
+	}
+	return containers.HealthNoHealthcheck, nil
+}
+
+// GetContainerStats only fills the sample time and the number of processes
+func (b *Backend) GetContainerStats(ctx context.Context, containerID string) (containers.ContainerStats, error) {
+	ctx = b.withNamespace(ctx)
+	stats := containers.ContainerStats{Read: time.Now()}
+	task, err := b.task(ctx, containerID)
+	if errdefs.IsNotFound(err) {
+		return stats, nil
+	}
+	if err == nil {
+		var pids []ctrd.ProcessInfo
+		pids, err = task.Pids(ctx)
+		stats.PIDs = uint64(len(pids))
+	}
+	if err != nil {
+		return stats, errors.New("[ERR:] [CONTAINERD] => FAILED TO GET STATS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return stats, nil
+}
+
+// GetHostPort returns the container port itself, containers share the host network
+func (b *Backend) GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error) {
+	ctx = b.withNamespace(ctx)
+	if _, err := b.client.LoadContainer(ctx, containerID); err != nil {
+		return "", errors.New("[ERR:] [CONTAINERD] => FAILED TO LOAD CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	port, _, _ := strings.Cut(containerPort, "/")
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", errors.New("[ERR:] [CONTAINERD] => INVALID CONTAINER PORT " + containerPort)
+	}
+	return port, nil
+}
+
+// GetContainerIPs returns no addresses, containers share the host network
+func (b *Backend) GetContainerIPs(ctx context.Context, containerID string) (map[string]containers.NetworkAddresses, error) {
+	ctx = b.withNamespace(ctx)
+	if _, err := b.client.LoadContainer(ctx, containerID); err != nil {
+		return nil, errors.New("[ERR:] [CONTAINERD] => FAILED TO LOAD CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return map[string]containers.NetworkAddresses{}, nil
+}
+
+func (b *Backend) Exec(containerID string, cmd []string) (string, error) {
+	result, err := b.ExecWithResult(context.Background(), containerID, cmd, containers.ExecOptions{})
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", errors.New("[ERR:] [CONTAINERD] => COMMAND EXITED WITH CODE " + fmt.Sprint(result.ExitCode) + " => " + result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+func (b *Backend) ExecWithResult(ctx context.Context, containerID string, cmd []string, opts containers.ExecOptions) (containers.ExecResult, error) {
+	ctx = b.withNamespace(ctx)
+	result := containers.ExecResult{ExitCode: -1}
+	fail := func(err error) (containers.ExecResult, error) {
+		return result, errors.New("[ERR:] [CONTAINERD] => FAILED TO EXEC IN CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	cont, err := b.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return fail(err)
+	}
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	spec, err := cont.Spec(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	process := *spec.Process
+	process.Args = cmd
+	process.Terminal = false
+	process.Env = append(append([]string{}, process.Env...), opts.Env...)
+	if opts.WorkingDir != "" {
+		process.Cwd = opts.WorkingDir
+	}
+
+	var stdout, stderr strings.Builder
+	started := time.Now()
+	proc, err := task.Exec(ctx, execID(), &process, cio.NewCreator(cio.WithStreams(opts.Stdin, &stdout, &stderr)))
+	if err != nil {
+		return fail(err)
+	}
+	defer proc.Delete(context.WithoutCancel(ctx))
+
+	statusCh, err := proc.Wait(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	if err := proc.Start(ctx); err != nil {
+		return fail(err)
+	}
+	select {
+	case status := <-statusCh:
+		code, _, err := status.Result()
+		if err != nil {
+			return fail(err)
+		}
+		result.ExitCode = int(code)
+	case <-ctx.Done():
+		proc.Kill(context.WithoutCancel(ctx), syscall.SIGKILL)
+		return fail(ctx.Err())
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Duration = time.Since(started)
+	return result, nil
+}
+
+// StreamContainerLogs follows the log file of a container. containerd keeps stdout and stderr in one file, so every line is reported on stdout
+func (b *Backend) StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(containers.LogLine)) error {
+	ctx = b.withNamespace(ctx)
+	file, err := os.Open(b.logPath(containerID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	for {
+		line, err := reader.ReadString('\n')
+		partial += line
+		if err == nil {
+			fn(containers.LogLine{Timestamp: time.Now(), Stream: containers.LogStreamStdout, Message: strings.TrimRight(partial, "\r\n")})
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return errors.New("[ERR:] [CONTAINERD] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		if !b.running(ctx, containerID) {
+			if partial != "" {
+				fn(containers.LogLine{Timestamp: time.Now(), Stream: containers.LogStreamStdout, Message: partial})
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+func (b *Backend) CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error {
+	return unsupported("COPYING FILES")
+}
+
+func (b *Backend) CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	return nil, unsupported("COPYING FILES")
+}
+
+func (b *Backend) WaitForExit(ctx context.Context, containerID string) (int64, error) {
+	ctx = b.withNamespace(ctx)
+	fail := func(err error) (int64, error) {
+		return -1, errors.New("[ERR:] [CONTAINERD] => FAILED TO WAIT FOR CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	task, err := b.task(ctx, containerID)
+	if errdefs.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return fail(err)
+	}
+	statusCh, err := task.Wait(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	select {
+	case status := <-statusCh:
+		code, _, err := status.Result()
+		if err != nil {
+			return fail(err)
+		}
+		return int64(code), nil
+	case <-ctx.Done():
+		return fail(ctx.Err())
+	}
+}
+
+// WaitForHealthy always fails, containerd does not run healthchecks
+func (b *Backend) WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	return errors.New("[ERR:] [CONTAINERD] => CONTAINER WITH ID: " + containerID + " HAS NO HEALTHCHECK")
+}
+
+func (b *Backend) CreateNetwork(ctx context.Context, name string, opts containers.NetworkOptions) (string, error) {
+	return "", unsupported("NETWORKS")
+}
+
+func (b *Backend) RemoveNetwork(ctx context.Context, name string) error {
+	return unsupported("NETWORKS")
+}
+
+func (b *Backend) ConnectNetwork(ctx context.Context, networkName string, containerID string, opts containers.EndpointOptions) error {
+	return unsupported("NETWORKS")
+}
+
+func (b *Backend) DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error {
+	return unsupported("NETWORKS")
+}
+
+func (b *Backend) InspectNetwork(ctx context.Context, name string) (containers.NetworkInfo, error) {
+	return containers.NetworkInfo{}, unsupported("NETWORKS")
+}
+
+// CreateVolume creates the directory of a volume. Like the daemon, creating an existing volume is a no-op
+func (b *Backend) CreateVolume(ctx context.Context, name string, opts containers.VolumeOptions) (string, error) {
+	if name == "" {
+		name = randomID()
+	}
+	fail := func(err error) (string, error) {
+		return "", errors.New("[ERR:] [CONTAINERD] => FAILED TO CREATE VOLUME " + name + " => " + err.Error())
+	}
+	if opts.Driver != "" && opts.Driver != "local" {
+		return fail(errors.New("ONLY THE LOCAL DRIVER IS SUPPORTED"))
+	}
+	if _, err := os.Stat(b.volumeMetaPath(name)); err == nil {
+		return name, nil
+	}
+	if err := os.MkdirAll(b.volumePath(name), 0o755); err != nil {
+		return fail(err)
+	}
+	meta, err := json.Marshal(volume.Volume{
+		Name:       name,
+		Driver:     "local",
+		Labels:     opts.Labels,
+		Options:    opts.DriverOpts,
+		Mountpoint: b.volumePath(name),
+		Scope:      "local",
+		CreatedAt:  time.Now().Format(time.RFC3339),
+	})
+	if err == nil {
+		err = os.WriteFile(b.volumeMetaPath(name), meta, 0o600)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return name, nil
+}
+
+// RemoveVolume removes the directory of a volume. Volumes mounted by a container are not removed, force or not
+func (b *Backend) RemoveVolume(ctx context.Context, name string, force bool) error {
+	ctx = b.withNamespace(ctx)
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [CONTAINERD] => FAILED TO REMOVE VOLUME " + name + " => " + reason)
+	}
+	if _, err := os.Stat(b.volumeMetaPath(name)); err != nil {
+		if force {
+			return nil
+		}
+		return fail("no such volume")
+	}
+	users, err := b.volumeUsers(ctx)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if len(users[b.volumePath(name)]) > 0 {
+		return fail("volume is in use - [" + strings.Join(users[b.volumePath(name)], ", ") + "]")
+	}
+	if err := os.RemoveAll(b.volumePath(name)); err != nil {
+		return fail(err.Error())
+	}
+	if err := os.Remove(b.volumeMetaPath(name)); err != nil {
+		return fail(err.Error())
+	}
+	return nil
+}
+
+func (b *Backend) ListVolumes(ctx context.Context, filter containers.VolumeFilter) ([]*volume.Volume, error) {
+	ctx = b.withNamespace(ctx)
+	fail := func(err error) ([]*volume.Volume, error) {
+		return nil, errors.New("[ERR:] [CONTAINERD] => FAILED TO LIST VOLUMES => " + err.Error())
+	}
+	entries, err := os.ReadDir(filepath.Join(b.stateDir, "volumes"))
+	if err != nil {
+		return fail(err)
+	}
+	users, err := b.volumeUsers(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	var list []*volume.Volume
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(b.stateDir, "volumes", entry.Name()))
+		if err != nil {
+			return fail(err)
+		}
+		v := &volume.Volume{}
+		if err := json.Unmarshal(data, v); err != nil {
+			return fail(err)
+		}
+		if filter.Name != "" && !strings.Contains(v.Name, filter.Name) {
+			continue
+		}
+		if filter.Driver != "" && v.Driver != filter.Driver {
+			continue
+		}
+		if !matchLabels(v.Labels, filter.Labels) {
+			continue
+		}
+		if filter.Dangling != nil && *filter.Dangling != (len(users[v.Mountpoint]) == 0) {
+			continue
+		}
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (b *Backend) withNamespace(ctx context.Context) context.Context {
+	return namespaces.WithNamespace(ctx, b.namespace)
+}
+
+func (b *Backend) task(ctx context.Context, containerID string) (ctrd.Task, error) {
+	cont, err := b.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return cont.Task(ctx, nil)
+}
+
+func (b *Backend) running(ctx context.Context, containerID string) bool {
+	task, err := b.task(ctx, containerID)
+	if err != nil {
+		return false
+	}
+	status, err := task.Status(ctx)
+	return err == nil && (status.Status == ctrd.Running || status.Status == ctrd.Paused || status.Status == ctrd.Pausing)
+}
+
+// start creates and starts the task of a container, replacing the task of a previous run. A running container is left alone
+func (b *Backend) start(ctx context.Context, containerID string) error {
+	cont, err := b.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	if task, err := cont.Task(ctx, nil); err == nil {
+		status, err := task.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Status != ctrd.Stopped {
+			return nil
+		}
+		if _, err := task.Delete(ctx); err != nil {
+			return err
+		}
+	} else if !errdefs.IsNotFound(err) {
+		return err
+	}
+
+	task, err := cont.NewTask(ctx, cio.LogFile(b.logPath(containerID)))
+	if err != nil {
+		return err
+	}
+	return task.Start(ctx)
+}
+
+// stop sends SIGTERM to the task of a container, SIGKILL once timeout expires, and deletes the task. A zero timeout kills right away
+func (b *Backend) stop(ctx context.Context, containerID string, timeout time.Duration) error {
+	task, err := b.task(ctx, containerID)
+	if errdefs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Status != ctrd.Stopped {
+		if status.Status == ctrd.Paused || status.Status == ctrd.Pausing {
+			// A frozen process cannot handle SIGTERM
+			if err := task.Resume(ctx); err != nil {
+				return err
+			}
+		}
+		statusCh, err := task.Wait(ctx)
+		if err != nil {
+			return err
+		}
+		signal := syscall.SIGTERM
+		if timeout <= 0 {
+			signal = syscall.SIGKILL
+		}
+		if err := task.Kill(ctx, signal); err != nil && !errdefs.IsNotFound(err) {
+			return err
+		}
+		select {
+		case <-statusCh:
+		case <-time.After(timeout):
+			if err := task.Kill(ctx, syscall.SIGKILL); err != nil && !errdefs.IsNotFound(err) {
+				return err
+			}
+			<-statusCh
+		}
+	}
+	_, err = task.Delete(ctx)
+	return err
+}
+
+// specMounts translates the mounts and binds of a host config. Named volumes are bound from their directory, which is created on first use
+func (b *Backend) specMounts(hostConfig *container.HostConfig) ([]specs.Mount, error) {
+	if hostConfig == nil {
+		return nil, nil
+	}
+	var mounts []specs.Mount
+	bind := func(source string, target string, readOnly bool) {
+		mode := "rw"
+		if readOnly {
+			mode = "ro"
+		}
+		mounts = append(mounts, specs.Mount{Type: "bind", Source: source, Destination: target, Options: []string{"rbind", mode}})
+	}
+	volumeSource := func(name string) (string, error) {
+		if _, err := b.CreateVolume(context.Background(), name, containers.VolumeOptions{}); err != nil {
+			return "", err
+		}
+		return b.volumePath(name), nil
+	}
+
+	for _, m := range hostConfig.Mounts {
+		switch m.Type {
+		case mount.TypeBind:
+			bind(m.Source, m.Target, m.ReadOnly)
+		case mount.TypeVolume:
+			source, err := volumeSource(m.Source)
+			if err != nil {
+				return nil, err
+			}
+			bind(source, m.Target, m.ReadOnly)
+		case mount.TypeTmpfs:
+			mounts = append(mounts, specs.Mount{Type: "tmpfs", Source: "tmpfs", Destination: m.Target, Options: []string{"nosuid", "nodev", "noexec"}})
+		default:
+			return nil, errors.New("MOUNT TYPE " + string(m.Type) + " IS NOT SUPPORTED")
+		}
+	}
+	for _, spec := range hostConfig.Binds {
+		source, target, readOnly, err := containers.ParseBind(spec)
+		if err != nil {
+			return nil, errors.New("INVALID BIND " + spec)
+		}
+		if !filepath.IsAbs(source) {
+			if source, err = volumeSource(source); err != nil {
+				return nil, err
+			}
+		}
+		bind(source, target, readOnly)
+	}
+	return mounts, nil
+}
+
+// volumeUsers maps the directory of every mounted volume to the containers that mount it
+func (b *Backend) volumeUsers(ctx context.Context) (map[string][]string, error) {
+	list, err := b.client.Containers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users := map[string][]string{}
+	volumes := filepath.Join(b.stateDir, "volumes") + string(filepath.Separator)
+	for _, cont := range list {
+		spec, err := cont.Spec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range spec.Mounts {
+			if strings.HasPrefix(m.Source, volumes) {
+				users[m.Source] = append(users[m.Source], cont.ID())
+			}
+		}
+	}
+	return users, nil
+}
+
+func (b *Backend) logPath(containerID string) string {
+	return filepath.Join(b.stateDir, "logs", b.namespace+"-"+containerID+".log")
+}
+
+func (b *Backend) volumePath(name string) string {
+	return filepath.Join(b.stateDir, "volumes", name)
+}
+
+func (b *Backend) volumeMetaPath(name string) string {
+	return filepath.Join(b.stateDir, "volumes", name+".json")
+}
+
+// normalizeImage expands short image names, containerd only knows fully qualified references such as docker.io/library/redis:latest
+func normalizeImage(imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", errors.New("[ERR:] [CONTAINERD] => INVALID IMAGE REFERENCE " + imageName + " => " + err.Error())
+	}
+	return reference.TagNameOnly(named).String(), nil
+}
+
+func matchLabels(labels map[string]string, want map[string]string) bool {
+	for key, value := range want {
+		got, ok := labels[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+func randomID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func execID() string {
+	return "exec-" + randomID()[:16]
+}
+
+func unsupported(what string) error {
+	return errors.New("[ERR:] [CONTAINERD] => " + what + " NOT SUPPORTED BY THE CONTAINERD BACKEND")
+}
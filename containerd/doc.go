@@ -0,0 +1,9 @@
+// Package containerd implements containers.ContainerManager against the containerd API, for hosts that run containerd without dockerd.
+// Containers and images live in a containerd namespace and containers share the host network, so there are no networks to manage and
+// container ports are reachable on the same host ports. Named volumes are directories under the state directory. Builds, renames,
+// copies, healthchecks and networks are not supported by containerd and fail with an error.
+//
+// The backend pulls in the containerd client and its gRPC stack, so it is only compiled with the containerd build tag:
+//
+//	go build -tags containerd ./...
+package containerd
@@ -1,12 +1,12 @@
 package containers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -14,7 +14,6 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
-	"github.com/docker/docker/pkg/stdcopy"
 )
 
 // DockerClient ~ The docker client
@@ -59,6 +58,12 @@ func ListContainers() error {
 
 // BuildImage ~ Builds an image
 func BuildImage(path string, imageName string) error {
+	return BuildImageContext(context.Background(), path, imageName)
+}
+
+// BuildImageContext ~ Builds an image, cancelling the build when the context is done. The operation is reported to observers with ctx,
+// so it joins the trace and carries the actor of the caller
+func BuildImageContext(ctx context.Context, path string, imageName string) error {
 	buildCtx, buildCtxErr := archive.Tar(path, archive.Uncompressed)
 	if buildCtxErr != nil {
 		return errors.New("[ERR:] [DOCKER] => FAILED TO CREATE BUILD CONTEXT FOR IMAGE " + imageName + " => " + buildCtxErr.Error())
@@ -71,10 +76,16 @@ func BuildImage(path string, imageName string) error {
 		Remove:         true,
 		NoCache:        true,
 		SuppressOutput: false,
+		Labels:         withManagedLabel(nil),
 	}
 
-	image, imgErr := DockerClient.ImageBuild(context.Background(), buildCtx, buildOptions)
+	if dryRun(OpBuildImage, imageName, imageName) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpBuildImage, imageName, imageName)
+	image, imgErr := DockerClient.ImageBuild(ctx, buildCtx, buildOptions)
 	if imgErr != nil {
+		done(imgErr)
 		return errors.New("[ERR:] [DOCKER] => FAILED TO BUILD IMAGE " + imageName + " => " + imgErr.Error())
 	}
 	for {
@@ -85,18 +96,51 @@ func BuildImage(path string, imageName string) error {
 			break
 		}
 	}
+	done(nil)
 	return nil
 }
 
 // CreateContainer ~ Creates a container
 func CreateContainer(config *ContainerCreateConfig) (container.CreateResponse, error) {
-	containerRes, err := DockerClient.ContainerCreate(context.Background(),
-		config.Config,
+	return CreateContainerContext(context.Background(), config)
+}
+
+// CreateContainerContext ~ Creates a container, pulling and verifying its image first as configured. The operations are reported to
+// observers with ctx, so they join the trace and carry the actor of the caller
+func CreateContainerContext(ctx context.Context, config *ContainerCreateConfig) (container.CreateResponse, error) {
+	config = adaptCreateConfig(config)
+	containerConfig := config.Config
+	if managedBy != "" {
+		stamped := container.Config{}
+		if containerConfig != nil {
+			stamped = *containerConfig
+		}
+		stamped.Labels = withManagedLabel(stamped.Labels)
+		containerConfig = &stamped
+	}
+
+	imageName := ""
+	if containerConfig != nil {
+		imageName = containerConfig.Image
+	}
+	if dryRun(OpCreateContainer, config.Name, imageName) {
+		return container.CreateResponse{ID: dryRunID(config.Name)}, nil
+	}
+	if err := applyPullPolicy(ctx, imageName, config.PullPolicy); err != nil {
+		return container.CreateResponse{}, err
+	}
+	if err := enforceSignature(ctx, imageName); err != nil {
+		return container.CreateResponse{}, err
+	}
+	ctx, done := beginOperation(ctx, OpCreateContainer, config.Name, imageName)
+	containerRes, err := DockerClient.ContainerCreate(ctx,
+		containerConfig,
 		config.HostConfig,
 		config.NetworkingConfig,
 		config.Platform,
 		config.Name,
 	)
+	done(err)
 	if err != nil {
 		return containerRes, errors.New("[ERR:] [DOCKER] => FAILED TO CREATE CONTAINER " + config.Name + " => " + err.Error())
 	}
@@ -106,50 +150,231 @@ func CreateContainer(config *ContainerCreateConfig) (container.CreateResponse, e
 
 // StartContainer ~ Starts a container
 func StartContainer(cont container.CreateResponse) error {
-	err := DockerClient.ContainerStart(context.Background(), cont.ID, container.StartOptions{})
+	return StartContainerContext(context.Background(), cont)
+}
+
+// StartContainerContext ~ Starts a container, reporting the operation to observers with ctx
+func StartContainerContext(ctx context.Context, cont container.CreateResponse) error {
+	if dryRun(OpStartContainer, cont.ID, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpStartContainer, cont.ID, "")
+	err := retry(ctx, func() error {
+		return DockerClient.ContainerStart(ctx, cont.ID, container.StartOptions{})
+	})
+	done(err)
 	if err != nil {
 		return errors.New("[ERR:] [DOCKER] => FAILED TO START CONTAINER WITH ID: " + cont.ID + " => " + err.Error())
 	}
+	notifyStarted(cont.ID)
 	return nil
 }
 
 // StopContainer ~ Stops a container
 func StopContainer(containerID string) error {
-	err := DockerClient.ContainerStop(context.Background(), containerID, container.StopOptions{
-		Signal: "SIGTERM",
+	return StopContainerContext(context.Background(), containerID)
+}
+
+// StopContainerContext ~ Stops a container, reporting the operation to observers with ctx
+func StopContainerContext(ctx context.Context, containerID string) error {
+	if dryRun(OpStopContainer, containerID, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpStopContainer, containerID, "")
+	err := retry(ctx, func() error {
+		return DockerClient.ContainerStop(ctx, containerID, container.StopOptions{
+			Signal: "SIGTERM",
+		})
 	})
+	done(err)
 	if err != nil {
 		return errors.New("[ERR:] [DOCKER] => FAILED TO STOP CONTAINER WITH ID: " + containerID + " => " + err.Error())
 	}
 	return nil
 }
 
+// RestartContainer ~ Restarts a container, killing it if it does not stop within timeout
+func RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	timeoutSeconds := int(timeout.Seconds())
+	if dryRun(OpRestartContainer, containerID, "", "timeout", timeout.String()) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpRestartContainer, containerID, "", "timeout", timeout.String())
+	err := retry(ctx, func() error {
+		return DockerClient.ContainerRestart(ctx, containerID, container.StopOptions{
+			Signal:  "SIGTERM",
+			Timeout: &timeoutSeconds,
+		})
+	})
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO RESTART CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// PauseContainer ~ Freezes all processes of a running container
+func PauseContainer(ctx context.Context, containerID string) error {
+	if dryRun(OpPauseContainer, containerID, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpPauseContainer, containerID, "")
+	err := DockerClient.ContainerPause(ctx, containerID)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO PAUSE CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// UnpauseContainer ~ Resumes all processes of a paused container
+func UnpauseContainer(ctx context.Context, containerID string) error {
+	if dryRun(OpUnpauseContainer, containerID, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpUnpauseContainer, containerID, "")
+	err := DockerClient.ContainerUnpause(ctx, containerID)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO UNPAUSE CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// KillContainer ~ Sends a signal (e.g. SIGHUP, SIGUSR1, SIGKILL) to the main process of a container
+func KillContainer(ctx context.Context, containerID string, signal string) error {
+	if dryRun(OpKillContainer, containerID, "", "signal", signal) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpKillContainer, containerID, "", "signal", signal)
+	err := DockerClient.ContainerKill(ctx, containerID, signal)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO SEND " + signal + " TO CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// RenameContainer ~ Renames a container
+func RenameContainer(ctx context.Context, containerID string, newName string) error {
+	if dryRun(OpRenameContainer, containerID, "", "name", newName) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpRenameContainer, containerID, "", "name", newName)
+	err := DockerClient.ContainerRename(ctx, containerID, newName)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO RENAME CONTAINER WITH ID: " + containerID + " TO " + newName + " => " + err.Error())
+	}
+	return nil
+}
+
+// UpdateContainerResources ~ Adjusts the resource limits of a running container without recreating it. Returns any warnings of the daemon
+func UpdateContainerResources(ctx context.Context, containerID string, update ResourceUpdate) ([]string, error) {
+	if dryRun(OpUpdateContainer, containerID, "") {
+		return nil, nil
+	}
+	ctx, done := beginOperation(ctx, OpUpdateContainer, containerID, "")
+	updateRes, err := DockerClient.ContainerUpdate(ctx, containerID, container.UpdateConfig{
+		Resources: container.Resources{
+			CPUShares:         update.CPUShares,
+			CPUPeriod:         update.CPUPeriod,
+			CPUQuota:          update.CPUQuota,
+			NanoCPUs:          update.NanoCPUs,
+			CpusetCpus:        update.CpusetCpus,
+			Memory:            update.Memory,
+			MemoryReservation: update.MemoryReservation,
+			MemorySwap:        update.MemorySwap,
+			PidsLimit:         update.PidsLimit,
+			BlkioWeight:       update.BlkioWeight,
+		},
+	})
+	done(err)
+	if err != nil {
+		return updateRes.Warnings, errors.New("[ERR:] [DOCKER] => FAILED TO UPDATE RESOURCES OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return updateRes.Warnings, nil
+}
+
 // PurgeContainer ~ Purges a stopped container
 func PurgeContainer(containerID string) error {
+	return PurgeContainerContext(context.Background(), containerID)
+}
+
+// PurgeContainerContext ~ Purges a stopped container, reporting the operation to observers with ctx
+func PurgeContainerContext(ctx context.Context, containerID string) error {
 	removeOptions := container.RemoveOptions{
 		RemoveVolumes: true,
 		RemoveLinks:   false,
 		Force:         true,
 	}
-	err := DockerClient.ContainerRemove(context.Background(), containerID, removeOptions)
+	if dryRun(OpPurgeContainer, containerID, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpPurgeContainer, containerID, "")
+	err := DockerClient.ContainerRemove(ctx, containerID, removeOptions)
+	done(err)
 	if err != nil {
 		return errors.New("[ERR:] [DOCKER] => FAILED TO PURGE CONTAINER WITH ID: " + containerID + " => " + err.Error())
 	}
 	return nil
 }
 
+// CommitContainer ~ Captures the current state of a container as a new image tagged ref and returns the image ID
+func CommitContainer(ctx context.Context, containerID string, ref string, opts container.CommitOptions) (string, error) {
+	opts.Reference = ref
+	if managedBy != "" {
+		opts.Changes = append(opts.Changes, "LABEL "+ManagedByLabel+"="+managedBy)
+	}
+	if dryRun(OpCommitContainer, containerID, ref) {
+		return dryRunID(ref), nil
+	}
+	ctx, done := beginOperation(ctx, OpCommitContainer, containerID, ref)
+	commitRes, err := DockerClient.ContainerCommit(ctx, containerID, opts)
+	done(err)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO COMMIT CONTAINER WITH ID: " + containerID + " TO IMAGE " + ref + " => " + err.Error())
+	}
+	return commitRes.ID, nil
+}
+
+// ExportContainer ~ Writes the root filesystem of a container to w as a tar archive
+func ExportContainer(ctx context.Context, containerID string, w io.Writer) error {
+	export, err := DockerClient.ContainerExport(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO EXPORT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer export.Close()
+
+	_, err = io.Copy(w, export)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO WRITE EXPORT OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
 // DeleteImage ~ Deletes an image
 func DeleteImage(imageName string) (bool, error) {
-	img, _, imgErr := DockerClient.ImageInspectWithRaw(context.Background(), imageName)
+	return DeleteImageContext(context.Background(), imageName)
+}
+
+// DeleteImageContext ~ Deletes an image, reporting the operation to observers with ctx
+func DeleteImageContext(ctx context.Context, imageName string) (bool, error) {
+	img, _, imgErr := DockerClient.ImageInspectWithRaw(ctx, imageName)
 	exists := true
 	if imgErr != nil {
 		exists = false
 	}
 	if exists {
-		_, imgRemoveErr := DockerClient.ImageRemove(context.Background(), img.ID, image.RemoveOptions{
+		if dryRun(OpDeleteImage, imageName, imageName) {
+			return exists, nil
+		}
+		ctx, done := beginOperation(ctx, OpDeleteImage, imageName, imageName)
+		_, imgRemoveErr := DockerClient.ImageRemove(ctx, img.ID, image.RemoveOptions{
 			Force:         true,
 			PruneChildren: true,
 		})
+		done(imgRemoveErr)
 		if imgRemoveErr != nil {
 			return exists, errors.New("[ERR:] [DOCKER] => FAILED TO DELETE IMAGE: " + imageName + " | => " + imgRemoveErr.Error())
 		}
@@ -160,10 +385,20 @@ func DeleteImage(imageName string) (bool, error) {
 
 // PruneDanglingImages ~ Prunes all dangling images
 func PruneDanglingImages() (image.PruneReport, error) {
+	return PruneDanglingImagesContext(context.Background())
+}
+
+// PruneDanglingImagesContext ~ Prunes all dangling images, reporting the operation to observers with ctx
+func PruneDanglingImagesContext(ctx context.Context) (image.PruneReport, error) {
 	pruneFilters := filters.NewArgs()
 	pruneFilters.Add("dangling", "true")
 
-	pruneReport, pruneErr := DockerClient.ImagesPrune(context.Background(), pruneFilters)
+	if dryRun(OpPruneImages, "", "", "dangling", "true") {
+		return image.PruneReport{}, nil
+	}
+	ctx, done := beginOperation(ctx, OpPruneImages, "", "", "dangling", "true")
+	pruneReport, pruneErr := DockerClient.ImagesPrune(ctx, pruneFilters)
+	done(pruneErr)
 	if pruneErr != nil {
 		return pruneReport, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE DANGLING IMAGES  | => " + pruneErr.Error())
 	}
@@ -171,56 +406,42 @@ func PruneDanglingImages() (image.PruneReport, error) {
 	return pruneReport, nil
 }
 
-// GetContainerHealthStatus ~ Gets the health status of a container
-func GetContainerHealthStatus(containerID string) (string, error) {
-	// Starting, Healthy or Unhealthy
-	containerJSON, err := DockerClient.ContainerInspect(context.Background(), containerID)
+// GetContainerHealthStatus ~ Gets the health status of a container: HealthStarting, HealthHealthy or HealthUnhealthy from its
+// healthcheck and HealthNoHealthcheck when it runs without one. A container that does not run reports its state instead: HealthCreated,
+// HealthRestarting, HealthPaused, HealthExited, HealthRemoving or HealthDead. The status is empty when the container cannot be inspected
+func GetContainerHealthStatus(containerID string) (HealthStatus, error) {
+	containerJSON, err := retryResult(context.Background(), func() (types.ContainerJSON, error) {
+		return inspectContainer(context.Background(), containerID)
+	})
 	if err != nil {
-		return "unhealthy", err
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
 	}
 
-	return containerJSON.State.Health.Status, nil
-}
-
-// Exec executes a command on a running container
-func Exec(containerID string, cmd []string) (string, error) {
-
-	execConfig := container.ExecOptions{
-		Cmd:          cmd,
-		AttachStdout: true,
-		AttachStderr: true,
+	state := containerJSON.State
+	if state == nil {
+		return "", errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " HAS NO STATE")
 	}
-
-	execIDResp, err := DockerClient.ContainerExecCreate(context.Background(), containerID, execConfig)
-	if err != nil {
-		return "", errors.New("[ERR:] [DOCKER] => FAILED TO CREATE EXEC ISNTANCE => " + err.Error())
+	// A restarting or paused container is still reported as running
+	if state.Restarting {
+		return HealthRestarting, nil
 	}
-
-	// Attach to the exec instance
-	resp, err := DockerClient.ContainerExecAttach(context.Background(), execIDResp.ID, container.ExecAttachOptions{})
-	if err != nil {
-		return "", errors.New("[ERR:] [DOCKER] => FAILED TO ATTACH TO EXEC INSTANCE => " + err.Error())
+	if state.Paused {
+		return HealthPaused, nil
 	}
-
-	defer resp.Close()
-	var outBuf, errBuf bytes.Buffer
-
-	// Copy the output of the command to the buffers
-	_, err = stdcopy.StdCopy(&outBuf, &errBuf, resp.Reader)
-	if err != nil {
-		return "", errors.New("[ERR:] [DOCKER] => FAILED TO COPY EXEC OUTPUT => " + err.Error())
+	if !state.Running {
+		return HealthStatus(state.Status), nil
 	}
-
-	// Inspect exec instance to get the exit code
-	execInspectResp, err := DockerClient.ContainerExecInspect(context.Background(), execIDResp.ID)
-	if err != nil {
-		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT EXEC INSTANCE => " + err.Error())
+	if state.Health == nil || state.Health.Status == "" || state.Health.Status == types.NoHealthcheck {
+		return HealthNoHealthcheck, nil
 	}
+	return HealthStatus(state.Health.Status), nil
+}
 
-	if execInspectResp.ExitCode != 0 {
-		return "", errors.New("[ERR:] [DOCKER] => COMMAND EXITIED WITH CODE " + fmt.Sprint(execInspectResp.ExitCode) + " => " + errBuf.String())
+// EnsureImage ~ Pulls an image if it is not present locally
+func EnsureImage(ctx context.Context, imageName string) error {
+	_, _, err := DockerClient.ImageInspectWithRaw(ctx, imageName)
+	if err == nil {
+		return nil
 	}
-
-	psOutput := outBuf.String()
-	return psOutput, nil
+	return PullImage(ctx, imageName)
 }
@@ -0,0 +1,226 @@
+// Package containerstest starts throwaway containers for integration tests, e.g. a Postgres or Redis instance per test, and removes
+// them when the test ends
+package containerstest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+)
+
+// DefaultTimeout ~ How long StartTestContainer waits for a container to become ready when the spec sets no timeout
+var DefaultTimeout = time.Minute
+
+// WaitStrategy ~ Decides when a started container is ready. It blocks until the container is ready, the timeout expires or ctx is done
+type WaitStrategy func(ctx context.Context, containerID string, timeout time.Duration) error
+
+// Spec ~ Describes a test container. Ports are container ports (e.g. "5432/tcp") published on random host ports. Create is an
+// optional base config that Image, Cmd and Env are applied on top of. An empty Name is derived from the test name
+type Spec struct {
+	Image   string
+	Name    string
+	Cmd     []string
+	Env     []string
+	Ports   []string
+	WaitFor WaitStrategy
+	Timeout time.Duration
+	Create  *containers.ContainerCreateConfig
+}
+
+// Container ~ A started test container. Its accessors fail the test instead of returning errors
+type Container struct {
+	ID   string
+	Name string
+
+	tb testing.TB
+}
+
+// StartTestContainer ~ Pulls the image if needed, creates and starts the container and waits until spec.WaitFor reports it ready.
+// The container is removed with its anonymous volumes when the test and its subtests complete, also when starting it fails.
+// The docker client is initialized from the environment if the caller has not done so
+func StartTestContainer(tb testing.TB, spec Spec) *Container {
+	tb.Helper()
+
+	if containers.DockerClient == nil {
+		if err := containers.InitializeDockerClient(); err != nil {
+			tb.Fatalf("containerstest: %v", err)
+		}
+	}
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx := context.Background()
+
+	if err := containers.EnsureImage(ctx, spec.Image); err != nil {
+		tb.Fatalf("containerstest: %v", err)
+	}
+	config, err := spec.createConfig(tb)
+	if err != nil {
+		tb.Fatalf("containerstest: %v", err)
+	}
+	cont, err := containers.CreateContainer(config)
+	if err != nil {
+		tb.Fatalf("containerstest: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := containers.PurgeContainer(cont.ID); err != nil {
+			tb.Logf("containerstest: %v", err)
+		}
+	})
+	if err := containers.StartContainer(cont); err != nil {
+		tb.Fatalf("containerstest: %v", err)
+	}
+
+	if spec.WaitFor != nil {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if err := spec.WaitFor(waitCtx, cont.ID, timeout); err != nil {
+			tb.Fatalf("containerstest: container %s is not ready: %v", config.Name, err)
+		}
+	}
+	return &Container{ID: cont.ID, Name: config.Name, tb: tb}
+}
+
+// createConfig merges the shortcuts of a Spec into its container config and names it after the test if it has no name
+func (spec Spec) createConfig(tb testing.TB) (*containers.ContainerCreateConfig, error) {
+	config := &containers.ContainerCreateConfig{}
+	if spec.Create != nil {
+		*config = *spec.Create
+	}
+	if config.Config == nil {
+		config.Config = &container.Config{}
+	} else {
+		containerConfig := *config.Config
+		config.Config = &containerConfig
+	}
+	if config.HostConfig != nil {
+		hostConfig := *config.HostConfig
+		config.HostConfig = &hostConfig
+	}
+	config.Config.Image = spec.Image
+	if len(spec.Cmd) > 0 {
+		config.Config.Cmd = spec.Cmd
+	}
+	if len(spec.Env) > 0 {
+		config.Config.Env = append(append([]string(nil), config.Config.Env...), spec.Env...)
+	}
+	for _, port := range spec.Ports {
+		if err := containers.PublishRandomPort(config, port); err != nil {
+			return nil, err
+		}
+	}
+	if spec.Name != "" {
+		config.Name = spec.Name
+	}
+	if config.Name == "" {
+		config.Name = testContainerName(tb.Name())
+	}
+	return config, nil
+}
+
+// Host ~ Returns the host the published ports are reachable on: the host of DOCKER_HOST for remote daemons, localhost otherwise
+func (c *Container) Host() string {
+	daemon, err := url.Parse(containers.DockerClient.DaemonHost())
+	if err != nil {
+		return "localhost"
+	}
+	switch daemon.Scheme {
+	case "tcp", "http", "https":
+		if host := daemon.Hostname(); host != "" {
+			return host
+		}
+	}
+	return "localhost"
+}
+
+// Port ~ Returns the host port a container port (e.g. "5432/tcp") is published on
+func (c *Container) Port(containerPort string) string {
+	c.tb.Helper()
+	port, err := containers.GetHostPort(context.Background(), c.ID, containerPort)
+	if err != nil {
+		c.tb.Fatalf("containerstest: %v", err)
+	}
+	return port
+}
+
+// Address ~ Returns the host:port a container port is reachable on, ready to be dialed
+func (c *Container) Address(containerPort string) string {
+	c.tb.Helper()
+	return net.JoinHostPort(c.Host(), c.Port(containerPort))
+}
+
+// Exec ~ Executes a command in the container and returns its stdout, failing the test on a non-zero exit code
+func (c *Container) Exec(cmd ...string) string {
+	c.tb.Helper()
+	out, err := containers.Exec(c.ID, cmd)
+	if err != nil {
+		c.tb.Fatalf("containerstest: %v", err)
+	}
+	return out
+}
+
+// ForLog ~ Waits for a log line matching a regular expression, e.g. "database system is ready to accept connections"
+func ForLog(pattern string) WaitStrategy {
+	re := regexp.MustCompile(pattern)
+	return func(ctx context.Context, containerID string, timeout time.Duration) error {
+		_, err := containers.WaitForLogLine(ctx, containerID, re, timeout)
+		return err
+	}
+}
+
+// ForPort ~ Waits until a published container port accepts TCP connections
+func ForPort(containerPort string) WaitStrategy {
+	return func(ctx context.Context, containerID string, timeout time.Duration) error {
+		_, err := containers.WaitForPort(ctx, containerID, containerPort, timeout)
+		return err
+	}
+}
+
+// ForHealthy ~ Waits until the healthcheck of the container reports healthy
+func ForHealthy() WaitStrategy {
+	return func(ctx context.Context, containerID string, timeout time.Duration) error {
+		return containers.WaitForHealthy(ctx, containerID, timeout, 500*time.Millisecond)
+	}
+}
+
+// ForAll ~ Waits for every strategy in order, all of them within the same timeout
+func ForAll(strategies ...WaitStrategy) WaitStrategy {
+	return func(ctx context.Context, containerID string, timeout time.Duration) error {
+		deadline := time.Now().Add(timeout)
+		for _, strategy := range strategies {
+			if err := strategy(ctx, containerID, time.Until(deadline)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// testContainerName turns a test name into a valid, unique container name
+func testContainerName(testName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, testName)
+	if len(name) > 48 {
+		name = name[:48]
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return "test-" + strings.Trim(name, "-") + "-" + hex.EncodeToString(suffix)
+}
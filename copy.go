@@ -0,0 +1,131 @@
+package containers
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/archive"
+)
+
+// CopyToContainer ~ Copies src into destPath of a container. src can be a local file or directory path (string), an fs.FS or a tar stream (io.Reader)
+func CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error {
+	if dryRun(OpCopyToContainer, containerID, "", "path", destPath) {
+		return nil
+	}
+	content, err := tarCopySource(src)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO PACKAGE FILES FOR CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer content.Close()
+
+	ctx, done := beginOperation(ctx, OpCopyToContainer, containerID, "", "path", destPath)
+	err = DockerClient.CopyToContainer(ctx, containerID, destPath, content, container.CopyToContainerOptions{})
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO COPY FILES TO " + destPath + " OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// tarCopySource packages a copy source as an uncompressed tar stream
+func tarCopySource(src interface{}) (io.ReadCloser, error) {
+	switch source := src.(type) {
+	case string:
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return archive.Tar(source, archive.Uncompressed)
+		}
+		return archive.TarWithOptions(filepath.Dir(source), &archive.TarOptions{
+			Compression:  archive.Uncompressed,
+			IncludeFiles: []string{filepath.Base(source)},
+		})
+	case fs.FS:
+		return tarFS(source), nil
+	case io.Reader:
+		return io.NopCloser(source), nil
+	default:
+		return nil, errors.New("UNSUPPORTED COPY SOURCE, EXPECTED A PATH, AN fs.FS OR AN io.Reader")
+	}
+}
+
+// tarFS streams the contents of fsys as a tar archive
+func tarFS(fsys fs.FS) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if name == "." {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = name
+			if d.IsDir() {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			file, err := fsys.Open(name)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tw, file)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// CopyFromContainer ~ Copies srcPath of a container into the local directory destDir
+func CopyFromContainer(ctx context.Context, containerID string, srcPath string, destDir string) error {
+	content, err := CopyFromContainerStream(ctx, containerID, srcPath)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO CREATE DIRECTORY " + destDir + " => " + err.Error())
+	}
+	err = archive.Untar(content, destDir, &archive.TarOptions{NoLchown: true})
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO UNPACK " + srcPath + " OF CONTAINER WITH ID: " + containerID + " INTO " + destDir + " => " + err.Error())
+	}
+	return nil
+}
+
+// CopyFromContainerStream ~ Returns srcPath of a container as a tar stream. The caller must close it
+func CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	content, _, err := DockerClient.CopyFromContainer(ctx, containerID, srcPath)
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO COPY " + srcPath + " FROM CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return content, nil
+}
@@ -0,0 +1,93 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DependencyStarted ~ The dependency only has to be started
+	DependencyStarted = "started"
+	// DependencyHealthy ~ The dependency has to pass its healthcheck
+	DependencyHealthy = "healthy"
+	// DependencyCompleted ~ The dependency has to run to completion and exit with code 0
+	DependencyCompleted = "completed"
+)
+
+// DependencyTimeout ~ How long a dependent container waits for a dependency to become healthy or to complete
+var DependencyTimeout = 2 * time.Minute
+
+// orderSpecs sorts specs so that every spec comes after its dependencies, keeping the given order where dependencies allow.
+// Unknown dependencies, unknown conditions and cycles are reported as errors
+func orderSpecs(specs []ContainerSpec) ([]ContainerSpec, error) {
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		index[spec.Name] = i
+	}
+	for _, spec := range specs {
+		for dependency, condition := range spec.DependsOn {
+			if _, ok := index[dependency]; !ok {
+				return nil, errors.New("[ERR:] [DEPENDENCY] => " + spec.Name + " DEPENDS ON UNKNOWN CONTAINER " + dependency)
+			}
+			switch condition {
+			case DependencyStarted, DependencyHealthy, DependencyCompleted:
+			default:
+				return nil, errors.New("[ERR:] [DEPENDENCY] => UNKNOWN CONDITION " + condition + " FOR DEPENDENCY " + dependency + " OF " + spec.Name)
+			}
+		}
+	}
+
+	ordered := make([]ContainerSpec, 0, len(specs))
+	state := make(map[string]int, len(specs)) // 1 visiting, 2 done
+	var visit func(spec ContainerSpec, path []string) error
+	visit = func(spec ContainerSpec, path []string) error {
+		switch state[spec.Name] {
+		case 1:
+			return errors.New("[ERR:] [DEPENDENCY] => DEPENDENCY CYCLE " + strings.Join(append(path, spec.Name), " -> "))
+		case 2:
+			return nil
+		}
+		state[spec.Name] = 1
+		// Dependencies are visited in the order their specs were given so the result does not depend on map iteration
+		for _, candidate := range specs {
+			if _, ok := spec.DependsOn[candidate.Name]; ok {
+				if err := visit(candidate, append(path, spec.Name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[spec.Name] = 2
+		ordered = append(ordered, spec)
+		return nil
+	}
+	for _, spec := range specs {
+		if err := visit(spec, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// waitForDependency blocks until the container with the given ID meets condition
+func waitForDependency(ctx context.Context, name string, containerID string, condition string) error {
+	switch condition {
+	case DependencyHealthy:
+		if err := WaitForHealthy(ctx, containerID, DependencyTimeout, time.Second); err != nil {
+			return errors.New("[ERR:] [DEPENDENCY] => " + name + " DID NOT BECOME HEALTHY => " + err.Error())
+		}
+	case DependencyCompleted:
+		waitCtx, cancel := context.WithTimeout(ctx, DependencyTimeout)
+		defer cancel()
+		exitCode, err := WaitForExit(waitCtx, containerID)
+		if err != nil {
+			return errors.New("[ERR:] [DEPENDENCY] => " + name + " DID NOT COMPLETE => " + err.Error())
+		}
+		if exitCode != 0 {
+			return errors.New("[ERR:] [DEPENDENCY] => " + name + " EXITED WITH CODE " + fmt.Sprint(exitCode))
+		}
+	}
+	return nil
+}
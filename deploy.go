@@ -0,0 +1,168 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// deployCandidateSuffix ~ Appended to the name of the new container while it runs next to the old one
+const deployCandidateSuffix = "-next"
+
+// Deploy ~ Replaces the container named desired.Name blue-green style. The new container starts next to the old one and must become
+// healthy (or, without a healthcheck, keep running) before the old one is stopped and purged and the new one takes over its name.
+// When desired publishes fixed host ports the old container still holds, it is stopped just before the new one starts instead.
+// If the new container never gets ready it is purged and the old one is left (or put back) running. Returns the ID of the new container
+func Deploy(ctx context.Context, desired *ContainerCreateConfig, opts DeployOptions) (string, error) {
+	name := desired.Name
+	if name == "" {
+		return "", errors.New("[ERR:] [DEPLOY] => CANNOT DEPLOY A CONTAINER WITHOUT A NAME")
+	}
+	if opts.HealthTimeout == 0 {
+		opts.HealthTimeout = time.Minute
+	}
+	if opts.HealthInterval == 0 {
+		opts.HealthInterval = time.Second
+	}
+	if desired.Config != nil && desired.Config.Image != "" {
+		if err := EnsureImage(ctx, desired.Config.Image); err != nil {
+			return "", err
+		}
+	}
+
+	old, err := DockerClient.ContainerInspect(ctx, name)
+	if err != nil && !client.IsErrNotFound(err) {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER " + name + " => " + err.Error())
+	}
+	hasOld := err == nil
+	oldRunning := hasOld && old.State != nil && old.State.Running
+
+	// A candidate left behind by an interrupted deploy would block the name
+	candidateName := name + deployCandidateSuffix
+	if stale, err := DockerClient.ContainerInspect(ctx, candidateName); err == nil {
+		if err := PurgeContainerContext(ctx, stale.ID); err != nil {
+			return "", err
+		}
+	}
+
+	candidate := *desired
+	candidate.Name = candidateName
+	if opts.Network != "" && opts.Alias != "" && desired.HostConfig != nil && string(desired.HostConfig.NetworkMode) == opts.Network {
+		candidate.NetworkingConfig = withEndpointAlias(desired.NetworkingConfig, opts.Network, opts.Alias)
+	}
+	created, err := CreateContainerContext(ctx, &candidate)
+	if err != nil {
+		return "", err
+	}
+
+	handover := oldRunning && publishesFixedPorts(desired)
+	if handover {
+		if err := StopContainerContext(ctx, old.ID); err != nil {
+			PurgeContainerContext(context.WithoutCancel(ctx), created.ID)
+			return "", err
+		}
+	}
+
+	if err := startCandidate(ctx, created.ID, desired, opts); err != nil {
+		return "", rollbackDeploy(ctx, created.ID, old.ID, handover, err)
+	}
+	if err := waitForReady(ctx, created.ID, opts.HealthTimeout, opts.HealthInterval); err != nil {
+		return "", rollbackDeploy(ctx, created.ID, old.ID, handover, err)
+	}
+
+	if hasOld {
+		if oldRunning && !handover {
+			if err := StopContainerContext(ctx, old.ID); err != nil {
+				return created.ID, err
+			}
+		}
+		if err := PurgeContainerContext(ctx, old.ID); err != nil {
+			return created.ID, err
+		}
+	}
+	if err := RenameContainer(ctx, created.ID, name); err != nil {
+		return created.ID, err
+	}
+	return created.ID, nil
+}
+
+// startCandidate starts the new container of a deploy and, if it is not created on opts.Network already, connects it there under opts.Alias
+func startCandidate(ctx context.Context, containerID string, desired *ContainerCreateConfig, opts DeployOptions) error {
+	if err := StartContainerContext(ctx, container.CreateResponse{ID: containerID}); err != nil {
+		return err
+	}
+	if opts.Network == "" || opts.Alias == "" {
+		return nil
+	}
+	if desired.HostConfig != nil && string(desired.HostConfig.NetworkMode) == opts.Network {
+		return nil
+	}
+	return ConnectNetwork(ctx, opts.Network, containerID, EndpointOptions{Aliases: []string{opts.Alias}})
+}
+
+// rollbackDeploy purges the new container of a failed deploy and restarts the old one if the deploy had stopped it. The rollback also
+// runs when ctx is done, e.g. after the readiness wait ran out of time
+func rollbackDeploy(ctx context.Context, candidateID string, oldID string, restartOld bool, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	message := "[ERR:] [DEPLOY] => NEW CONTAINER DID NOT GET READY, ROLLED BACK => " + cause.Error()
+	if err := PurgeContainerContext(ctx, candidateID); err != nil {
+		message += " | " + err.Error()
+	}
+	if restartOld {
+		if err := StartContainerContext(ctx, container.CreateResponse{ID: oldID}); err != nil {
+			message += " | " + err.Error()
+		}
+	}
+	return errors.New(message)
+}
+
+// waitForReady waits for a container to become healthy. A container without a healthcheck only has to be running
+func waitForReady(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	containerJSON, err := inspectContainer(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	if containerJSON.State != nil && containerJSON.State.Health == nil {
+		if !containerJSON.State.Running {
+			return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " IS NOT RUNNING => " + containerJSON.State.Status)
+		}
+		return nil
+	}
+	return WaitForHealthy(ctx, containerID, timeout, interval)
+}
+
+// publishesFixedPorts reports whether a config binds any explicit host port, which two containers cannot hold at once
+func publishesFixedPorts(config *ContainerCreateConfig) bool {
+	if config.HostConfig == nil {
+		return false
+	}
+	for _, bindings := range config.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			if binding.HostPort != "" && binding.HostPort != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withEndpointAlias returns a copy of networking with alias added to the endpoint of networkName
+func withEndpointAlias(networking *network.NetworkingConfig, networkName string, alias string) *network.NetworkingConfig {
+	endpoints := map[string]*network.EndpointSettings{}
+	if networking != nil {
+		for name, endpoint := range networking.EndpointsConfig {
+			endpoints[name] = endpoint
+		}
+	}
+	endpoint := network.EndpointSettings{}
+	if existing := endpoints[networkName]; existing != nil {
+		endpoint = *existing
+	}
+	endpoint.Aliases = append(append([]string{}, endpoint.Aliases...), alias)
+	endpoints[networkName] = &endpoint
+	return &network.NetworkingConfig{EndpointsConfig: endpoints}
+}
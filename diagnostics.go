@@ -0,0 +1,205 @@
+package containers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DebugBundleLogLines ~ How many of the most recent log lines of every stream CollectDebugBundle includes
+var DebugBundleLogLines = 1000
+
+// ContainerTop ~ Lists the processes running inside a container. psArgs are passed to ps, e.g. "aux" (empty uses the daemon default "-ef")
+func ContainerTop(ctx context.Context, containerID string, psArgs string) ([]ContainerProcess, error) {
+	top, err := DockerClient.ContainerTop(ctx, containerID, strings.Fields(psArgs))
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO LIST PROCESSES OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	processes := make([]ContainerProcess, 0, len(top.Processes))
+	for _, row := range top.Processes {
+		process := ContainerProcess{Fields: make(map[string]string, len(top.Titles))}
+		for i, title := range top.Titles {
+			if i >= len(row) {
+				break
+			}
+			value := row[i]
+			process.Fields[title] = value
+			switch strings.ToUpper(title) {
+			case "UID", "USER":
+				process.User = value
+			case "PID":
+				process.PID = value
+			case "PPID":
+				process.PPID = value
+			case "C", "%CPU":
+				process.CPU = value
+			case "%MEM":
+				process.Memory = value
+			case "STIME", "START":
+				process.Started = value
+			case "TTY":
+				process.TTY = value
+			case "TIME":
+				process.Time = value
+			case "CMD", "COMMAND":
+				process.Command = value
+			}
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// ContainerDiff ~ Lists the paths that were added, changed or deleted in a container's filesystem compared to its image
+func ContainerDiff(ctx context.Context, containerID string) (ContainerDiffResult, error) {
+	var result ContainerDiffResult
+	changes, err := DockerClient.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return result, errors.New("[ERR:] [DOCKER] => FAILED TO DIFF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	for _, change := range changes {
+		switch change.Kind {
+		case container.ChangeAdd:
+			result.Added = append(result.Added, change.Path)
+		case container.ChangeModify:
+			result.Changed = append(result.Changed, change.Path)
+		case container.ChangeDelete:
+			result.Deleted = append(result.Deleted, change.Path)
+		}
+	}
+	return result, nil
+}
+
+// CollectDebugBundle ~ Writes a gzipped tar archive to w with everything needed to look into a misbehaving container: inspect.json
+// with the values of the environment variables redacted, the most recent stdout.log and stderr.log lines, stats.json, top.json and
+// diff.json. Parts that cannot be collected, e.g. the stats and processes of a stopped container, are listed in errors.txt instead
+// of failing the bundle. Only a missing container is an error
+func CollectDebugBundle(ctx context.Context, containerID string, w io.Writer) error {
+	_, raw, err := DockerClient.ContainerInspectWithRaw(ctx, containerID, true)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	inspect, err := redactInspect(raw)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO REDACT INSPECT DATA OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	files := []debugFile{{name: "inspect.json", data: inspect}}
+	var failures []string
+	addJSON := func(name string, value interface{}, err error) {
+		if err != nil {
+			failures = append(failures, name+": "+err.Error())
+			return
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			failures = append(failures, name+": "+err.Error())
+			return
+		}
+		files = append(files, debugFile{name: name, data: data})
+	}
+
+	stdout, stderr, err := collectRecentLogs(ctx, containerID, DebugBundleLogLines)
+	if err != nil {
+		failures = append(failures, "logs: "+err.Error())
+	} else {
+		files = append(files, debugFile{name: "stdout.log", data: stdout}, debugFile{name: "stderr.log", data: stderr})
+	}
+	stats, err := GetContainerStats(ctx, containerID)
+	addJSON("stats.json", stats, err)
+	processes, err := ContainerTop(ctx, containerID, "")
+	addJSON("top.json", processes, err)
+	diff, err := ContainerDiff(ctx, containerID)
+	addJSON("diff.json", diff, err)
+	if len(failures) > 0 {
+		files = append(files, debugFile{name: "errors.txt", data: []byte(strings.Join(failures, "\n") + "\n")})
+	}
+
+	if err := writeDebugBundle(w, files); err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO WRITE DEBUG BUNDLE OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return nil
+}
+
+// redactInspect indents the raw inspect data of a container and replaces the values of its environment variables, which commonly
+// hold credentials, keeping the names
+func redactInspect(raw []byte) ([]byte, error) {
+	var inspect map[string]interface{}
+	if err := json.Unmarshal(raw, &inspect); err != nil {
+		return nil, err
+	}
+	if config, ok := inspect["Config"].(map[string]interface{}); ok {
+		if env, ok := config["Env"].([]interface{}); ok {
+			for i, variable := range env {
+				if s, ok := variable.(string); ok {
+					key, _, _ := strings.Cut(s, "=")
+					env[i] = key + "=REDACTED"
+				}
+			}
+		}
+	}
+	return json.MarshalIndent(inspect, "", "  ")
+}
+
+type debugFile struct {
+	name string
+	data []byte
+}
+
+// collectRecentLogs reads the last lines of both log streams of a container with their timestamps
+func collectRecentLogs(ctx context.Context, containerID string, lines int) ([]byte, []byte, error) {
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	logs, err := DockerClient.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer logs.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	// TTY containers do not multiplex their output, everything arrives on stdout
+	if containerJSON.Config != nil && containerJSON.Config.Tty {
+		_, err = io.Copy(&outBuf, logs)
+	} else {
+		_, err = stdcopy.StdCopy(&outBuf, &errBuf, logs)
+	}
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+func writeDebugBundle(w io.Writer, files []debugFile) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.data)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
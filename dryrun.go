@@ -0,0 +1,87 @@
+package containers
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	dryRunMu      sync.RWMutex
+	dryRunEnabled bool
+	dryRunLog     func(op Operation)
+)
+
+// EnableDryRun ~ Makes every mutating operation (creates, starts, stops, removals, builds, pulls, tags, execs, copies into containers,
+// network connections, volume backups and restores and prunes) report what it would do to logFn and return a synthetic result instead of calling the daemon. Created objects get IDs of the form "dry-run-<name>".
+// Reads still reach the daemon. A nil logFn writes to the standard logger
+func EnableDryRun(logFn func(op Operation)) {
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	dryRunEnabled = true
+	dryRunLog = logFn
+}
+
+// DisableDryRun ~ Lets mutating operations reach the daemon again
+func DisableDryRun() {
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	dryRunEnabled = false
+	dryRunLog = nil
+}
+
+// IsDryRun ~ Reports whether dry-run mode is on
+func IsDryRun() bool {
+	dryRunMu.RLock()
+	defer dryRunMu.RUnlock()
+	return dryRunEnabled
+}
+
+// dryRun reports whether an operation has to be skipped, logging it if so. params are key, value pairs as for beginOperation
+func dryRun(name string, target string, image string, params ...string) bool {
+	dryRunMu.RLock()
+	enabled, logFn := dryRunEnabled, dryRunLog
+	dryRunMu.RUnlock()
+	if !enabled {
+		return false
+	}
+
+	op := Operation{Name: name, Target: target, Image: image}
+	if len(params) > 0 {
+		op.Parameters = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			op.Parameters[params[i]] = params[i+1]
+		}
+	}
+	if logFn != nil {
+		logFn(op)
+		return true
+	}
+
+	line := "[DRY-RUN] => " + op.Name
+	if op.Target != "" {
+		line += " " + op.Target
+	}
+	if op.Image != "" && op.Image != op.Target {
+		line += " IMAGE " + op.Image
+	}
+	keys := make([]string, 0, len(op.Parameters))
+	for key := range op.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		line += " " + key + "=" + strings.TrimSpace(op.Parameters[key])
+	}
+	log.Println(line)
+	return true
+}
+
+// dryRunID returns the synthetic ID of an object a dry run pretends to create
+func dryRunID(name string) string {
+	if name == "" {
+		return "dry-run"
+	}
+	return "dry-run-" + name
+}
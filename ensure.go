@@ -0,0 +1,157 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// EnsureContainer ~ Returns the ID of the container named config.Name and whether it had to be created, creating it only if it does not exist yet.
+// With verify set, an existing container that does not match the config is reported as an error instead of reused
+func EnsureContainer(ctx context.Context, config *ContainerCreateConfig, verify bool) (string, bool, error) {
+	if config.Name == "" {
+		return "", false, errors.New("[ERR:] [DOCKER] => CANNOT ENSURE A CONTAINER WITHOUT A NAME")
+	}
+
+	existing, err := DockerClient.ContainerInspect(ctx, config.Name)
+	if err != nil && !client.IsErrNotFound(err) {
+		return "", false, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER " + config.Name + " => " + err.Error())
+	}
+	if err == nil {
+		if verify {
+			if changes := diffContainerConfig(existing, config); len(changes) > 0 {
+				return existing.ID, false, errors.New("[ERR:] [DOCKER] => CONTAINER " + config.Name + " EXISTS WITH A DIFFERENT CONFIG => " + strings.Join(changes, ", "))
+			}
+		}
+		return existing.ID, false, nil
+	}
+
+	containerRes, err := CreateContainerContext(ctx, config)
+	if err != nil {
+		return "", false, err
+	}
+	return containerRes.ID, true, nil
+}
+
+// RecreateContainer ~ Compares the container named desired.Name with the desired config and, if they differ, stops, purges, creates and starts it again.
+// A missing container is created and started. Returns the ID of the container and a description of every difference found
+func RecreateContainer(ctx context.Context, desired *ContainerCreateConfig) (string, []string, error) {
+	if desired.Name == "" {
+		return "", nil, errors.New("[ERR:] [DOCKER] => CANNOT RECREATE A CONTAINER WITHOUT A NAME")
+	}
+
+	existing, err := DockerClient.ContainerInspect(ctx, desired.Name)
+	if err != nil && !client.IsErrNotFound(err) {
+		return "", nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER " + desired.Name + " => " + err.Error())
+	}
+
+	var changes []string
+	if err == nil {
+		changes = diffContainerConfig(existing, desired)
+		if len(changes) == 0 {
+			return existing.ID, nil, nil
+		}
+		if existing.State != nil && existing.State.Running {
+			if err := StopContainerContext(ctx, existing.ID); err != nil {
+				return existing.ID, changes, err
+			}
+		}
+		if err := PurgeContainerContext(ctx, existing.ID); err != nil {
+			return existing.ID, changes, err
+		}
+	} else {
+		changes = []string{"container does not exist"}
+	}
+
+	containerRes, err := CreateContainerContext(ctx, desired)
+	if err != nil {
+		return "", changes, err
+	}
+	if err := StartContainerContext(ctx, containerRes); err != nil {
+		return containerRes.ID, changes, err
+	}
+	return containerRes.ID, changes, nil
+}
+
+// diffContainerConfig lists the settings of desired that an existing container does not match.
+// Only what desired sets is compared, so defaults filled in by the daemon or the image are not reported
+func diffContainerConfig(existing types.ContainerJSON, desired *ContainerCreateConfig) []string {
+	var changes []string
+
+	if desired.Config != nil && existing.Config != nil {
+		want, have := desired.Config, existing.Config
+		if want.Image != "" && want.Image != have.Image {
+			changes = append(changes, "image: "+have.Image+" -> "+want.Image)
+		}
+		if len(want.Cmd) > 0 && !slices.Equal(want.Cmd, have.Cmd) {
+			changes = append(changes, "cmd: "+strings.Join(have.Cmd, " ")+" -> "+strings.Join(want.Cmd, " "))
+		}
+		if len(want.Entrypoint) > 0 && !slices.Equal(want.Entrypoint, have.Entrypoint) {
+			changes = append(changes, "entrypoint: "+strings.Join(have.Entrypoint, " ")+" -> "+strings.Join(want.Entrypoint, " "))
+		}
+		for _, env := range want.Env {
+			if !slices.Contains(have.Env, env) {
+				changes = append(changes, "env: missing "+env)
+			}
+		}
+		for key, value := range want.Labels {
+			if have.Labels[key] != value {
+				changes = append(changes, "label "+key+": "+have.Labels[key]+" -> "+value)
+			}
+		}
+		for port := range want.ExposedPorts {
+			if _, ok := have.ExposedPorts[port]; !ok {
+				changes = append(changes, "exposed port: missing "+string(port))
+			}
+		}
+	}
+
+	if desired.HostConfig != nil && existing.HostConfig != nil {
+		want, have := desired.HostConfig, existing.HostConfig
+		for port, bindings := range want.PortBindings {
+			if !reflect.DeepEqual(bindings, have.PortBindings[port]) {
+				changes = append(changes, "port bindings of "+string(port)+" changed")
+			}
+		}
+		for _, bind := range want.Binds {
+			if !slices.Contains(have.Binds, bind) {
+				changes = append(changes, "bind: missing "+bind)
+			}
+		}
+		for _, mount := range want.Mounts {
+			found := false
+			for _, haveMount := range have.Mounts {
+				if haveMount.Target == mount.Target && haveMount.Source == mount.Source && haveMount.Type == mount.Type {
+					found = true
+					break
+				}
+			}
+			if !found {
+				changes = append(changes, "mount: missing "+mount.Target)
+			}
+		}
+		if want.RestartPolicy.Name != "" && want.RestartPolicy != have.RestartPolicy {
+			changes = append(changes, "restart policy: "+string(have.RestartPolicy.Name)+" -> "+string(want.RestartPolicy.Name))
+		}
+		if want.NetworkMode != "" && want.NetworkMode != have.NetworkMode {
+			changes = append(changes, "network mode: "+string(have.NetworkMode)+" -> "+string(want.NetworkMode))
+		}
+		if want.Memory != 0 && want.Memory != have.Memory {
+			changes = append(changes, "memory: "+fmt.Sprint(have.Memory)+" -> "+fmt.Sprint(want.Memory))
+		}
+		if want.CPUQuota != 0 && (want.CPUQuota != have.CPUQuota || want.CPUPeriod != have.CPUPeriod) {
+			changes = append(changes, "cpu quota: "+fmt.Sprint(have.CPUQuota)+"/"+fmt.Sprint(have.CPUPeriod)+" -> "+fmt.Sprint(want.CPUQuota)+"/"+fmt.Sprint(want.CPUPeriod))
+		}
+		if want.NanoCPUs != 0 && want.NanoCPUs != have.NanoCPUs {
+			changes = append(changes, "cpus: "+fmt.Sprint(have.NanoCPUs)+" -> "+fmt.Sprint(want.NanoCPUs))
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,152 @@
+package containers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LoadEnvFile ~ Parses a .env file the way docker compose does: blank lines and # comments are skipped, an optional "export " prefix is
+// allowed, single quoted values are literal, double quoted values support \n escapes and unquoted values end at " #".
+// ${VAR}, ${VAR:-default} and $VAR are expanded from earlier entries of the file, then from the process environment
+func LoadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("[ERR:] [ENV] => FAILED TO OPEN ENV FILE " + path + " => " + err.Error())
+	}
+	defer file.Close()
+
+	env := map[string]string{}
+	lookup := func(key string) (string, bool) {
+		if value, ok := env[key]; ok {
+			return value, true
+		}
+		return os.LookupEnv(key)
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rawValue, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, errors.New("[ERR:] [ENV] => INVALID LINE " + fmt.Sprint(lineNumber) + " IN ENV FILE " + path)
+		}
+		// A bare KEY is passed through from the process environment, like compose does
+		if !found {
+			if value, ok := os.LookupEnv(key); ok {
+				env[key] = value
+			}
+			continue
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(rawValue), lookup)
+		if err != nil {
+			return nil, errors.New("[ERR:] [ENV] => INVALID VALUE ON LINE " + fmt.Sprint(lineNumber) + " IN ENV FILE " + path + " => " + err.Error())
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("[ERR:] [ENV] => FAILED TO READ ENV FILE " + path + " => " + err.Error())
+	}
+	return env, nil
+}
+
+// EnvMapToSlice ~ Converts an environment map to the sorted KEY=VALUE slice container configs expect
+func EnvMapToSlice(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	envSlice := make([]string, 0, len(keys))
+	for _, key := range keys {
+		envSlice = append(envSlice, key+"="+env[key])
+	}
+	return envSlice
+}
+
+// EnvSliceToMap ~ Converts a KEY=VALUE slice to a map. Later entries win
+func EnvSliceToMap(env []string) map[string]string {
+	envMap := make(map[string]string, len(env))
+	for _, e := range env {
+		key, value, _ := strings.Cut(e, "=")
+		envMap[key] = value
+	}
+	return envMap
+}
+
+// ExpandEnv ~ Expands ${VAR}, ${VAR:-default}, ${VAR-default} and $VAR in s using lookup
+func ExpandEnv(s string, lookup func(string) (string, bool)) string {
+	return os.Expand(s, func(name string) string {
+		if key, fallback, found := strings.Cut(name, ":-"); found {
+			if value, ok := lookup(key); ok && value != "" {
+				return value
+			}
+			return fallback
+		}
+		if key, fallback, found := strings.Cut(name, "-"); found {
+			if value, ok := lookup(key); ok {
+				return value
+			}
+			return fallback
+		}
+		value, _ := lookup(name)
+		return value
+	})
+}
+
+// parseEnvValue unquotes and expands the value of an env file entry
+func parseEnvValue(raw string, lookup func(string) (string, bool)) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errors.New("UNTERMINATED SINGLE QUOTE")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var value strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			if c == '"' {
+				return ExpandEnv(value.String(), lookup), nil
+			}
+			if c == '\\' && i+1 < len(raw) {
+				i++
+				switch raw[i] {
+				case 'n':
+					value.WriteByte('\n')
+				case 't':
+					value.WriteByte('\t')
+				case 'r':
+					value.WriteByte('\r')
+				default:
+					value.WriteByte(raw[i])
+				}
+				continue
+			}
+			value.WriteByte(c)
+		}
+		return "", errors.New("UNTERMINATED DOUBLE QUOTE")
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		return ExpandEnv(raw, lookup), nil
+	}
+}
@@ -0,0 +1,67 @@
+package containers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"NAME": "web", "EMPTY": ""}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"$NAME", "web"},
+		{"${NAME}-1", "web-1"},
+		{"${MISSING}", ""},
+		{"${MISSING:-fallback}", "fallback"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${EMPTY-fallback}", ""},
+		{"${MISSING-fallback}", "fallback"},
+		{"${NAME:-fallback}", "web"},
+	}
+	for _, tt := range tests {
+		if got := ExpandEnv(tt.in, lookupIn(env)); got != tt.want {
+			t.Errorf("ExpandEnv(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# comment
+
+export HOST=db
+PORT=5432 # trailing comment
+URL=postgres://${HOST}:${PORT}
+LITERAL='${HOST} stays'
+QUOTED="line\nbreak"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"HOST":    "db",
+		"PORT":    "5432",
+		"URL":     "postgres://db:5432",
+		"LITERAL": "${HOST} stays",
+		"QUOTED":  "line\nbreak",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
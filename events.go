@@ -0,0 +1,90 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// SubscribeEvents ~ Delivers daemon events matching eventFilters (e.g. type=container, event=die, label=...) to fn until the context is cancelled.
+// Lost connections to the daemon are re-established with backoff, resuming after the last delivered event
+func SubscribeEvents(ctx context.Context, eventFilters filters.Args, fn func(Event)) error {
+	since := ""
+	backoff := time.Second
+	for {
+		messages, errs := DockerClient.Events(ctx, events.ListOptions{
+			Since:   since,
+			Filters: eventFilters,
+		})
+
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case message := <-messages:
+				backoff = time.Second
+				event := toEvent(message)
+				// Resume right after this event when reconnecting
+				next := event.Time.Add(time.Nanosecond)
+				since = fmt.Sprintf("%d.%09d", next.Unix(), next.Nanosecond())
+				fn(event)
+			case <-errs:
+				break receive
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// EventsChannel ~ Like SubscribeEvents but delivers the events on a channel, which is closed once the context is cancelled
+func EventsChannel(ctx context.Context, eventFilters filters.Args) <-chan Event {
+	eventCh := make(chan Event)
+	go func() {
+		defer close(eventCh)
+		_ = SubscribeEvents(ctx, eventFilters, func(event Event) {
+			select {
+			case eventCh <- event:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return eventCh
+}
+
+func toEvent(message events.Message) Event {
+	event := Event{
+		Type:       string(message.Type),
+		Action:     string(message.Action),
+		ActorID:    message.Actor.ID,
+		Name:       message.Actor.Attributes["name"],
+		Image:      message.Actor.Attributes["image"],
+		Attributes: message.Actor.Attributes,
+		Time:       time.Unix(0, message.TimeNano),
+	}
+	if message.TimeNano == 0 {
+		event.Time = time.Unix(message.Time, 0)
+	}
+	// Health events carry the status in the action, e.g. "health_status: healthy"
+	if status, found := strings.CutPrefix(event.Action, string(events.ActionHealthStatus)+": "); found {
+		event.Action = string(events.ActionHealthStatus)
+		event.HealthStatus = status
+	}
+	// Exec events carry the command in the action, e.g. "exec_start: sh -c ..."
+	if action, _, found := strings.Cut(event.Action, ": "); found {
+		event.Action = action
+	}
+	return event
+}
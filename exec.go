@@ -0,0 +1,373 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Exec executes a command on a running container
+func Exec(containerID string, cmd []string) (string, error) {
+	return ExecWithOptions(containerID, cmd, ExecOptions{})
+}
+
+// ExecWithStdin ~ Executes a command on a running container feeding stdin to it. The input stream is closed once stdin is exhausted
+func ExecWithStdin(containerID string, cmd []string, stdin io.Reader) (string, error) {
+	return ExecWithOptions(containerID, cmd, ExecOptions{Stdin: stdin})
+}
+
+// ExecWithOptions ~ Executes a command on a running container as a specific user, in a working directory, with extra environment variables or privileged
+func ExecWithOptions(containerID string, cmd []string, opts ExecOptions) (string, error) {
+	return ExecContext(context.Background(), containerID, cmd, opts)
+}
+
+// execKillGrace ~ How long a cancelled command has to exit after SIGTERM before it gets SIGKILL
+const execKillGrace = 10 * time.Second
+
+// ExecContext ~ Executes a command on a running container, killing it when the context is done: the command gets SIGTERM and, if it
+// still runs after 10 seconds, SIGKILL. The daemon has no API to kill an exec, so the signals are sent by kill inside the container,
+// which needs /bin/sh there. In a container without one the command is only detached from
+func ExecContext(ctx context.Context, containerID string, cmd []string, opts ExecOptions) (string, error) {
+	result, err := ExecWithResult(ctx, containerID, cmd, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if result.ExitCode != 0 {
+		return "", errors.New("[ERR:] [DOCKER] => COMMAND EXITIED WITH CODE " + fmt.Sprint(result.ExitCode) + " => " + result.Stderr)
+	}
+
+	return result.Stdout, nil
+}
+
+// ExecWithResult ~ Executes a command on a running container and returns its exit code, stdout, stderr and duration.
+// A non-zero exit code is not an error, only failures to run the command are
+func ExecWithResult(ctx context.Context, containerID string, cmd []string, opts ExecOptions) (ExecResult, error) {
+	var outBuf, errBuf bytes.Buffer
+
+	started := time.Now()
+	exitCode, err := execAndCopy(ctx, containerID, cmd, opts, &outBuf, &errBuf)
+	if err != nil {
+		return ExecResult{ExitCode: exitCode}, err
+	}
+
+	return ExecResult{
+		ExitCode: exitCode,
+		Stdout:   outBuf.String(),
+		Stderr:   errBuf.String(),
+		Duration: time.Since(started),
+	}, nil
+}
+
+// ExecStream ~ Executes a command on a running container copying its output to stdout and stderr live as it runs. Returns the exit code of the command
+func ExecStream(ctx context.Context, containerID string, cmd []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	return execAndCopy(ctx, containerID, cmd, ExecOptions{}, stdout, stderr)
+}
+
+// execAndCopy runs a command on a running container, demultiplexes its output into stdout and stderr and returns its exit code
+func execAndCopy(ctx context.Context, containerID string, cmd []string, opts ExecOptions, stdout io.Writer, stderr io.Writer) (int, error) {
+	if dryRun(OpExec, containerID, "", "cmd", strings.Join(cmd, " "), "user", opts.User) {
+		return 0, nil
+	}
+	ctx, done := beginOperation(ctx, OpExec, containerID, "", "cmd", strings.Join(cmd, " "), "user", opts.User)
+	exitCode, err := runExec(ctx, containerID, cmd, opts, stdout, stderr)
+	done(err)
+	return exitCode, err
+}
+
+// runExec does the work of execAndCopy
+func runExec(ctx context.Context, containerID string, cmd []string, opts ExecOptions, stdout io.Writer, stderr io.Writer) (int, error) {
+	cmd, wrapped := execWrapper(ctx, containerID, cmd)
+	execConfig := container.ExecOptions{
+		Cmd:          cmd,
+		User:         opts.User,
+		WorkingDir:   opts.WorkingDir,
+		Env:          opts.Env,
+		Privileged:   opts.Privileged,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execIDResp, err := DockerClient.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO CREATE EXEC ISNTANCE => " + err.Error())
+	}
+
+	// Attach to the exec instance
+	resp, err := DockerClient.ContainerExecAttach(ctx, execIDResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO ATTACH TO EXEC INSTANCE => " + err.Error())
+	}
+
+	defer resp.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			resp.Close()
+		}
+	}()
+
+	// Feed stdin and half-close the connection so the command sees EOF
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, opts.Stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	// Copy the output of the command to the writers
+	pids := &pidWriter{w: stdout}
+	if wrapped {
+		stdout = pids
+	}
+	_, err = stdcopy.StdCopy(stdout, stderr, resp.Reader)
+	if ctx.Err() != nil {
+		message := "[ERR:] [DOCKER] => EXEC CANCELLED => " + ctx.Err().Error()
+		if wrapped {
+			if killErr := killExec(ctx, containerID, execIDResp.ID, pids.pid(), opts.User); killErr != nil {
+				message += " | FAILED TO KILL THE COMMAND => " + killErr.Error()
+			}
+		}
+		return -1, errors.New(message)
+	}
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO COPY EXEC OUTPUT => " + err.Error())
+	}
+
+	// Inspect exec instance to get the exit code
+	execInspectResp, err := DockerClient.ContainerExecInspect(ctx, execIDResp.ID)
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT EXEC INSTANCE => " + err.Error())
+	}
+
+	return execInspectResp.ExitCode, nil
+}
+
+// ExecInteractive ~ Runs a command on a running container with a TTY attached, proxying raw I/O between the streams and the command,
+// like `docker exec -it`. Putting the local terminal in raw mode is left to the caller. Returns the exit code of the command.
+// Cancelling the context kills the command like ExecContext does
+func ExecInteractive(ctx context.Context, containerID string, cmd []string, stdin io.Reader, stdout io.Writer, opts TerminalOptions) (int, error) {
+	if dryRun(OpExec, containerID, "", "cmd", strings.Join(cmd, " "), "tty", "true") {
+		return 0, nil
+	}
+	ctx, done := beginOperation(ctx, OpExec, containerID, "", "cmd", strings.Join(cmd, " "), "tty", "true")
+	exitCode, err := runExecInteractive(ctx, containerID, cmd, stdin, stdout, opts)
+	done(err)
+	return exitCode, err
+}
+
+// runExecInteractive does the work of ExecInteractive
+func runExecInteractive(ctx context.Context, containerID string, cmd []string, stdin io.Reader, stdout io.Writer, opts TerminalOptions) (int, error) {
+	var consoleSize *[2]uint
+	if opts.Height > 0 && opts.Width > 0 {
+		consoleSize = &[2]uint{opts.Height, opts.Width}
+	}
+
+	cmd, wrapped := execWrapper(ctx, containerID, cmd)
+	execConfig := container.ExecOptions{
+		Cmd:          cmd,
+		Tty:          true,
+		ConsoleSize:  consoleSize,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execIDResp, err := DockerClient.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO CREATE EXEC ISNTANCE => " + err.Error())
+	}
+
+	resp, err := DockerClient.ContainerExecAttach(ctx, execIDResp.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: consoleSize,
+	})
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO ATTACH TO EXEC INSTANCE => " + err.Error())
+	}
+	defer resp.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// Forward terminal resizes until the session ends
+	if opts.Resize != nil {
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case size, ok := <-opts.Resize:
+					if !ok {
+						return
+					}
+					_ = DockerClient.ContainerExecResize(ctx, execIDResp.ID, container.ResizeOptions{
+						Height: size.Height,
+						Width:  size.Width,
+					})
+				}
+			}
+		}()
+	}
+
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	// A TTY merges stdout and stderr into one raw stream
+	pids := &pidWriter{w: stdout}
+	if wrapped {
+		stdout = pids
+	}
+	outputDone := make(chan error, 1)
+	go func() {
+		_, copyErr := io.Copy(stdout, resp.Reader)
+		outputDone <- copyErr
+	}()
+
+	select {
+	case <-ctx.Done():
+		message := "[ERR:] [DOCKER] => EXEC SESSION CANCELLED => " + ctx.Err().Error()
+		if wrapped {
+			if killErr := killExec(ctx, containerID, execIDResp.ID, pids.pid(), ""); killErr != nil {
+				message += " | FAILED TO KILL THE COMMAND => " + killErr.Error()
+			}
+		}
+		return -1, errors.New(message)
+	case copyErr := <-outputDone:
+		if copyErr != nil {
+			return -1, errors.New("[ERR:] [DOCKER] => FAILED TO COPY EXEC OUTPUT => " + copyErr.Error())
+		}
+	}
+
+	execInspectResp, err := DockerClient.ContainerExecInspect(ctx, execIDResp.ID)
+	if err != nil {
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT EXEC INSTANCE => " + err.Error())
+	}
+	return execInspectResp.ExitCode, nil
+}
+
+// execWrapper runs cmd through /bin/sh, which prints its PID on the first line of stdout and then replaces itself with cmd, so the
+// command can be signalled inside the container when ctx is done. Commands of contexts that cannot be cancelled and of containers
+// without /bin/sh are left as they are
+func execWrapper(ctx context.Context, containerID string, cmd []string) ([]string, bool) {
+	if ctx.Done() == nil || len(cmd) == 0 {
+		return cmd, false
+	}
+	if _, err := DockerClient.ContainerStatPath(ctx, containerID, "/bin/sh"); err != nil {
+		return cmd, false
+	}
+	return append([]string{"/bin/sh", "-c", `echo $$; exec "$@"`, "sh"}, cmd...), true
+}
+
+// pidWriter takes the PID execWrapper prints off the output of a command and passes everything after it on to w
+type pidWriter struct {
+	w     io.Writer
+	mu    sync.Mutex
+	line  []byte
+	found bool
+}
+
+func (p *pidWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	if p.found {
+		p.mu.Unlock()
+		return p.w.Write(b)
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		p.line = append(p.line, b...)
+		p.mu.Unlock()
+		return len(b), nil
+	}
+	p.line = append(p.line, b[:i]...)
+	p.found = true
+	p.mu.Unlock()
+
+	if _, err := p.w.Write(b[i+1:]); err != nil {
+		return i + 1, err
+	}
+	return len(b), nil
+}
+
+// pid returns the PID of the command inside the container, empty until it was printed
+func (p *pidWriter) pid() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.found {
+		return ""
+	}
+	// A TTY ends the line with \r\n
+	return strings.TrimSpace(string(p.line))
+}
+
+// killExec sends SIGTERM to a command started through execWrapper and SIGKILL if it still runs after execKillGrace. The signals are
+// sent by kill in a second exec as user, since the daemon cannot signal an exec. It runs after ctx is done, so it ignores the cancellation
+func killExec(ctx context.Context, containerID string, execID string, pid string, user string) error {
+	if _, err := strconv.Atoi(pid); err != nil {
+		return errors.New("THE COMMAND DID NOT REPORT ITS PID")
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*execKillGrace)
+	defer cancel()
+
+	exited, err := waitExecExit(ctx, execID, 0)
+	for _, signal := range []string{"TERM", "KILL"} {
+		if err != nil || exited {
+			return err
+		}
+		kill, createErr := DockerClient.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+			Cmd:  []string{"/bin/sh", "-c", "kill -" + signal + " " + pid},
+			User: user,
+		})
+		if createErr != nil {
+			return errors.New("FAILED TO CREATE KILL EXEC => " + createErr.Error())
+		}
+		if startErr := DockerClient.ContainerExecStart(ctx, kill.ID, container.ExecStartOptions{Detach: true}); startErr != nil {
+			return errors.New("FAILED TO START KILL EXEC => " + startErr.Error())
+		}
+		exited, err = waitExecExit(ctx, execID, execKillGrace)
+	}
+	if err == nil && !exited {
+		err = errors.New("THE COMMAND STILL RUNS AFTER SIGKILL")
+	}
+	return err
+}
+
+// waitExecExit polls an exec until it no longer runs or timeout passes. Reports whether it exited
+func waitExecExit(ctx context.Context, execID string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		inspect, err := DockerClient.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return false, errors.New("FAILED TO INSPECT EXEC INSTANCE => " + err.Error())
+		}
+		if !inspect.Running {
+			return true, nil
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
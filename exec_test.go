@@ -0,0 +1,39 @@
+package containers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPidWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		pid    string
+		out    string
+	}{
+		{"one write", []string{"42\nhello\n"}, "42", "hello\n"},
+		{"split pid", []string{"4", "2", "\nhello"}, "42", "hello"},
+		{"pid only", []string{"42\n"}, "42", ""},
+		{"tty line end", []string{"42\r\n", "hello\r\n"}, "42", "hello\r\n"},
+		{"no newline yet", []string{"42"}, "", ""},
+		{"later newlines untouched", []string{"7\n", "a\nb\n"}, "7", "a\nb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := &pidWriter{w: &out}
+			for _, chunk := range tt.chunks {
+				if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+				}
+			}
+			if got := w.pid(); got != tt.pid {
+				t.Errorf("pid = %q, want %q", got, tt.pid)
+			}
+			if out.String() != tt.out {
+				t.Errorf("output = %q, want %q", out.String(), tt.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,1088 @@
+// Package fake provides an in-memory containers.ContainerManager that keeps track of images, containers, networks and volumes
+// with the state transitions of a real daemon, so reconciliation style code can be tested without one
+package fake
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// The states a fake container moves through, named like the daemon names them
+const (
+	StateCreated = "created"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateExited  = "exited"
+)
+
+// Container ~ A snapshot of a fake container
+type Container struct {
+	ID         string
+	Name       string
+	Image      string
+	State      string
+	ExitCode   int64
+	Health     string
+	Config     containers.ContainerCreateConfig
+	Networks   []string
+	Ports      map[string]string
+	Logs       []containers.LogLine
+	Files      map[string][]byte
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Backend ~ An in-memory daemon. Containers go from created to running on start, from running to paused and back, and to exited on
+// stop, kill or Exit. Images must exist before a container is created from them, EnsureImage "pulls" any image unless PullError
+// refuses it. The bridge, host and none networks exist from the start
+type Backend struct {
+	// ExecHandler answers exec calls on running containers. A nil handler answers every command with exit code 0 and no output
+	ExecHandler func(containerID string, cmd []string) containers.ExecResult
+	// PullError decides whether a pull of an image fails. A nil func lets every pull succeed
+	PullError func(imageName string) error
+	// StartHealth is the health a container with a healthcheck gets when it starts. It defaults to healthy
+	StartHealth string
+
+	mu         sync.Mutex
+	changed    chan struct{}
+	seq        int
+	nextPort   int
+	images     map[string]string
+	containers map[string]*Container
+	networks   map[string]*network
+	volumes    map[string]*volume.Volume
+}
+
+type network struct {
+	id        string
+	name      string
+	opts      containers.NetworkOptions
+	endpoints map[string]containers.EndpointOptions
+}
+
+var _ containers.ContainerManager = (*Backend)(nil)
+
+// NewBackend ~ Creates an empty backend with the default networks
+func NewBackend() *Backend {
+	b := &Backend{
+		changed:    make(chan struct{}),
+		nextPort:   32768,
+		images:     map[string]string{},
+		containers: map[string]*Container{},
+		networks:   map[string]*network{},
+		volumes:    map[string]*volume.Volume{},
+	}
+	for _, name := range []string{"bridge", "host", "none"} {
+		b.addNetwork(name, containers.NetworkOptions{Driver: name})
+	}
+	return b
+}
+
+// AddImage ~ Makes an image present, as if it had been pulled or built
+func (b *Backend) AddImage(imageName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addImage(imageName)
+}
+
+// Images ~ Returns the names of the present images, sorted
+func (b *Backend) Images() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	names := make([]string, 0, len(b.images))
+	for name := range b.images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Container ~ Returns a snapshot of a container by ID, ID prefix or name
+func (b *Backend) Container(ref string) (Container, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(ref)
+	if c == nil {
+		return Container{}, false
+	}
+	return snapshot(c), true
+}
+
+// Containers ~ Returns snapshots of every container, sorted by name
+func (b *Backend) Containers() []Container {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := make([]Container, 0, len(b.containers))
+	for _, c := range b.containers {
+		list = append(list, snapshot(c))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Exit ~ Makes the process of a running container exit with a code, as if it had crashed or finished
+func (b *Backend) Exit(ref string, exitCode int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(ref)
+	if c == nil {
+		return noSuchContainer(ref)
+	}
+	if c.State != StateRunning && c.State != StatePaused {
+		return errors.New("container " + c.ID + " is not running")
+	}
+	b.stop(c, exitCode)
+	return nil
+}
+
+// SetHealth ~ Sets the health status of a container, e.g. to simulate a failing healthcheck
+func (b *Backend) SetHealth(ref string, status string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(ref)
+	if c == nil {
+		return noSuchContainer(ref)
+	}
+	c.Health = status
+	b.notify()
+	return nil
+}
+
+// WriteLog ~ Appends a line to the logs of a container on the given stream (containers.LogStreamStdout or containers.LogStreamStderr)
+func (b *Backend) WriteLog(ref string, stream string, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(ref)
+	if c == nil {
+		return noSuchContainer(ref)
+	}
+	c.Logs = append(c.Logs, containers.LogLine{Timestamp: time.Now(), Stream: stream, Message: message})
+	b.notify()
+	return nil
+}
+
+func (b *Backend) BuildImage(path string, imageName string) error {
+	b.AddImage(imageName)
+	return nil
+}
+
+func (b *Backend) EnsureImage(ctx context.Context, imageName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.images[normalizeImage(imageName)]; ok {
+		return nil
+	}
+	if b.PullError != nil {
+		if err := b.PullError(imageName); err != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO PULL IMAGE " + imageName + " => " + err.Error())
+		}
+	}
+	b.addImage(imageName)
+	return nil
+}
+
+func (b *Backend) DeleteImage(imageName string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name := normalizeImage(imageName)
+	if _, ok := b.images[name]; !ok {
+		return false, nil
+	}
+	// The real delete is forced, so images of existing containers go as well
+	delete(b.images, name)
+	return true, nil
+}
+
+func (b *Backend) PruneDanglingImages() (image.PruneReport, error) {
+	return image.PruneReport{}, nil
+}
+
+func (b *Backend) CreateContainer(config *containers.ContainerCreateConfig) (container.CreateResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	imageName := ""
+	if config.Config != nil {
+		imageName = config.Config.Image
+	}
+	fail := func(reason string) (container.CreateResponse, error) {
+		return container.CreateResponse{}, errors.New("[ERR:] [DOCKER] => FAILED TO CREATE CONTAINER " + config.Name + " => " + reason)
+	}
+	if _, ok := b.images[normalizeImage(imageName)]; !ok {
+		return fail("No such image: " + imageName)
+	}
+
+	name := config.Name
+	if name == "" {
+		name = "container-" + strconv.Itoa(b.seq+1)
+	}
+	if existing := b.byName(name); existing != nil {
+		return fail(`Conflict. The container name "/` + name + `" is already in use by container "` + existing.ID + `"`)
+	}
+
+	var networks []string
+	networkMode := ""
+	if config.HostConfig != nil {
+		networkMode = string(config.HostConfig.NetworkMode)
+	}
+	if networkMode == "" || networkMode == "default" {
+		networkMode = "bridge"
+	}
+	networks = append(networks, networkMode)
+	if config.NetworkingConfig != nil {
+		for networkName := range config.NetworkingConfig.EndpointsConfig {
+			if networkName != networkMode {
+				networks = append(networks, networkName)
+			}
+		}
+	}
+	for _, networkName := range networks {
+		if b.findNetwork(networkName) == nil {
+			return fail("network " + networkName + " not found")
+		}
+	}
+
+	c := &Container{
+		ID:       b.newID("container"),
+		Name:     name,
+		Image:    imageName,
+		State:    StateCreated,
+		Config:   *config,
+		Ports:    map[string]string{},
+		Files:    map[string][]byte{},
+		Networks: networks,
+	}
+	c.Config.Name = name
+	for _, networkName := range networks {
+		opts := containers.EndpointOptions{}
+		if config.NetworkingConfig != nil {
+			if endpoint := config.NetworkingConfig.EndpointsConfig[networkName]; endpoint != nil {
+				opts.Aliases = endpoint.Aliases
+			}
+		}
+		b.findNetwork(networkName).endpoints[c.ID] = opts
+	}
+	for _, volumeName := range namedVolumes(config) {
+		if _, ok := b.volumes[volumeName]; !ok {
+			b.volumes[volumeName] = &volume.Volume{Name: volumeName, Driver: "local", CreatedAt: time.Now().Format(time.RFC3339)}
+		}
+	}
+	b.containers[c.ID] = c
+	b.notify()
+	return container.CreateResponse{ID: c.ID}, nil
+}
+
+func (b *Backend) StartContainer(cont container.CreateResponse) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(cont.ID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO START CONTAINER WITH ID: " + cont.ID + " => " + noSuchContainer(cont.ID).Error())
+	}
+	if c.State == StatePaused {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO START CONTAINER WITH ID: " + cont.ID + " => cannot start a paused container, try unpause instead")
+	}
+	if c.State != StateRunning {
+		b.start(c)
+	}
+	return nil
+}
+
+func (b *Backend) StopContainer(containerID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO STOP CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	if c.State == StateRunning || c.State == StatePaused {
+		// The process is assumed to handle SIGTERM and exit cleanly
+		b.stop(c, 0)
+	}
+	return nil
+}
+
+func (b *Backend) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO RESTART CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	if c.State == StateRunning || c.State == StatePaused {
+		b.stop(c, 0)
+	}
+	b.start(c)
+	return nil
+}
+
+func (b *Backend) PauseContainer(ctx context.Context, containerID string) error {
+	return b.transition(containerID, "PAUSE", StateRunning, StatePaused)
+}
+
+func (b *Backend) UnpauseContainer(ctx context.Context, containerID string) error {
+	return b.transition(containerID, "UNPAUSE", StatePaused, StateRunning)
+}
+
+func (b *Backend) KillContainer(ctx context.Context, containerID string, signal string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO SEND " + signal + " TO CONTAINER WITH ID: " + containerID + " => " + reason)
+	}
+	c := b.findContainer(containerID)
+	if c == nil {
+		return fail(noSuchContainer(containerID).Error())
+	}
+	if c.State != StateRunning && c.State != StatePaused {
+		return fail("container " + c.ID + " is not running")
+	}
+	signum, err := signalNumber(signal)
+	if err != nil {
+		return fail(err.Error())
+	}
+	b.stop(c, 128+int64(signum))
+	return nil
+}
+
+func (b *Backend) RenameContainer(ctx context.Context, containerID string, newName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO RENAME CONTAINER WITH ID: " + containerID + " TO " + newName + " => " + reason)
+	}
+	c := b.findContainer(containerID)
+	if c == nil {
+		return fail(noSuchContainer(containerID).Error())
+	}
+	if existing := b.byName(newName); existing != nil && existing != c {
+		return fail(`Conflict. The container name "/` + newName + `" is already in use by container "` + existing.ID + `"`)
+	}
+	c.Name = newName
+	c.Config.Name = newName
+	b.notify()
+	return nil
+}
+
+func (b *Backend) PurgeContainer(containerID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO PURGE CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	for _, n := range b.networks {
+		delete(n.endpoints, c.ID)
+	}
+	delete(b.containers, c.ID)
+	b.notify()
+	return nil
+}
+
+func (b *Backend) InspectContainer(ctx context.Context, containerID string) (containers.ContainerInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return containers.ContainerInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	info := containers.ContainerInfo{
+		ID:         c.ID,
+		Name:       c.Name,
+		Image:      c.Image,
+		State:      c.State,
+		ExitCode:   c.ExitCode,
+		StartedAt:  c.StartedAt,
+		FinishedAt: c.FinishedAt,
+		IPs:        map[string]string{},
+	}
+	for name, addresses := range b.containerAddresses(c) {
+		if addresses.IPv4 != "" {
+			info.IPs[name] = addresses.IPv4
+		}
+	}
+	if cfg := c.Config.Config; cfg != nil {
+		info.Env = cfg.Env
+		info.Labels = cfg.Labels
+	}
+	if hc := c.Config.HostConfig; hc != nil {
+		for _, m := range hc.Mounts {
+			mountInfo := containers.MountInfo{
+				Type:        string(m.Type),
+				Source:      m.Source,
+				Destination: m.Target,
+				ReadOnly:    m.ReadOnly,
+			}
+			if m.Type == mount.TypeVolume {
+				mountInfo.Name = m.Source
+			}
+			info.Mounts = append(info.Mounts, mountInfo)
+		}
+	}
+	return info, nil
+}
+
+// containerAddresses maps the networks of a container to the static addresses it was given on them, fake networks assign none
+func (b *Backend) containerAddresses(c *Container) map[string]containers.NetworkAddresses {
+	addresses := map[string]containers.NetworkAddresses{}
+	for _, name := range c.Networks {
+		n := b.findNetwork(name)
+		if n == nil {
+			continue
+		}
+		if endpoint := n.endpoints[c.ID]; endpoint.IPv4Address != "" || endpoint.IPv6Address != "" {
+			addresses[name] = containers.NetworkAddresses{IPv4: endpoint.IPv4Address, IPv6: endpoint.IPv6Address}
+		}
+	}
+	return addresses
+}
+
+func (b *Backend) GetContainerHealthStatus(containerID string) (containers.HealthStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	switch {
+	case c == nil:
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	case c.State != StateRunning:
+		return containers.HealthStatus(c.State), nil
+	case !hasHealthcheck(c) || c.Health == "":
+		return containers.HealthNoHealthcheck, nil
+	}
+	return containers.HealthStatus(c.Health), nil
+}
+
+func (b *Backend) GetContainerStats(ctx context.Context, containerID string) (containers.ContainerStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return containers.ContainerStats{}, errors.New("[ERR:] [DOCKER] => FAILED TO GET STATS OF CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	stats := containers.ContainerStats{Read: time.Now()}
+	if c.State == StateRunning || c.State == StatePaused {
+		stats.PIDs = 1
+	}
+	return stats, nil
+}
+
+func (b *Backend) GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	if !strings.Contains(containerPort, "/") {
+		containerPort += "/tcp"
+	}
+	hostPort, ok := c.Ports[containerPort]
+	if !ok {
+		return "", errors.New("[ERR:] [DOCKER] => PORT " + containerPort + " OF CONTAINER WITH ID: " + containerID + " IS NOT PUBLISHED")
+	}
+	return hostPort, nil
+}
+
+func (b *Backend) GetContainerIPs(ctx context.Context, containerID string) (map[string]containers.NetworkAddresses, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	return b.containerAddresses(c), nil
+}
+
+func (b *Backend) Exec(containerID string, cmd []string) (string, error) {
+	result, err := b.ExecWithResult(context.Background(), containerID, cmd, containers.ExecOptions{})
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", errors.New("[ERR:] [DOCKER] => COMMAND EXITED WITH CODE " + fmt.Sprint(result.ExitCode) + " => " + result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+func (b *Backend) ExecWithResult(ctx context.Context, containerID string, cmd []string, opts containers.ExecOptions) (containers.ExecResult, error) {
+	b.mu.Lock()
+	c := b.findContainer(containerID)
+	var err error
+	switch {
+	case c == nil:
+		err = noSuchContainer(containerID)
+	case c.State != StateRunning:
+		err = errors.New("container " + c.ID + " is not running")
+	}
+	handler := b.ExecHandler
+	b.mu.Unlock()
+	if err != nil {
+		return containers.ExecResult{ExitCode: -1}, errors.New("[ERR:] [DOCKER] => FAILED TO CREATE EXEC ISNTANCE => " + err.Error())
+	}
+	if handler == nil {
+		return containers.ExecResult{}, nil
+	}
+	return handler(c.ID, cmd), nil
+}
+
+func (b *Backend) StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(containers.LogLine)) error {
+	showStdout, showStderr := opts.ShowStdout, opts.ShowStderr
+	if !showStdout && !showStderr {
+		showStdout, showStderr = true, true
+	}
+
+	sent := 0
+	for {
+		b.mu.Lock()
+		c := b.findContainer(containerID)
+		if c == nil {
+			b.mu.Unlock()
+			if sent == 0 {
+				return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+			}
+			return nil
+		}
+		lines := append([]containers.LogLine(nil), c.Logs[sent:]...)
+		sent = len(c.Logs)
+		running := c.State == StateRunning || c.State == StatePaused
+		changed := b.changed
+		b.mu.Unlock()
+
+		for _, line := range lines {
+			if (line.Stream == containers.LogStreamStderr && showStderr) || (line.Stream != containers.LogStreamStderr && showStdout) {
+				fn(line)
+			}
+		}
+		if !running {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func (b *Backend) CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO COPY FILES TO " + destPath + " OF CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	// Only readers keep their content, paths and file systems are recorded as empty entries
+	var data []byte
+	if r, ok := src.(io.Reader); ok {
+		read, err := io.ReadAll(r)
+		if err != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO PACKAGE FILES FOR CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		data = read
+	}
+	c.Files[destPath] = data
+	return nil
+}
+
+func (b *Backend) CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) (io.ReadCloser, error) {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO COPY " + srcPath + " FROM CONTAINER WITH ID: " + containerID + " => " + reason)
+	}
+	c := b.findContainer(containerID)
+	if c == nil {
+		return fail(noSuchContainer(containerID).Error())
+	}
+	data, ok := c.Files[srcPath]
+	if !ok {
+		return fail("Could not find the file " + srcPath + " in container " + c.Name)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *Backend) WaitForExit(ctx context.Context, containerID string) (int64, error) {
+	for {
+		b.mu.Lock()
+		c := b.findContainer(containerID)
+		if c == nil {
+			b.mu.Unlock()
+			return -1, errors.New("[ERR:] [DOCKER] => FAILED TO WAIT FOR CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+		}
+		if c.State != StateRunning && c.State != StatePaused {
+			exitCode := c.ExitCode
+			b.mu.Unlock()
+			return exitCode, nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return -1, errors.New("[ERR:] [DOCKER] => FAILED TO WAIT FOR CONTAINER WITH ID: " + containerID + " => " + ctx.Err().Error())
+		case <-changed:
+		}
+	}
+}
+
+func (b *Backend) WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		b.mu.Lock()
+		c := b.findContainer(containerID)
+		var err error
+		switch {
+		case c == nil:
+			err = errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+		case !hasHealthcheck(c):
+			err = errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " HAS NO HEALTHCHECK")
+		case c.State != StateRunning:
+			err = errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " IS NOT RUNNING => " + c.State)
+		}
+		healthy := c != nil && c.Health == "healthy"
+		changed := b.changed
+		b.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+		if healthy {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " DID NOT BECOME HEALTHY WITHIN " + timeout.String() + " => NO HEALTHCHECK RESULTS YET")
+		case <-changed:
+		}
+	}
+}
+
+func (b *Backend) CreateNetwork(ctx context.Context, name string, opts containers.NetworkOptions) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.findNetwork(name) != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO CREATE NETWORK " + name + " => network with name " + name + " already exists")
+	}
+	if opts.Driver == "" {
+		opts.Driver = "bridge"
+	}
+	return b.addNetwork(name, opts).id, nil
+}
+
+func (b *Backend) RemoveNetwork(ctx context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO REMOVE NETWORK " + name + " => " + reason)
+	}
+	n := b.findNetwork(name)
+	if n == nil {
+		return fail("network " + name + " not found")
+	}
+	if len(n.endpoints) > 0 {
+		return fail("error while removing network: network " + n.name + " id " + n.id + " has active endpoints")
+	}
+	delete(b.networks, n.id)
+	b.notify()
+	return nil
+}
+
+func (b *Backend) ConnectNetwork(ctx context.Context, networkName string, containerID string, opts containers.EndpointOptions) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO CONNECT CONTAINER WITH ID: " + containerID + " TO NETWORK " + networkName + " => " + reason)
+	}
+	n := b.findNetwork(networkName)
+	if n == nil {
+		return fail("network " + networkName + " not found")
+	}
+	c := b.findContainer(containerID)
+	if c == nil {
+		return fail(noSuchContainer(containerID).Error())
+	}
+	if _, ok := n.endpoints[c.ID]; ok {
+		return fail("endpoint with name " + c.Name + " already exists in network " + n.name)
+	}
+	n.endpoints[c.ID] = opts
+	c.Networks = append(c.Networks, n.name)
+	b.notify()
+	return nil
+}
+
+func (b *Backend) DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO DISCONNECT CONTAINER WITH ID: " + containerID + " FROM NETWORK " + networkName + " => " + reason)
+	}
+	n := b.findNetwork(networkName)
+	if n == nil {
+		return fail("network " + networkName + " not found")
+	}
+	c := b.findContainer(containerID)
+	if c == nil {
+		return fail(noSuchContainer(containerID).Error())
+	}
+	if _, ok := n.endpoints[c.ID]; !ok {
+		return fail("container " + c.ID + " is not connected to network " + n.name)
+	}
+	delete(n.endpoints, c.ID)
+	for i, name := range c.Networks {
+		if name == n.name {
+			c.Networks = append(c.Networks[:i], c.Networks[i+1:]...)
+			break
+		}
+	}
+	b.notify()
+	return nil
+}
+
+func (b *Backend) InspectNetwork(ctx context.Context, name string) (containers.NetworkInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := b.findNetwork(name)
+	if n == nil {
+		return containers.NetworkInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT NETWORK " + name + " => network " + name + " not found")
+	}
+	info := containers.NetworkInfo{
+		ID:         n.id,
+		Name:       n.name,
+		Driver:     n.opts.Driver,
+		Scope:      "local",
+		Internal:   n.opts.Internal,
+		Attachable: n.opts.Attachable,
+		EnableIPv6: n.opts.EnableIPv6,
+		Labels:     n.opts.Labels,
+		Options:    n.opts.Options,
+	}
+	if n.opts.Subnet != "" {
+		info.Subnets = []string{n.opts.Subnet}
+	}
+	if n.opts.Gateway != "" {
+		info.Gateways = []string{n.opts.Gateway}
+	}
+	for id, endpoint := range n.endpoints {
+		info.Containers = append(info.Containers, containers.NetworkContainer{
+			ID:          id,
+			Name:        b.containers[id].Name,
+			IPv4Address: endpoint.IPv4Address,
+			IPv6Address: endpoint.IPv6Address,
+		})
+	}
+	sort.Slice(info.Containers, func(i, j int) bool {
+		return info.Containers[i].Name < info.Containers[j].Name
+	})
+	return info, nil
+}
+
+func (b *Backend) CreateVolume(ctx context.Context, name string, opts containers.VolumeOptions) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if name == "" {
+		name = b.newID("volume")
+	}
+	// Like the daemon, creating an existing volume is a no-op
+	if _, ok := b.volumes[name]; !ok {
+		driver := opts.Driver
+		if driver == "" {
+			driver = "local"
+		}
+		b.volumes[name] = &volume.Volume{
+			Name:      name,
+			Driver:    driver,
+			Labels:    opts.Labels,
+			Options:   opts.DriverOpts,
+			Scope:     "local",
+			CreatedAt: time.Now().Format(time.RFC3339),
+		}
+		b.notify()
+	}
+	return name, nil
+}
+
+func (b *Backend) RemoveVolume(ctx context.Context, name string, force bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := func(reason string) error {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO REMOVE VOLUME " + name + " => " + reason)
+	}
+	if _, ok := b.volumes[name]; !ok {
+		if force {
+			return nil
+		}
+		return fail("get " + name + ": no such volume")
+	}
+	// Force does not remove volumes that are in use, neither does the daemon
+	if users := b.volumeUsers(name); len(users) > 0 {
+		return fail("remove " + name + ": volume is in use - [" + strings.Join(users, ", ") + "]")
+	}
+	delete(b.volumes, name)
+	b.notify()
+	return nil
+}
+
+func (b *Backend) ListVolumes(ctx context.Context, filter containers.VolumeFilter) ([]*volume.Volume, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var list []*volume.Volume
+	for name, v := range b.volumes {
+		if filter.Name != "" && !strings.Contains(name, filter.Name) {
+			continue
+		}
+		if filter.Driver != "" && v.Driver != filter.Driver {
+			continue
+		}
+		if !matchLabels(v.Labels, filter.Labels) {
+			continue
+		}
+		if filter.Dangling != nil && *filter.Dangling != (len(b.volumeUsers(name)) == 0) {
+			continue
+		}
+		copied := *v
+		list = append(list, &copied)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// transition moves a container from one state to another, failing if it is not in the expected state
+func (b *Backend) transition(containerID string, action string, from string, to string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.findContainer(containerID)
+	if c == nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO " + action + " CONTAINER WITH ID: " + containerID + " => " + noSuchContainer(containerID).Error())
+	}
+	if c.State != from {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO " + action + " CONTAINER WITH ID: " + containerID + " => container " + c.ID + " is " + c.State + ", not " + from)
+	}
+	c.State = to
+	b.notify()
+	return nil
+}
+
+// start moves a container to running, publishing its ports and resetting its health
+func (b *Backend) start(c *Container) {
+	c.State = StateRunning
+	c.ExitCode = 0
+	c.StartedAt = time.Now()
+	c.Health = ""
+	if hasHealthcheck(c) {
+		c.Health = b.StartHealth
+		if c.Health == "" {
+			c.Health = "healthy"
+		}
+	}
+	c.Ports = map[string]string{}
+	if c.Config.HostConfig != nil {
+		for port, bindings := range c.Config.HostConfig.PortBindings {
+			hostPort := ""
+			if len(bindings) > 0 {
+				hostPort = bindings[0].HostPort
+			}
+			if hostPort == "" || hostPort == "0" {
+				hostPort = strconv.Itoa(b.nextPort)
+				b.nextPort++
+			}
+			c.Ports[string(port)] = hostPort
+		}
+	}
+	b.notify()
+}
+
+// stop moves a container to exited with an exit code, unpublishing its ports
+func (b *Backend) stop(c *Container, exitCode int64) {
+	c.State = StateExited
+	c.ExitCode = exitCode
+	c.FinishedAt = time.Now()
+	c.Ports = map[string]string{}
+	if c.Health != "" {
+		c.Health = "unhealthy"
+	}
+	b.notify()
+}
+
+// notify wakes everything waiting on a state change. Callers hold mu
+func (b *Backend) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+func (b *Backend) newID(kind string) string {
+	b.seq++
+	sum := sha256.Sum256([]byte(kind + "-" + strconv.Itoa(b.seq)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (b *Backend) addImage(imageName string) {
+	name := normalizeImage(imageName)
+	if _, ok := b.images[name]; !ok {
+		b.images[name] = "sha256:" + b.newID("image")
+	}
+}
+
+func (b *Backend) addNetwork(name string, opts containers.NetworkOptions) *network {
+	n := &network{id: b.newID("network"), name: name, opts: opts, endpoints: map[string]containers.EndpointOptions{}}
+	b.networks[n.id] = n
+	b.notify()
+	return n
+}
+
+// findContainer looks a container up by ID, unique ID prefix or name. Callers hold mu
+func (b *Backend) findContainer(ref string) *Container {
+	if c, ok := b.containers[ref]; ok {
+		return c
+	}
+	if c := b.byName(strings.TrimPrefix(ref, "/")); c != nil {
+		return c
+	}
+	var found *Container
+	for id, c := range b.containers {
+		if ref != "" && strings.HasPrefix(id, ref) {
+			if found != nil {
+				return nil
+			}
+			found = c
+		}
+	}
+	return found
+}
+
+func (b *Backend) byName(name string) *Container {
+	for _, c := range b.containers {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// findNetwork looks a network up by ID or name. Callers hold mu
+func (b *Backend) findNetwork(ref string) *network {
+	if n, ok := b.networks[ref]; ok {
+		return n
+	}
+	for _, n := range b.networks {
+		if n.name == ref {
+			return n
+		}
+	}
+	return nil
+}
+
+// volumeUsers returns the names of the containers that mount a volume. Callers hold mu
+func (b *Backend) volumeUsers(name string) []string {
+	var users []string
+	for _, c := range b.containers {
+		for _, volumeName := range namedVolumes(&c.Config) {
+			if volumeName == name {
+				users = append(users, c.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// namedVolumes returns the named volumes a container config mounts, through Mounts or Binds
+func namedVolumes(config *containers.ContainerCreateConfig) []string {
+	if config.HostConfig == nil {
+		return nil
+	}
+	var names []string
+	for _, m := range config.HostConfig.Mounts {
+		if m.Type == mount.TypeVolume && m.Source != "" {
+			names = append(names, m.Source)
+		}
+	}
+	for _, bind := range config.HostConfig.Binds {
+		source, _, _ := strings.Cut(bind, ":")
+		if source != "" && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "~") {
+			names = append(names, source)
+		}
+	}
+	return names
+}
+
+func hasHealthcheck(c *Container) bool {
+	hc := c.Config.Config
+	if hc == nil || hc.Healthcheck == nil || len(hc.Healthcheck.Test) == 0 {
+		return false
+	}
+	return hc.Healthcheck.Test[0] != "NONE"
+}
+
+func matchLabels(labels map[string]string, want map[string]string) bool {
+	for key, value := range want {
+		got, ok := labels[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeImage adds the latest tag to untagged image names so "redis" and "redis:latest" are the same image
+func normalizeImage(imageName string) string {
+	if strings.Contains(imageName, "@") {
+		return imageName
+	}
+	if i := strings.LastIndex(imageName, ":"); i < 0 || strings.Contains(imageName[i:], "/") {
+		return imageName + ":latest"
+	}
+	return imageName
+}
+
+// signalNumber resolves a signal given by name (KILL, SIGKILL) or number. An empty signal is SIGKILL, like for the daemon.
+// The numbers are the Linux ones, whatever the host is
+func signalNumber(signal string) (int, error) {
+	if signal == "" {
+		return 9, nil
+	}
+	if n, err := strconv.Atoi(signal); err == nil {
+		return n, nil
+	}
+	signals := map[string]int{"HUP": 1, "INT": 2, "QUIT": 3, "KILL": 9, "USR1": 10, "USR2": 12, "TERM": 15}
+	if n, ok := signals[strings.TrimPrefix(strings.ToUpper(signal), "SIG")]; ok {
+		return n, nil
+	}
+	return 0, errors.New("invalid signal: " + signal)
+}
+
+func noSuchContainer(ref string) error {
+	return errors.New("No such container: " + ref)
+}
+
+func snapshot(c *Container) Container {
+	copied := *c
+	copied.Networks = append([]string(nil), c.Networks...)
+	copied.Logs = append([]containers.LogLine(nil), c.Logs...)
+	copied.Ports = make(map[string]string, len(c.Ports))
+	for port, hostPort := range c.Ports {
+		copied.Ports[port] = hostPort
+	}
+	copied.Files = make(map[string][]byte, len(c.Files))
+	for path, data := range c.Files {
+		copied.Files[path] = data
+	}
+	return copied
+}
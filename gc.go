@@ -0,0 +1,204 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+)
+
+// GCImages ~ Removes the images no container uses, whether running or not, that are older than MaxAge or beyond the newest
+// KeepPerRepository of each repository they are tagged in. Images matching Keep are never removed. Returns what was removed and a
+// *BatchError for the images that could not be
+func GCImages(ctx context.Context, policy ImageGCPolicy) (ImageGCReport, error) {
+	var report ImageGCReport
+	if policy.MaxAge <= 0 && policy.KeepPerRepository <= 0 {
+		return report, errors.New("[ERR:] [GC] => IMAGE GC NEEDS A MAX AGE OR A COUNT TO KEEP PER REPOSITORY")
+	}
+
+	listFilters := filters.NewArgs()
+	addLabelFilters(listFilters, policy.Labels)
+	images, err := retryResult(ctx, func() ([]image.Summary, error) {
+		return DockerClient.ImageList(ctx, image.ListOptions{Filters: listFilters})
+	})
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO LIST IMAGES => " + err.Error())
+	}
+	containerList, err := retryResult(ctx, func() ([]types.Container, error) {
+		return DockerClient.ContainerList(ctx, container.ListOptions{All: true})
+	})
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO LIST CONTAINERS => " + err.Error())
+	}
+	used := make(map[string]bool, len(containerList))
+	for _, c := range containerList {
+		used[c.ImageID] = true
+	}
+
+	candidates := imageGCCandidates(images, used, policy, time.Now())
+	failures := map[string]error{}
+	for _, img := range candidates {
+		name := img.ID
+		if len(img.RepoTags) > 0 && img.RepoTags[0] != "<none>:<none>" {
+			name = img.RepoTags[0]
+		}
+		if dryRun(OpDeleteImage, name, name, "gc", "true") {
+			report.Removed = append(report.Removed, name)
+			continue
+		}
+		opCtx, done := beginOperation(ctx, OpDeleteImage, name, name, "gc", "true")
+		// Force removes every tag of the image, candidates are unused in all of their repositories
+		_, err := DockerClient.ImageRemove(opCtx, img.ID, image.RemoveOptions{Force: true, PruneChildren: true})
+		done(err)
+		if err != nil {
+			failures[name] = err
+			continue
+		}
+		report.Removed = append(report.Removed, name)
+		report.SpaceReclaimed += uint64(img.Size)
+	}
+
+	if len(failures) > 0 {
+		return report, &BatchError{Operation: "GC IMAGES", Total: len(candidates), Failures: failures}
+	}
+	return report, nil
+}
+
+// imageGCCandidates picks the images GCImages removes, oldest first
+func imageGCCandidates(images []image.Summary, used map[string]bool, policy ImageGCPolicy, now time.Time) []image.Summary {
+	// Newest first, so the index of an image within its repository is its rank
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Created != images[j].Created {
+			return images[i].Created > images[j].Created
+		}
+		return images[i].ID < images[j].ID
+	})
+
+	excess := map[string]bool{}
+	if policy.KeepPerRepository > 0 {
+		kept := map[string]bool{}
+		ranks := map[string]int{}
+		for _, img := range images {
+			for _, repository := range imageRepositories(img) {
+				ranks[repository]++
+				if ranks[repository] <= policy.KeepPerRepository {
+					kept[img.ID] = true
+				} else {
+					excess[img.ID] = true
+				}
+			}
+		}
+		// Being among the newest of any repository keeps an image
+		for id := range kept {
+			delete(excess, id)
+		}
+	}
+
+	var candidates []image.Summary
+	for i := len(images) - 1; i >= 0; i-- {
+		img := images[i]
+		if used[img.ID] || keepImage(img, policy.Keep) {
+			continue
+		}
+		old := policy.MaxAge > 0 && now.Sub(time.Unix(img.Created, 0)) > policy.MaxAge
+		if old || excess[img.ID] {
+			candidates = append(candidates, img)
+		}
+	}
+	return candidates
+}
+
+// imageRepositories returns the repositories an image is tagged in, by their familiar names
+func imageRepositories(img image.Summary) []string {
+	seen := map[string]bool{}
+	var repositories []string
+	for _, tag := range img.RepoTags {
+		named, err := reference.ParseNormalizedNamed(tag)
+		if err != nil {
+			continue
+		}
+		if repository := reference.FamiliarName(named); !seen[repository] {
+			seen[repository] = true
+			repositories = append(repositories, repository)
+		}
+	}
+	return repositories
+}
+
+// keepImage reports whether a tag of the image matches one of the patterns
+func keepImage(img image.Summary, patterns []string) bool {
+	for _, tag := range img.RepoTags {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, tag); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GCContainers ~ Removes exited and dead containers, with their anonymous volumes, that finished longer ago than MaxAge and carry
+// Labels. At least one of the two must be set. With DryRun nothing is removed and the report lists what would be. Returns a
+// *BatchError for the containers that could not be removed
+func GCContainers(ctx context.Context, policy ContainerGCPolicy) (ContainerGCReport, error) {
+	var report ContainerGCReport
+	if policy.MaxAge <= 0 && len(policy.Labels) == 0 {
+		return report, errors.New("[ERR:] [GC] => CONTAINER GC NEEDS A MAX AGE OR LABELS")
+	}
+
+	listFilters := filters.NewArgs(filters.Arg("status", "exited"), filters.Arg("status", "dead"))
+	addLabelFilters(listFilters, policy.Labels)
+	containerList, err := retryResult(ctx, func() ([]types.Container, error) {
+		return DockerClient.ContainerList(ctx, container.ListOptions{All: true, Size: true, Filters: listFilters})
+	})
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO LIST CONTAINERS => " + err.Error())
+	}
+
+	failures := map[string]error{}
+	total := 0
+	now := time.Now()
+	for _, c := range containerList {
+		if policy.MaxAge > 0 {
+			containerJSON, err := DockerClient.ContainerInspect(ctx, c.ID)
+			if err != nil {
+				// Removed since it was listed
+				continue
+			}
+			finished, err := time.Parse(time.RFC3339Nano, containerJSON.State.FinishedAt)
+			if err != nil || finished.IsZero() {
+				finished = time.Unix(c.Created, 0)
+			}
+			if now.Sub(finished) <= policy.MaxAge {
+				continue
+			}
+		}
+
+		total++
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		if !policy.DryRun {
+			if err := PurgeContainerContext(ctx, c.ID); err != nil {
+				failures[name] = err
+				continue
+			}
+		}
+		report.Removed = append(report.Removed, name)
+		report.SpaceReclaimed += uint64(c.SizeRw)
+	}
+
+	if len(failures) > 0 {
+		return report, &BatchError{Operation: "GC CONTAINERS", Total: total, Failures: failures}
+	}
+	return report, nil
+}
@@ -0,0 +1,46 @@
+package containers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+func TestImageGCCandidates(t *testing.T) {
+	now := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) int64 { return now.AddDate(0, 0, -days).Unix() }
+	images := []image.Summary{
+		{ID: "app-1", RepoTags: []string{"app:1"}, Created: daysAgo(30)},
+		{ID: "app-2", RepoTags: []string{"app:2"}, Created: daysAgo(20)},
+		{ID: "app-3", RepoTags: []string{"app:3", "docker.io/library/app:latest"}, Created: daysAgo(1)},
+		{ID: "db-1", RepoTags: []string{"db:1"}, Created: daysAgo(40)},
+		{ID: "shared", RepoTags: []string{"app:old", "tools:1"}, Created: daysAgo(50)},
+		{ID: "dangling", Created: daysAgo(60)},
+	}
+	tests := []struct {
+		name   string
+		used   map[string]bool
+		policy ImageGCPolicy
+		want   []string
+	}{
+		{"no policy", nil, ImageGCPolicy{}, nil},
+		{"max age, oldest first", nil, ImageGCPolicy{MaxAge: 25 * 24 * time.Hour}, []string{"dangling", "shared", "db-1", "app-1"}},
+		{"keep per repository", nil, ImageGCPolicy{KeepPerRepository: 1}, []string{"app-1", "app-2"}},
+		{"keep per repository, used image kept", map[string]bool{"app-1": true}, ImageGCPolicy{KeepPerRepository: 1}, []string{"app-2"}},
+		{"keep pattern", nil, ImageGCPolicy{MaxAge: 25 * 24 * time.Hour, Keep: []string{"db:*", "app:old"}}, []string{"dangling", "app-1"}},
+		{"either rule", nil, ImageGCPolicy{MaxAge: 45 * 24 * time.Hour, KeepPerRepository: 2}, []string{"dangling", "shared", "app-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, img := range imageGCCandidates(append([]image.Summary{}, images...), tt.used, tt.policy, now) {
+				got = append(got, img.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
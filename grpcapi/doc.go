@@ -0,0 +1,14 @@
+// Package grpcapi serves the operations of the containers package over gRPC, so a controller can drive thin agents on many hosts.
+// The service is defined in pb/containers.proto. Logs, build output, stats and events are server streams.
+//
+// The generated Go code of the service is committed in pb. The server is only compiled with the grpc build tag, so programs that don't
+// serve gRPC don't link it:
+//
+//	go build -tags grpc ./...
+//
+// Run go generate ./grpcapi with protoc, protoc-gen-go and protoc-gen-go-grpc installed after changing the proto file.
+//
+// The server has no authentication of its own, pass credentials and interceptors to grpc.NewServer
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pb/containers.proto
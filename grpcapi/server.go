@@ -0,0 +1,313 @@
+//go:build grpc
+
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/G-MAKROGLOU/containers/grpcapi/pb"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server ~ Implements the Containers service on top of the containers package, see Register
+type Server struct {
+	pb.UnimplementedContainersServer
+}
+
+// Register ~ Serves the Containers service on s
+func Register(s grpc.ServiceRegistrar) *Server {
+	server := &Server{}
+	pb.RegisterContainersServer(s, server)
+	return server
+}
+
+// ListContainers ~ Lists containers, optionally stopped ones and by labels
+func (s *Server) ListContainers(ctx context.Context, req *pb.ListContainersRequest) (*pb.ListContainersResponse, error) {
+	listFilters := filters.NewArgs()
+	for key, value := range req.GetLabels() {
+		if value == "" {
+			listFilters.Add("label", key)
+		} else {
+			listFilters.Add("label", key+"="+value)
+		}
+	}
+	summaries, err := containers.DockerClient.ContainerList(ctx, container.ListOptions{All: req.GetAll(), Filters: listFilters})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListContainersResponse{}
+	for _, summary := range summaries {
+		name := ""
+		if len(summary.Names) > 0 {
+			name = strings.TrimPrefix(summary.Names[0], "/")
+		}
+		res.Containers = append(res.Containers, &pb.ContainerSummary{
+			Id:      summary.ID,
+			Name:    name,
+			Image:   summary.Image,
+			State:   summary.State,
+			Status:  summary.Status,
+			Created: timestamppb.New(time.Unix(summary.Created, 0)),
+			Labels:  summary.Labels,
+		})
+	}
+	return res, nil
+}
+
+// CreateContainer ~ Creates a container from a JSON encoded ContainerCreateConfig
+func (s *Server) CreateContainer(ctx context.Context, req *pb.CreateContainerRequest) (*pb.CreateContainerResponse, error) {
+	config := &containers.ContainerCreateConfig{}
+	if err := json.Unmarshal(req.GetSpecJson(), config); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "[ERR:] [GRPC] => INVALID CONTAINER SPEC => "+err.Error())
+	}
+	if config.Config == nil || config.Config.Image == "" {
+		return nil, status.Error(codes.InvalidArgument, "[ERR:] [GRPC] => INVALID CONTAINER SPEC => Config.Image IS REQUIRED")
+	}
+	if req.GetEnsureImage() {
+		if err := containers.EnsureImage(ctx, config.Config.Image); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	cont, err := containers.CreateContainerContext(ctx, config)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateContainerResponse{Id: cont.ID, Warnings: cont.Warnings}, nil
+}
+
+// StartContainer ~ Starts a container
+func (s *Server) StartContainer(ctx context.Context, req *pb.ContainerRef) (*emptypb.Empty, error) {
+	if err := containers.StartContainerContext(ctx, container.CreateResponse{ID: req.GetId()}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// StopContainer ~ Stops a container
+func (s *Server) StopContainer(ctx context.Context, req *pb.ContainerRef) (*emptypb.Empty, error) {
+	if err := containers.StopContainerContext(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RemoveContainer ~ Force removes a container with its anonymous volumes
+func (s *Server) RemoveContainer(ctx context.Context, req *pb.ContainerRef) (*emptypb.Empty, error) {
+	if err := containers.PurgeContainerContext(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Exec ~ Runs a command in a running container and returns its output
+func (s *Server) Exec(ctx context.Context, req *pb.ExecRequest) (*pb.ExecResponse, error) {
+	if len(req.GetCmd()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "[ERR:] [GRPC] => cmd IS REQUIRED")
+	}
+	opts := containers.ExecOptions{User: req.GetUser(), WorkingDir: req.GetWorkingDir(), Env: req.GetEnv()}
+	if len(req.GetStdin()) > 0 {
+		opts.Stdin = bytes.NewReader(req.GetStdin())
+	}
+	result, err := containers.ExecWithResult(ctx, req.GetId(), req.GetCmd(), opts)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ExecResponse{
+		ExitCode:   int32(result.ExitCode),
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		DurationMs: result.Duration.Milliseconds(),
+	}, nil
+}
+
+// PullImage ~ Pulls an image unless it is present already
+func (s *Server) PullImage(ctx context.Context, req *pb.PullImageRequest) (*emptypb.Empty, error) {
+	if err := containers.EnsureImage(ctx, req.GetImage()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// BuildImage ~ Builds an image from a context directory on the agent host, streaming the build output
+func (s *Server) BuildImage(req *pb.BuildImageRequest, stream pb.Containers_BuildImageServer) error {
+	if len(req.GetTags()) == 0 {
+		return status.Error(codes.InvalidArgument, "[ERR:] [GRPC] => AT LEAST ONE TAG IS REQUIRED")
+	}
+	out := &buildStream{stream: stream}
+	results, err := containers.BuildImages(stream.Context(), []containers.BuildSpec{{
+		Context:    req.GetContextDir(),
+		Dockerfile: req.GetDockerfile(),
+		Tags:       req.GetTags(),
+		BuildArgs:  req.GetBuildArgs(),
+		Labels:     req.GetLabels(),
+		NoCache:    req.GetNoCache(),
+		PullParent: req.GetPullParent(),
+	}}, 1, out)
+	if out.err != nil {
+		return out.err
+	}
+	if err != nil {
+		return toStatus(err)
+	}
+	return stream.Send(&pb.BuildOutput{ImageId: results[0].ImageID})
+}
+
+// Logs ~ Streams the log lines of a container
+func (s *Server) Logs(req *pb.LogsRequest, stream pb.Containers_LogsServer) error {
+	opts := container.LogsOptions{Tail: req.GetTail()}
+	if req.GetSince() != nil {
+		opts.Since = req.GetSince().AsTime().Format(time.RFC3339Nano)
+	}
+
+	var sendErr error
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	err := containers.StreamContainerLogs(ctx, req.GetId(), opts, func(line containers.LogLine) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(&pb.LogLine{
+			Timestamp: timestamppb.New(line.Timestamp),
+			Stream:    line.Stream,
+			Message:   line.Message,
+		}); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return toStatus(err)
+}
+
+// Stats ~ Streams resource usage samples of a container
+func (s *Server) Stats(req *pb.ContainerRef, stream pb.Containers_StatsServer) error {
+	var sendErr error
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	err := containers.StreamContainerStats(ctx, req.GetId(), func(stats containers.ContainerStats) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(&pb.StatsSample{
+			Read:          timestamppb.New(stats.Read),
+			CpuPercent:    stats.CPUPercent,
+			MemoryUsage:   stats.MemoryUsage,
+			MemoryLimit:   stats.MemoryLimit,
+			MemoryPercent: stats.MemoryPercent,
+			NetworkRx:     stats.NetworkRx,
+			NetworkTx:     stats.NetworkTx,
+			BlockRead:     stats.BlockRead,
+			BlockWrite:    stats.BlockWrite,
+			Pids:          stats.PIDs,
+		}); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return toStatus(err)
+}
+
+// Events ~ Streams daemon events
+func (s *Server) Events(req *pb.EventsRequest, stream pb.Containers_EventsServer) error {
+	eventFilters := filters.NewArgs()
+	for _, eventType := range req.GetTypes() {
+		eventFilters.Add("type", eventType)
+	}
+	for _, c := range req.GetContainers() {
+		eventFilters.Add("container", c)
+	}
+	for key, value := range req.GetLabels() {
+		if value == "" {
+			eventFilters.Add("label", key)
+		} else {
+			eventFilters.Add("label", key+"="+value)
+		}
+	}
+
+	var sendErr error
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	err := containers.SubscribeEvents(ctx, eventFilters, func(event containers.Event) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(&pb.Event{
+			Type:         event.Type,
+			Action:       event.Action,
+			ActorId:      event.ActorID,
+			Name:         event.Name,
+			Image:        event.Image,
+			HealthStatus: event.HealthStatus,
+			Attributes:   event.Attributes,
+			Time:         timestamppb.New(event.Time),
+		}); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if stream.Context().Err() != nil {
+		return nil
+	}
+	return toStatus(err)
+}
+
+// buildStream ~ Sends every line of build output as a message, remembering the first failure
+type buildStream struct {
+	stream pb.Containers_BuildImageServer
+	err    error
+}
+
+func (b *buildStream) Write(p []byte) (int, error) {
+	if b.err == nil {
+		b.err = b.stream.Send(&pb.BuildOutput{Line: strings.TrimSuffix(string(p), "\n")})
+	}
+	return len(p), nil
+}
+
+// toStatus maps an error of the containers package to a gRPC status
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := codes.Unknown
+	switch containers.ErrorType(err) {
+	case "not_found":
+		code = codes.NotFound
+	case "conflict":
+		code = codes.FailedPrecondition
+	case "invalid":
+		code = codes.InvalidArgument
+	case "unauthorized":
+		code = codes.Unauthenticated
+	case "forbidden":
+		code = codes.PermissionDenied
+	case "unavailable":
+		code = codes.Unavailable
+	case "rate_limited":
+		code = codes.ResourceExhausted
+	case "timeout":
+		code = codes.DeadlineExceeded
+	case "cancelled":
+		code = codes.Canceled
+	case "system":
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,37 @@
+package containers
+
+import (
+	"github.com/docker/docker/api/types/container"
+)
+
+// HealthcheckCmd ~ A healthcheck that runs cmd directly (CMD form). The container is healthy while cmd exits with 0
+func HealthcheckCmd(opts HealthcheckOptions, cmd ...string) *container.HealthConfig {
+	return newHealthConfig(append([]string{"CMD"}, cmd...), opts)
+}
+
+// HealthcheckShell ~ A healthcheck that runs command with the default shell of the container (CMD-SHELL form)
+func HealthcheckShell(command string, opts HealthcheckOptions) *container.HealthConfig {
+	return newHealthConfig([]string{"CMD-SHELL", command}, opts)
+}
+
+// HealthcheckNone ~ Disables a healthcheck inherited from the image
+func HealthcheckNone() *container.HealthConfig {
+	return &container.HealthConfig{Test: []string{"NONE"}}
+}
+
+// SetHealthcheck ~ Sets the healthcheck of a container, see HealthcheckCmd and HealthcheckShell
+func SetHealthcheck(config *ContainerCreateConfig, healthcheck *container.HealthConfig) {
+	ensureContainerConfigs(config)
+	config.Config.Healthcheck = healthcheck
+}
+
+func newHealthConfig(test []string, opts HealthcheckOptions) *container.HealthConfig {
+	return &container.HealthConfig{
+		Test:          test,
+		Interval:      opts.Interval,
+		Timeout:       opts.Timeout,
+		StartPeriod:   opts.StartPeriod,
+		StartInterval: opts.StartInterval,
+		Retries:       opts.Retries,
+	}
+}
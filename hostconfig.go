@@ -0,0 +1,185 @@
+package containers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+)
+
+// RestartNo ~ Never restart the container (the daemon default)
+func RestartNo() container.RestartPolicy {
+	return container.RestartPolicy{Name: container.RestartPolicyDisabled}
+}
+
+// RestartAlways ~ Always restart the container when it stops, including after a daemon restart
+func RestartAlways() container.RestartPolicy {
+	return container.RestartPolicy{Name: container.RestartPolicyAlways}
+}
+
+// RestartOnFailure ~ Restart the container when it exits with a non-zero code, at most maxRetries times (0 for unlimited)
+func RestartOnFailure(maxRetries int) container.RestartPolicy {
+	return container.RestartPolicy{
+		Name:              container.RestartPolicyOnFailure,
+		MaximumRetryCount: maxRetries,
+	}
+}
+
+// RestartUnlessStopped ~ Always restart the container unless it was stopped explicitly
+func RestartUnlessStopped() container.RestartPolicy {
+	return container.RestartPolicy{Name: container.RestartPolicyUnlessStopped}
+}
+
+// SetRestartPolicy ~ Sets the restart policy of a container, see RestartAlways, RestartOnFailure and RestartUnlessStopped
+func SetRestartPolicy(config *ContainerCreateConfig, policy container.RestartPolicy) {
+	ensureContainerConfigs(config)
+	config.HostConfig.RestartPolicy = policy
+}
+
+// JSONFileLogs ~ Logs to the daemon's default json-file driver, rotated at maxSize (e.g. "10m") keeping at most maxFile files.
+// An empty maxSize or a maxFile of 0 leaves the daemon default, which for json-file never rotates
+func JSONFileLogs(maxSize string, maxFile int) container.LogConfig {
+	return container.LogConfig{Type: "json-file", Config: rotationOptions(maxSize, maxFile)}
+}
+
+// LocalLogs ~ Logs to the compressed local driver, rotated at maxSize keeping at most maxFile files. It rotates at 20m over 5 files by default
+func LocalLogs(maxSize string, maxFile int) container.LogConfig {
+	return container.LogConfig{Type: "local", Config: rotationOptions(maxSize, maxFile)}
+}
+
+// SyslogLogs ~ Sends logs to syslog at address (e.g. "udp://logs.internal:514"), the local syslog if empty, tagged with tag if set
+func SyslogLogs(address string, tag string) container.LogConfig {
+	return container.LogConfig{Type: "syslog", Config: logOptions("syslog-address", address, "tag", tag)}
+}
+
+// FluentdLogs ~ Sends logs to the fluentd at address (e.g. "fluentd.internal:24224"), localhost:24224 if empty, tagged with tag if set.
+// Logs are buffered while fluentd is unreachable instead of failing the container start
+func FluentdLogs(address string, tag string) container.LogConfig {
+	return container.LogConfig{Type: "fluentd", Config: logOptions("fluentd-address", address, "tag", tag, "fluentd-async", "true")}
+}
+
+// AWSLogs ~ Sends logs to the CloudWatch Logs group in region, to stream if set and to a stream named after the container ID otherwise
+func AWSLogs(region string, group string, stream string) container.LogConfig {
+	return container.LogConfig{Type: "awslogs", Config: logOptions("awslogs-region", region, "awslogs-group", group, "awslogs-stream", stream)}
+}
+
+// SetLogConfig ~ Sets the log driver of a container, see JSONFileLogs, LocalLogs, SyslogLogs, FluentdLogs and AWSLogs
+func SetLogConfig(config *ContainerCreateConfig, logConfig container.LogConfig) {
+	ensureContainerConfigs(config)
+	config.HostConfig.LogConfig = logConfig
+}
+
+// rotationOptions returns the rotation options shared by the json-file and local drivers
+func rotationOptions(maxSize string, maxFile int) map[string]string {
+	options := logOptions("max-size", maxSize)
+	if maxFile > 0 {
+		options["max-file"] = strconv.Itoa(maxFile)
+	}
+	return options
+}
+
+// logOptions builds driver options from key value pairs, leaving out empty values
+func logOptions(pairs ...string) map[string]string {
+	options := map[string]string{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			options[pairs[i]] = pairs[i+1]
+		}
+	}
+	return options
+}
+
+// ContainerOption ~ Modifies a container config, see ApplyOptions
+type ContainerOption func(config *ContainerCreateConfig) error
+
+// ApplyOptions ~ Applies options to a container config in order, stopping at the first error
+func ApplyOptions(config *ContainerCreateConfig, opts ...ContainerOption) error {
+	ensureContainerConfigs(config)
+	for _, opt := range opts {
+		if err := opt(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithMemoryLimit ~ Limits the memory of a container to a human readable size, e.g. "512m" or "2g"
+func WithMemoryLimit(limit string) ContainerOption {
+	return func(config *ContainerCreateConfig) error {
+		bytes, err := units.RAMInBytes(limit)
+		if err != nil || bytes <= 0 {
+			return errors.New("[ERR:] [DOCKER] => INVALID MEMORY LIMIT " + limit)
+		}
+		config.HostConfig.Memory = bytes
+		return nil
+	}
+}
+
+// WithCPUs ~ Limits a container to a number of CPUs, e.g. 1.5, using a CFS quota over the default 100ms period
+func WithCPUs(cpus float64) ContainerOption {
+	return func(config *ContainerCreateConfig) error {
+		if cpus <= 0 {
+			return errors.New("[ERR:] [DOCKER] => INVALID CPU LIMIT " + strconv.FormatFloat(cpus, 'f', -1, 64))
+		}
+		config.HostConfig.CPUPeriod = cfsPeriod
+		config.HostConfig.CPUQuota = int64(cpus * cfsPeriod)
+		return nil
+	}
+}
+
+// WithPidsLimit ~ Limits the number of processes in a container
+func WithPidsLimit(limit int64) ContainerOption {
+	return func(config *ContainerCreateConfig) error {
+		if limit <= 0 {
+			return errors.New("[ERR:] [DOCKER] => INVALID PIDS LIMIT " + strconv.FormatInt(limit, 10))
+		}
+		config.HostConfig.PidsLimit = &limit
+		return nil
+	}
+}
+
+// cfsPeriod is the default CFS scheduler period in microseconds
+const cfsPeriod = 100000
+
+// WithGPUs ~ Requests count GPUs for a container, or all of them when count is -1. Capabilities default to "gpu", add e.g. "compute" or "utility" to narrow them
+func WithGPUs(count int, capabilities ...string) ContainerOption {
+	return func(config *ContainerCreateConfig) error {
+		if count == 0 || count < -1 {
+			return errors.New("[ERR:] [DOCKER] => INVALID GPU COUNT " + strconv.Itoa(count))
+		}
+		config.HostConfig.DeviceRequests = append(config.HostConfig.DeviceRequests, container.DeviceRequest{
+			Count:        count,
+			Capabilities: [][]string{gpuCapabilities(capabilities)},
+		})
+		return nil
+	}
+}
+
+// WithAllGPUs ~ Requests every GPU of the host for a container, like `--gpus all`
+func WithAllGPUs(capabilities ...string) ContainerOption {
+	return WithGPUs(-1, capabilities...)
+}
+
+// WithGPUDevices ~ Requests specific GPUs by index or UUID for a container, like `--gpus '"device=0,1"'`
+func WithGPUDevices(deviceIDs []string, capabilities ...string) ContainerOption {
+	return func(config *ContainerCreateConfig) error {
+		if len(deviceIDs) == 0 {
+			return errors.New("[ERR:] [DOCKER] => NO GPU DEVICES REQUESTED")
+		}
+		config.HostConfig.DeviceRequests = append(config.HostConfig.DeviceRequests, container.DeviceRequest{
+			DeviceIDs:    deviceIDs,
+			Capabilities: [][]string{gpuCapabilities(capabilities)},
+		})
+		return nil
+	}
+}
+
+func gpuCapabilities(capabilities []string) []string {
+	for _, capability := range capabilities {
+		if capability == "gpu" {
+			return capabilities
+		}
+	}
+	return append([]string{"gpu"}, capabilities...)
+}
@@ -0,0 +1,273 @@
+// Package httpapi exposes the operations of the containers package as a small REST API that can be embedded in a service
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Options ~ Options of the handler. Every request has to carry Token as "Authorization: Bearer <token>", an empty token rejects every
+// request. ReadOnly rejects start, stop and exec
+type Options struct {
+	Token    string
+	ReadOnly bool
+}
+
+// Container ~ A container as listed by the API
+type Container struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	State   string            `json:"state"`
+	Status  string            `json:"status"`
+	Created time.Time         `json:"created"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// ExecRequest ~ The body of an exec request
+type ExecRequest struct {
+	Cmd        []string `json:"cmd"`
+	Env        []string `json:"env,omitempty"`
+	User       string   `json:"user,omitempty"`
+	WorkingDir string   `json:"workingDir,omitempty"`
+}
+
+// ExecResponse ~ The outcome of an exec request
+type ExecResponse struct {
+	ExitCode   int    `json:"exitCode"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// errorResponse ~ The body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handler ~ Routes the requests of the API
+type handler struct {
+	opts Options
+}
+
+// NewHandler ~ Returns a handler serving, relative to where it is mounted (see http.StripPrefix):
+//
+//	GET  /containers                 lists containers, ?all=true includes stopped ones
+//	POST /containers/{id}/start      starts a container
+//	POST /containers/{id}/stop       stops a container
+//	GET  /containers/{id}/logs       writes the logs as text, ?tail=N limits them and ?follow=true streams them
+//	POST /containers/{id}/exec       runs an ExecRequest and answers with an ExecResponse
+//
+// Failures are answered with a JSON body holding the error
+func NewHandler(opts Options) http.Handler {
+	return &handler{opts: opts}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "[ERR:] [HTTPAPI] => MISSING OR INVALID TOKEN")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "containers":
+		if allowMethod(w, r, http.MethodGet) {
+			h.list(w, r)
+		}
+	case len(parts) == 3 && parts[0] == "containers" && parts[1] != "":
+		id, action := parts[1], parts[2]
+		switch action {
+		case "start", "stop", "exec":
+			if !allowMethod(w, r, http.MethodPost) {
+				return
+			}
+			if h.opts.ReadOnly {
+				writeError(w, http.StatusForbidden, "[ERR:] [HTTPAPI] => THE API IS READ ONLY")
+				return
+			}
+		case "logs":
+			if !allowMethod(w, r, http.MethodGet) {
+				return
+			}
+		default:
+			writeError(w, http.StatusNotFound, "[ERR:] [HTTPAPI] => NO ROUTE FOR "+r.URL.Path)
+			return
+		}
+		h.containerAction(w, r, id, action)
+	default:
+		writeError(w, http.StatusNotFound, "[ERR:] [HTTPAPI] => NO ROUTE FOR "+r.URL.Path)
+	}
+}
+
+// authorized compares the bearer token in constant time
+func (h *handler) authorized(r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && h.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.Token)) == 1
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	summaries, err := containers.DockerClient.ContainerList(r.Context(), container.ListOptions{All: r.URL.Query().Get("all") == "true"})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "[ERR:] [DOCKER] => FAILED TO LIST CONTAINERS => "+err.Error())
+		return
+	}
+	list := make([]Container, 0, len(summaries))
+	for _, summary := range summaries {
+		name := ""
+		if len(summary.Names) > 0 {
+			name = strings.TrimPrefix(summary.Names[0], "/")
+		}
+		list = append(list, Container{
+			ID:      summary.ID,
+			Name:    name,
+			Image:   summary.Image,
+			State:   summary.State,
+			Status:  summary.Status,
+			Created: time.Unix(summary.Created, 0).UTC(),
+			Labels:  summary.Labels,
+		})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// containerAction resolves the container first, so unknown containers are answered with 404 whatever the action
+func (h *handler) containerAction(w http.ResponseWriter, r *http.Request, id string, action string) {
+	containerJSON, err := containers.DockerClient.ContainerInspect(r.Context(), id)
+	if client.IsErrNotFound(err) {
+		writeError(w, http.StatusNotFound, "[ERR:] [HTTPAPI] => NO SUCH CONTAINER "+id)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: "+id+" => "+err.Error())
+		return
+	}
+	id = containerJSON.ID
+
+	switch action {
+	case "start":
+		if err := containers.StartContainerContext(r.Context(), container.CreateResponse{ID: id}); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "stop":
+		if err := containers.StopContainerContext(r.Context(), id); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case "exec":
+		h.exec(w, r, id)
+	case "logs":
+		h.logs(w, r, id, containerJSON.Config != nil && containerJSON.Config.Tty)
+	}
+}
+
+func (h *handler) exec(w http.ResponseWriter, r *http.Request, id string) {
+	var request ExecRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "[ERR:] [HTTPAPI] => INVALID EXEC REQUEST => "+err.Error())
+		return
+	}
+	if len(request.Cmd) == 0 {
+		writeError(w, http.StatusBadRequest, "[ERR:] [HTTPAPI] => INVALID EXEC REQUEST => cmd IS REQUIRED")
+		return
+	}
+
+	result, err := containers.ExecWithResult(r.Context(), id, request.Cmd, containers.ExecOptions{
+		User:       request.User,
+		WorkingDir: request.WorkingDir,
+		Env:        request.Env,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ExecResponse{
+		ExitCode:   result.ExitCode,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		DurationMs: result.Duration.Milliseconds(),
+	})
+}
+
+// logs writes the logs of a container as plain text, flushing every line while following
+func (h *handler) logs(w http.ResponseWriter, r *http.Request, id string, tty bool) {
+	query := r.URL.Query()
+	tail := query.Get("tail")
+	if tail == "" {
+		tail = "all"
+	}
+	opts := container.LogsOptions{ShowStdout: true, ShowStderr: true, Tail: tail}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if query.Get("follow") == "true" {
+		flusher, _ := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		// The status is already sent, a failure can only end the stream
+		containers.StreamContainerLogs(r.Context(), id, opts, func(line containers.LogLine) {
+			w.Write([]byte(line.Message + "\n"))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		})
+		return
+	}
+
+	logs, err := containers.DockerClient.ContainerLogs(r.Context(), id, opts)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "[ERR:] [DOCKER] => FAILED TO READ LOGS OF CONTAINER WITH ID: "+id+" => "+err.Error())
+		return
+	}
+	defer logs.Close()
+	w.WriteHeader(http.StatusOK)
+	writeLogs(w, logs, tty)
+}
+
+// writeLogs copies a log stream to w, merging stdout and stderr. TTY containers do not multiplex their output
+func writeLogs(w io.Writer, logs io.Reader, tty bool) error {
+	if tty {
+		_, err := io.Copy(w, logs)
+		return err
+	}
+	_, err := stdcopy.StdCopy(w, w, logs)
+	return err
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "[ERR:] [HTTPAPI] => METHOD "+r.Method+" NOT ALLOWED, USE "+method)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
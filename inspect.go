@@ -0,0 +1,99 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// InspectContainer ~ Returns a simplified view of a container by name or ID
+func InspectContainer(ctx context.Context, containerID string) (ContainerInfo, error) {
+	containerJSON, err := retryResult(ctx, func() (types.ContainerJSON, error) {
+		return inspectContainer(ctx, containerID)
+	})
+	if err != nil {
+		return ContainerInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return toContainerInfo(containerJSON), nil
+}
+
+func toContainerInfo(containerJSON types.ContainerJSON) ContainerInfo {
+	var info ContainerInfo
+	if base := containerJSON.ContainerJSONBase; base != nil {
+		info.ID = base.ID
+		info.Name = strings.TrimPrefix(base.Name, "/")
+		info.ImageID = base.Image
+		info.RestartCount = base.RestartCount
+		info.CreatedAt = parseDockerTime(base.Created)
+		if state := base.State; state != nil {
+			info.State = state.Status
+			info.ExitCode = int64(state.ExitCode)
+			info.StartedAt = parseDockerTime(state.StartedAt)
+			info.FinishedAt = parseDockerTime(state.FinishedAt)
+		}
+	}
+	if config := containerJSON.Config; config != nil {
+		info.Image = config.Image
+		info.Env = config.Env
+		info.Labels = config.Labels
+	}
+	info.IPs = containerIPs(containerJSON)
+	for _, m := range containerJSON.Mounts {
+		info.Mounts = append(info.Mounts, MountInfo{
+			Type:        string(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	return info
+}
+
+// GetContainerIPs ~ Returns the addresses of a container on each of its networks, by network name, for reaching it from other
+// containers on a user-defined network without published ports. Networks without addresses such as host are left out
+func GetContainerIPs(ctx context.Context, containerID string) (map[string]NetworkAddresses, error) {
+	containerJSON, err := retryResult(ctx, func() (types.ContainerJSON, error) {
+		return inspectContainer(ctx, containerID)
+	})
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	addresses := map[string]NetworkAddresses{}
+	if containerJSON.NetworkSettings == nil {
+		return addresses, nil
+	}
+	for name, endpoint := range containerJSON.NetworkSettings.Networks {
+		if endpoint != nil && (endpoint.IPAddress != "" || endpoint.GlobalIPv6Address != "") {
+			addresses[name] = NetworkAddresses{IPv4: endpoint.IPAddress, IPv6: endpoint.GlobalIPv6Address}
+		}
+	}
+	return addresses, nil
+}
+
+// containerIPs maps the networks of a container to its IPv4 address on them, leaving out networks without one such as host
+func containerIPs(containerJSON types.ContainerJSON) map[string]string {
+	ips := map[string]string{}
+	if containerJSON.NetworkSettings == nil {
+		return ips
+	}
+	for name, endpoint := range containerJSON.NetworkSettings.Networks {
+		if endpoint != nil && endpoint.IPAddress != "" {
+			ips[name] = endpoint.IPAddress
+		}
+	}
+	return ips
+}
+
+// parseDockerTime parses the RFC3339 times of inspect output, whose unset times read 0001-01-01T00:00:00Z
+func parseDockerTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
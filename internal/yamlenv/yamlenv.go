@@ -0,0 +1,31 @@
+// Package yamlenv expands variables inside parsed YAML documents for the spec and compose loaders
+package yamlenv
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Expand ~ Runs expand over every scalar under node, with $$ standing for a literal $. Values are expanded after parsing, so quotes,
+// colons or newlines in them cannot change the structure of the document. Unquoted values are typed again after the expansion, so
+// "memory: ${MEMORY}" still decodes as a number
+func Expand(node *yaml.Node, expand func(string) string) {
+	if node.Kind != yaml.ScalarNode {
+		for _, child := range node.Content {
+			Expand(child, expand)
+		}
+		return
+	}
+	if !strings.Contains(node.Value, "$") {
+		return
+	}
+	parts := strings.Split(node.Value, "$$")
+	for i, part := range parts {
+		parts[i] = expand(part)
+	}
+	node.Value = strings.Join(parts, "$")
+	if node.Style == 0 && node.Tag == "!!str" {
+		node.Tag = ""
+	}
+}
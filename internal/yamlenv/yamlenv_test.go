@@ -0,0 +1,44 @@
+package yamlenv
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"PORT": "8080", "QUOTE": `a": b`, "NAME": "web"}
+	expand := func(s string) string {
+		return os.Expand(s, func(key string) string { return env[key] })
+	}
+	tests := []struct {
+		name string
+		doc  string
+		want interface{}
+	}{
+		{"number retyped", "port: ${PORT}", map[string]interface{}{"port": 8080}},
+		{"quoted stays string", `port: "${PORT}"`, map[string]interface{}{"port": "8080"}},
+		{"value cannot change structure", "value: ${QUOTE}", map[string]interface{}{"value": `a": b`}},
+		{"escaped dollar", "cmd: echo $$HOME", map[string]interface{}{"cmd": "echo $HOME"}},
+		{"escaped dollar before variable", "cmd: $$$NAME", map[string]interface{}{"cmd": "$web"}},
+		{"sequence", "list: [$NAME, $$NAME]", map[string]interface{}{"list": []interface{}{"web", "$NAME"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.doc), &node); err != nil {
+				t.Fatal(err)
+			}
+			Expand(&node, expand)
+			var got interface{}
+			if err := node.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+// Package kube converts container configs of the containers package into Kubernetes Pod and Deployment manifests, to ease moving
+// workloads to Kubernetes. The manifests are a starting point: Services, PersistentVolumeClaims and Secrets are not generated
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"gopkg.in/yaml.v3"
+)
+
+// Options ~ Options of a generated manifest. Labels select the pods and default to app: <name>. Replicas only applies to
+// Deployments and defaults to 1. An empty Name is derived from the container name
+type Options struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Replicas  int32
+}
+
+// Pod ~ Renders a container config as a Pod manifest
+func Pod(config *containers.ContainerCreateConfig, opts Options) ([]byte, error) {
+	spec, meta, err := podSpec(config, &opts)
+	if err != nil {
+		return nil, err
+	}
+	meta.Labels = opts.Labels
+	return render(manifest{APIVersion: "v1", Kind: "Pod", Metadata: meta, Spec: spec})
+}
+
+// Deployment ~ Renders a container config as a Deployment manifest. The restart policy of a Deployment is always Always
+func Deployment(config *containers.ContainerCreateConfig, opts Options) ([]byte, error) {
+	spec, meta, err := podSpec(config, &opts)
+	if err != nil {
+		return nil, err
+	}
+	spec.RestartPolicy = ""
+	replicas := opts.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+	return render(manifest{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   meta,
+		Spec: deploymentSpec{
+			Replicas: replicas,
+			Selector: labelSelector{MatchLabels: opts.Labels},
+			Template: podTemplate{Metadata: metadata{Labels: opts.Labels, Annotations: meta.Annotations}, Spec: spec},
+		},
+	})
+}
+
+// PodFromContainer ~ Renders an existing container as a Pod manifest
+func PodFromContainer(ctx context.Context, containerID string, opts Options) ([]byte, error) {
+	config, err := containers.ConfigFromContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return Pod(config, opts)
+}
+
+// DeploymentFromContainer ~ Renders an existing container as a Deployment manifest
+func DeploymentFromContainer(ctx context.Context, containerID string, opts Options) ([]byte, error) {
+	config, err := containers.ConfigFromContainer(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return Deployment(config, opts)
+}
+
+type manifest struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   metadata    `yaml:"metadata"`
+	Spec       interface{} `yaml:"spec"`
+}
+
+type metadata struct {
+	Name        string            `yaml:"name,omitempty"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type deploymentSpec struct {
+	Replicas int32         `yaml:"replicas"`
+	Selector labelSelector `yaml:"selector"`
+	Template podTemplate   `yaml:"template"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type podTemplate struct {
+	Metadata metadata `yaml:"metadata"`
+	Spec     podSpecs `yaml:"spec"`
+}
+
+type podSpecs struct {
+	Hostname      string          `yaml:"hostname,omitempty"`
+	RestartPolicy string          `yaml:"restartPolicy,omitempty"`
+	HostNetwork   bool            `yaml:"hostNetwork,omitempty"`
+	Containers    []containerSpec `yaml:"containers"`
+	Volumes       []volumeSpec    `yaml:"volumes,omitempty"`
+}
+
+type containerSpec struct {
+	Name            string           `yaml:"name"`
+	Image           string           `yaml:"image"`
+	Command         []string         `yaml:"command,omitempty"`
+	Args            []string         `yaml:"args,omitempty"`
+	WorkingDir      string           `yaml:"workingDir,omitempty"`
+	Env             []envVar         `yaml:"env,omitempty"`
+	Ports           []containerPort  `yaml:"ports,omitempty"`
+	Resources       *resources       `yaml:"resources,omitempty"`
+	VolumeMounts    []volumeMount    `yaml:"volumeMounts,omitempty"`
+	LivenessProbe   *probe           `yaml:"livenessProbe,omitempty"`
+	SecurityContext *securityContext `yaml:"securityContext,omitempty"`
+	TTY             bool             `yaml:"tty,omitempty"`
+	Stdin           bool             `yaml:"stdin,omitempty"`
+}
+
+type envVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type containerPort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+type resources struct {
+	Limits map[string]string `yaml:"limits,omitempty"`
+}
+
+type volumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type volumeSpec struct {
+	Name                  string          `yaml:"name"`
+	HostPath              *hostPathSource `yaml:"hostPath,omitempty"`
+	PersistentVolumeClaim *claimSource    `yaml:"persistentVolumeClaim,omitempty"`
+	EmptyDir              *emptyDirSource `yaml:"emptyDir,omitempty"`
+}
+
+type hostPathSource struct {
+	Path string `yaml:"path"`
+}
+
+type claimSource struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type emptyDirSource struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+type probe struct {
+	Exec                execAction `yaml:"exec"`
+	InitialDelaySeconds int        `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int        `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int        `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int        `yaml:"failureThreshold,omitempty"`
+}
+
+type execAction struct {
+	Command []string `yaml:"command"`
+}
+
+type securityContext struct {
+	RunAsUser              *int64        `yaml:"runAsUser,omitempty"`
+	RunAsGroup             *int64        `yaml:"runAsGroup,omitempty"`
+	Privileged             bool          `yaml:"privileged,omitempty"`
+	ReadOnlyRootFilesystem bool          `yaml:"readOnlyRootFilesystem,omitempty"`
+	Capabilities           *capabilities `yaml:"capabilities,omitempty"`
+}
+
+type capabilities struct {
+	Add  []string `yaml:"add,omitempty"`
+	Drop []string `yaml:"drop,omitempty"`
+}
+
+// podSpec translates a container config into the spec of a single container pod and fills the defaults of opts
+func podSpec(config *containers.ContainerCreateConfig, opts *Options) (podSpecs, metadata, error) {
+	if config == nil || config.Config == nil || config.Config.Image == "" {
+		return podSpecs{}, metadata{}, errors.New("[ERR:] [KUBE] => THE CONTAINER CONFIG HAS NO IMAGE")
+	}
+	if opts.Name == "" {
+		opts.Name = config.Name
+	}
+	name := dnsName(opts.Name)
+	if name == "" {
+		return podSpecs{}, metadata{}, errors.New("[ERR:] [KUBE] => A NAME IS NEEDED FOR CONTAINERS WITHOUT ONE")
+	}
+	if len(opts.Labels) == 0 {
+		opts.Labels = map[string]string{"app": name}
+	}
+
+	cfg := config.Config
+	c := containerSpec{
+		Name:       name,
+		Image:      cfg.Image,
+		Command:    cfg.Entrypoint,
+		Args:       cfg.Cmd,
+		WorkingDir: cfg.WorkingDir,
+		TTY:        cfg.Tty,
+		Stdin:      cfg.OpenStdin,
+	}
+	for _, variable := range cfg.Env {
+		key, value, _ := strings.Cut(variable, "=")
+		c.Env = append(c.Env, envVar{Name: key, Value: value})
+	}
+	c.Ports = ports(cfg.ExposedPorts, config)
+	c.LivenessProbe = livenessProbe(cfg)
+	c.SecurityContext = security(config)
+
+	spec := podSpecs{Hostname: dnsName(cfg.Hostname), RestartPolicy: "Always"}
+	if hc := config.HostConfig; hc != nil {
+		limits := map[string]string{}
+		if hc.Memory > 0 {
+			limits["memory"] = strconv.FormatInt(hc.Memory, 10)
+		}
+		if hc.NanoCPUs > 0 {
+			limits["cpu"] = strconv.FormatInt(hc.NanoCPUs/1e6, 10) + "m"
+		}
+		if len(limits) > 0 {
+			c.Resources = &resources{Limits: limits}
+		}
+		switch hc.RestartPolicy.Name {
+		case "no":
+			spec.RestartPolicy = "Never"
+		case "on-failure":
+			spec.RestartPolicy = "OnFailure"
+		}
+		spec.HostNetwork = hc.NetworkMode.IsHost()
+		c.VolumeMounts, spec.Volumes = volumes(hc.Mounts, hc.Binds)
+	}
+	spec.Containers = []containerSpec{c}
+
+	meta := metadata{Name: name, Namespace: opts.Namespace}
+	if len(cfg.Labels) > 0 {
+		// Docker labels rarely meet the rules of Kubernetes label values, annotations take anything
+		meta.Annotations = cfg.Labels
+	}
+	return spec, meta, nil
+}
+
+// ports lists the exposed and published container ports. Host ports are dropped, a Service is the Kubernetes way to publish them
+func ports(exposed nat.PortSet, config *containers.ContainerCreateConfig) []containerPort {
+	set := map[nat.Port]struct{}{}
+	for port := range exposed {
+		set[port] = struct{}{}
+	}
+	if config.HostConfig != nil {
+		for port := range config.HostConfig.PortBindings {
+			set[port] = struct{}{}
+		}
+	}
+	var list []containerPort
+	for port := range set {
+		list = append(list, containerPort{ContainerPort: port.Int(), Protocol: strings.ToUpper(port.Proto())})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ContainerPort != list[j].ContainerPort {
+			return list[i].ContainerPort < list[j].ContainerPort
+		}
+		return list[i].Protocol < list[j].Protocol
+	})
+	return list
+}
+
+// volumes maps bind mounts to hostPath volumes, named volumes to claims of the same name and tmpfs mounts to memory backed emptyDirs
+func volumes(mounts []mount.Mount, binds []string) ([]volumeMount, []volumeSpec) {
+	var volumeMounts []volumeMount
+	var specs []volumeSpec
+	add := func(source volumeSpec, target string, readOnly bool) {
+		source.Name = "volume-" + strconv.Itoa(len(specs))
+		specs = append(specs, source)
+		volumeMounts = append(volumeMounts, volumeMount{Name: source.Name, MountPath: target, ReadOnly: readOnly})
+	}
+
+	for _, m := range mounts {
+		switch m.Type {
+		case mount.TypeBind:
+			add(volumeSpec{HostPath: &hostPathSource{Path: m.Source}}, m.Target, m.ReadOnly)
+		case mount.TypeVolume:
+			add(volumeSpec{PersistentVolumeClaim: &claimSource{ClaimName: dnsName(m.Source)}}, m.Target, m.ReadOnly)
+		case mount.TypeTmpfs:
+			source := &emptyDirSource{Medium: "Memory"}
+			if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+				source.SizeLimit = strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10)
+			}
+			add(volumeSpec{EmptyDir: source}, m.Target, false)
+		}
+	}
+	for _, bind := range binds {
+		source, target, readOnly, err := containers.ParseBind(bind)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(source, "/") {
+			add(volumeSpec{HostPath: &hostPathSource{Path: source}}, target, readOnly)
+		} else {
+			add(volumeSpec{PersistentVolumeClaim: &claimSource{ClaimName: dnsName(source)}}, target, readOnly)
+		}
+	}
+	return volumeMounts, specs
+}
+
+// livenessProbe turns a healthcheck into an exec probe. Healthchecks that are disabled or inherited from the image give no probe
+func livenessProbe(cfg *container.Config) *probe {
+	hc := cfg.Healthcheck
+	if hc == nil || len(hc.Test) < 2 {
+		return nil
+	}
+	var command []string
+	switch hc.Test[0] {
+	case "CMD":
+		command = hc.Test[1:]
+	case "CMD-SHELL":
+		command = []string{"/bin/sh", "-c", hc.Test[1]}
+	default:
+		return nil
+	}
+	return &probe{
+		Exec:                execAction{Command: command},
+		InitialDelaySeconds: seconds(hc.StartPeriod),
+		PeriodSeconds:       seconds(hc.Interval),
+		TimeoutSeconds:      seconds(hc.Timeout),
+		FailureThreshold:    hc.Retries,
+	}
+}
+
+// security maps the user, privileges, capabilities and read-only root of a config
+func security(config *containers.ContainerCreateConfig) *securityContext {
+	sc := &securityContext{}
+	set := false
+	if user := config.Config.User; user != "" {
+		uid, gid, hasGroup := strings.Cut(user, ":")
+		if id, err := strconv.ParseInt(uid, 10, 64); err == nil {
+			sc.RunAsUser = &id
+			set = true
+		}
+		if group, err := strconv.ParseInt(gid, 10, 64); hasGroup && err == nil {
+			sc.RunAsGroup = &group
+			set = true
+		}
+	}
+	if hc := config.HostConfig; hc != nil {
+		sc.Privileged = hc.Privileged
+		sc.ReadOnlyRootFilesystem = hc.ReadonlyRootfs
+		if len(hc.CapAdd) > 0 || len(hc.CapDrop) > 0 {
+			sc.Capabilities = &capabilities{Add: capabilityNames(hc.CapAdd), Drop: capabilityNames(hc.CapDrop)}
+		}
+		set = set || hc.Privileged || hc.ReadonlyRootfs || sc.Capabilities != nil
+	}
+	if !set {
+		return nil
+	}
+	return sc
+}
+
+// capabilityNames strips the CAP_ prefix Docker accepts but Kubernetes does not
+func capabilityNames(caps []string) []string {
+	names := make([]string, 0, len(caps))
+	for _, capability := range caps {
+		names = append(names, strings.TrimPrefix(strings.ToUpper(capability), "CAP_"))
+	}
+	return names
+}
+
+// dnsName turns a container name into a valid DNS-1123 label, the format Kubernetes object names take
+func dnsName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, strings.TrimPrefix(name, "/"))
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.Trim(name, "-")
+}
+
+func seconds(d time.Duration) int {
+	return int(d / time.Second)
+}
+
+func render(m manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return nil, errors.New("[ERR:] [KUBE] => FAILED TO RENDER " + m.Kind + " MANIFEST => " + err.Error())
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, errors.New("[ERR:] [KUBE] => FAILED TO RENDER " + m.Kind + " MANIFEST => " + err.Error())
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// ManagedByLabel ~ The label stamped on every container, image, network and volume created while ownership labelling is enabled
+const ManagedByLabel = "com.github.g-makroglou.containers.managed-by"
+
+// managedBy is the owner stamped on created resources, empty when labelling is disabled
+var managedBy string
+
+// EnableManagedLabel ~ Stamps every container, image, network and volume created from now on with ManagedByLabel=owner, see CleanupManaged
+func EnableManagedLabel(owner string) {
+	managedBy = owner
+}
+
+// DisableManagedLabel ~ Stops stamping created resources with ManagedByLabel
+func DisableManagedLabel() {
+	managedBy = ""
+}
+
+// withManagedLabel returns a copy of labels carrying the managed-by label, or labels itself when labelling is disabled
+func withManagedLabel(labels map[string]string) map[string]string {
+	if managedBy == "" {
+		return labels
+	}
+	stamped := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		stamped[key] = value
+	}
+	stamped[ManagedByLabel] = managedBy
+	return stamped
+}
+
+// CleanupManaged ~ Force removes every container, network, volume and image labelled as managed by owner (the current one when empty).
+// Keeps going on failures and reports all of them at the end
+func CleanupManaged(ctx context.Context, owner string) error {
+	if owner == "" {
+		owner = managedBy
+	}
+	if owner == "" {
+		return errors.New("[ERR:] [DOCKER] => NO OWNER GIVEN AND MANAGED LABELLING IS DISABLED")
+	}
+	labelFilter := filters.NewArgs(filters.Arg("label", ManagedByLabel+"="+owner))
+	var failures []string
+
+	containerList, err := DockerClient.ContainerList(ctx, container.ListOptions{All: true, Filters: labelFilter})
+	if err != nil {
+		failures = append(failures, "LIST CONTAINERS => "+err.Error())
+	}
+	for _, c := range containerList {
+		if err := PurgeContainerContext(ctx, c.ID); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	networkList, err := DockerClient.NetworkList(ctx, network.ListOptions{Filters: labelFilter})
+	if err != nil {
+		failures = append(failures, "LIST NETWORKS => "+err.Error())
+	}
+	for _, n := range networkList {
+		if err := RemoveNetwork(ctx, n.ID); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	volumeList, err := DockerClient.VolumeList(ctx, volume.ListOptions{Filters: labelFilter})
+	if err != nil {
+		failures = append(failures, "LIST VOLUMES => "+err.Error())
+	}
+	for _, v := range volumeList.Volumes {
+		if err := RemoveVolume(ctx, v.Name, true); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	imageList, err := DockerClient.ImageList(ctx, image.ListOptions{All: true, Filters: labelFilter})
+	if err != nil {
+		failures = append(failures, "LIST IMAGES => "+err.Error())
+	}
+	for _, img := range imageList {
+		if dryRun(OpDeleteImage, img.ID, img.ID) {
+			continue
+		}
+		opCtx, done := beginOperation(ctx, OpDeleteImage, img.ID, img.ID)
+		_, err := DockerClient.ImageRemove(opCtx, img.ID, image.RemoveOptions{Force: true, PruneChildren: true})
+		done(err)
+		if err != nil && !client.IsErrNotFound(err) {
+			failures = append(failures, "REMOVE IMAGE "+img.ID+" => "+err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO CLEAN UP RESOURCES MANAGED BY " + owner + " => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// addLabelFilters adds a label filter per entry. An empty value matches on the key only
+func addLabelFilters(args filters.Args, labels map[string]string) {
+	for key, value := range labels {
+		if value == "" {
+			args.Add("label", key)
+		} else {
+			args.Add("label", key+"="+value)
+		}
+	}
+}
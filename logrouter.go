@@ -0,0 +1,98 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logColors ~ The ANSI colors prefixes cycle through, as in `docker compose logs`
+var logColors = []string{"36", "33", "32", "35", "34", "31", "96", "93", "92", "95", "94", "91"}
+
+// LogRouter ~ Follows the logs of many containers at once and writes their lines, prefixed with the container name, to a set of
+// writers, like the aggregated output of `docker compose logs -f`
+type LogRouter struct {
+	opts    LogRouterOptions
+	mu      sync.Mutex
+	writers []io.Writer
+	failed  error
+}
+
+// NewLogRouter ~ Creates a log router writing to opts.Writers, see LogRouter.Run
+func NewLogRouter(opts LogRouterOptions) *LogRouter {
+	return &LogRouter{opts: opts, writers: append([]io.Writer(nil), opts.Writers...)}
+}
+
+// Run ~ Follows the logs of the containers until the context is cancelled or every one of them stops. A writer that fails is
+// dropped and its error returned once the streams ended, failed streams are returned as a *BatchError
+func (r *LogRouter) Run(ctx context.Context, containerIDs ...string) error {
+	names := make([]string, len(containerIDs))
+	width := 0
+	for i, containerID := range containerIDs {
+		containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		names[i] = strings.TrimPrefix(containerJSON.Name, "/")
+		width = max(width, len(names[i]))
+	}
+
+	var wg sync.WaitGroup
+	var failuresMu sync.Mutex
+	failures := map[string]error{}
+	for i, containerID := range containerIDs {
+		prefix := names[i] + strings.Repeat(" ", width-len(names[i])) + " | "
+		if r.opts.Color {
+			prefix = "\x1b[" + logColors[i%len(logColors)] + "m" + prefix + "\x1b[0m"
+		}
+		wg.Add(1)
+		go func(containerID string, name string, prefix string) {
+			defer wg.Done()
+			err := StreamContainerLogs(ctx, containerID, r.opts.Logs, func(line LogLine) {
+				r.write(prefix, line)
+			})
+			if err != nil {
+				failuresMu.Lock()
+				failures[name] = err
+				failuresMu.Unlock()
+			}
+		}(containerID, names[i], prefix)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &BatchError{Operation: "ROUTE LOGS", Total: len(containerIDs), Failures: failures}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failed
+}
+
+// write writes a line to every writer, one line at a time so lines of different containers never interleave
+func (r *LogRouter) write(prefix string, line LogLine) {
+	var b strings.Builder
+	b.WriteString(prefix)
+	if r.opts.Timestamps && !line.Timestamp.IsZero() {
+		b.WriteString(line.Timestamp.Format(time.RFC3339Nano) + " ")
+	}
+	b.WriteString(line.Message)
+	b.WriteByte('\n')
+	out := []byte(b.String())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.writers[:0]
+	for _, w := range r.writers {
+		if _, err := w.Write(out); err != nil {
+			if r.failed == nil {
+				r.failed = errors.New("[ERR:] [LOGS] => DROPPED A LOG WRITER => " + err.Error())
+			}
+			continue
+		}
+		kept = append(kept, w)
+	}
+	r.writers = kept
+}
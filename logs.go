@@ -0,0 +1,189 @@
+package containers
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// StreamContainerLogs ~ Follows the logs of a container and calls fn for every line until the context is cancelled or the container stops
+func StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(LogLine)) error {
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	if !opts.ShowStdout && !opts.ShowStderr {
+		opts.ShowStdout = true
+		opts.ShowStderr = true
+	}
+	opts.Follow = true
+	opts.Timestamps = true
+
+	logs, err := DockerClient.ContainerLogs(ctx, containerID, opts)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO FOLLOW LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer logs.Close()
+
+	parser := NewLogParser(logs, containerID, containerJSON.Config != nil && containerJSON.Config.Tty, true)
+	for {
+		record, err := parser.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.New("[ERR:] [DOCKER] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		fn(LogLine{Timestamp: record.Timestamp, Stream: record.Stream, Message: record.Message})
+	}
+}
+
+// Stream types of the multiplexed log framing, see github.com/docker/docker/pkg/stdcopy
+const (
+	logFrameStdout = 1
+	logFrameStderr = 2
+	logFrameSystem = 3
+)
+
+// maxLogFrameSize caps the payload of a log frame. The daemon splits messages into frames of 16KB, a length far beyond that means
+// the stream is corrupt and must not be allocated
+const maxLogFrameSize = 1 << 20
+
+// LogParser ~ Turns the raw output of ContainerLogs into records. Non-TTY output is read frame by frame, so lines the daemon split
+// into 16KB messages are joined back into one record, and a line cut off at the end of the stream is still returned
+type LogParser struct {
+	containerID string
+	tty         bool
+	timestamps  bool
+	raw         *bufio.Reader
+	pending     map[string]*LogRecord
+	ready       []LogRecord
+	done        bool
+}
+
+// NewLogParser ~ Creates a parser for the log stream r of a container. tty is whether the container runs with a TTY, whose output
+// is not multiplexed and arrives as stdout, and timestamps whether the logs were requested with Timestamps
+func NewLogParser(r io.Reader, containerID string, tty bool, timestamps bool) *LogParser {
+	return &LogParser{
+		containerID: containerID,
+		tty:         tty,
+		timestamps:  timestamps,
+		raw:         bufio.NewReaderSize(r, 32*1024),
+		pending:     map[string]*LogRecord{},
+	}
+}
+
+// Next ~ Returns the next record, or io.EOF once the stream ended and every partial line was returned
+func (p *LogParser) Next() (LogRecord, error) {
+	for len(p.ready) == 0 {
+		if p.done {
+			return LogRecord{}, io.EOF
+		}
+		var err error
+		if p.tty {
+			err = p.readLine()
+		} else {
+			err = p.readFrame()
+		}
+		if err == io.EOF {
+			p.done = true
+			p.flush(LogStreamStdout)
+			p.flush(LogStreamStderr)
+		} else if err != nil {
+			return LogRecord{}, err
+		}
+	}
+	record := p.ready[0]
+	p.ready = p.ready[1:]
+	return record, nil
+}
+
+// readFrame reads one frame of the multiplexed stream. Every frame is a message of the daemon, which ends with a newline unless
+// the daemon split a long line and the next message of the stream continues it
+func (p *LogParser) readFrame() error {
+	var header [8]byte
+	if _, err := io.ReadFull(p.raw, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("TRUNCATED LOG FRAME HEADER")
+		}
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[4:])
+	if size > maxLogFrameSize {
+		return errors.New("LOG FRAME OF " + strconv.FormatUint(uint64(size), 10) + " BYTES EXCEEDS THE LIMIT OF " + strconv.Itoa(maxLogFrameSize) + " BYTES, THE STREAM IS CORRUPT")
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(p.raw, payload); err != nil {
+		return errors.New("TRUNCATED LOG FRAME => " + err.Error())
+	}
+
+	switch header[0] {
+	case logFrameStdout:
+		p.consume(LogStreamStdout, string(payload))
+	case logFrameStderr:
+		p.consume(LogStreamStderr, string(payload))
+	case logFrameSystem:
+		return errors.New("DAEMON ERROR => " + string(payload))
+	}
+	return nil
+}
+
+// readLine reads the unframed output of a TTY container up to the next newline. The boundaries of split messages are lost in it,
+// so the timestamps of the later pieces of a long line stay in its message
+func (p *LogParser) readLine() error {
+	line, err := p.raw.ReadString('\n')
+	if line != "" {
+		p.consume(LogStreamStdout, line)
+	}
+	return err
+}
+
+// consume adds the text of a message to the record pending on its stream and moves every completed line to ready. Messages, and
+// lines within them, start with their timestamp when timestamps were requested
+func (p *LogParser) consume(stream string, text string) {
+	for text != "" {
+		var timestamp time.Time
+		if p.timestamps {
+			if ts, rest, found := strings.Cut(text, " "); found {
+				if parsed, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+					timestamp, text = parsed, rest
+				}
+			}
+		}
+
+		record := p.pending[stream]
+		if record == nil {
+			record = &LogRecord{ContainerID: p.containerID, Stream: stream, Timestamp: timestamp}
+			p.pending[stream] = record
+		}
+		line, rest, complete := strings.Cut(text, "\n")
+		record.Message += line
+		if !complete {
+			return
+		}
+		p.flush(stream)
+		text = rest
+	}
+}
+
+// flush moves the pending record of a stream to ready
+func (p *LogParser) flush(stream string) {
+	record := p.pending[stream]
+	if record == nil {
+		return
+	}
+	delete(p.pending, stream)
+	record.Message = strings.TrimSuffix(record.Message, "\r")
+	p.ready = append(p.ready, *record)
+}
@@ -0,0 +1,125 @@
+package containers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logFrame encodes a message of the multiplexed log stream
+func logFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func readLogRecords(t *testing.T, parser *LogParser) ([]LogRecord, error) {
+	t.Helper()
+	var records []LogRecord
+	for {
+		record, err := parser.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestLogParserFrames(t *testing.T) {
+	ts1 := time.Date(2024, time.January, 10, 10, 0, 0, 0, time.UTC)
+	ts2 := ts1.Add(time.Second)
+	tests := []struct {
+		name       string
+		frames     [][]byte
+		timestamps bool
+		want       []LogRecord
+	}{
+		{
+			name:   "one line per frame",
+			frames: [][]byte{logFrame(logFrameStdout, "a\n"), logFrame(logFrameStderr, "b\r\n")},
+			want:   []LogRecord{{ContainerID: "c", Stream: LogStreamStdout, Message: "a"}, {ContainerID: "c", Stream: LogStreamStderr, Message: "b"}},
+		},
+		{
+			name:   "split line joined",
+			frames: [][]byte{logFrame(logFrameStdout, "part one, "), logFrame(logFrameStdout, "part two\n")},
+			want:   []LogRecord{{ContainerID: "c", Stream: LogStreamStdout, Message: "part one, part two"}},
+		},
+		{
+			name:   "streams joined separately",
+			frames: [][]byte{logFrame(logFrameStdout, "out "), logFrame(logFrameStderr, "err\n"), logFrame(logFrameStdout, "done\n")},
+			want:   []LogRecord{{ContainerID: "c", Stream: LogStreamStderr, Message: "err"}, {ContainerID: "c", Stream: LogStreamStdout, Message: "out done"}},
+		},
+		{
+			name:   "partial line at the end",
+			frames: [][]byte{logFrame(logFrameStdout, "a\nb")},
+			want:   []LogRecord{{ContainerID: "c", Stream: LogStreamStdout, Message: "a"}, {ContainerID: "c", Stream: LogStreamStdout, Message: "b"}},
+		},
+		{
+			name: "timestamps of the first piece kept",
+			frames: [][]byte{
+				logFrame(logFrameStdout, ts1.Format(time.RFC3339Nano)+" long "),
+				logFrame(logFrameStdout, ts2.Format(time.RFC3339Nano)+" line\n"),
+			},
+			timestamps: true,
+			want:       []LogRecord{{ContainerID: "c", Stream: LogStreamStdout, Timestamp: ts1, Message: "long line"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLogRecords(t, NewLogParser(bytes.NewReader(bytes.Join(tt.frames, nil)), "c", false, tt.timestamps))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogParserTTY(t *testing.T) {
+	got, err := readLogRecords(t, NewLogParser(strings.NewReader("a\r\nb\nc"), "c", true, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LogRecord{
+		{ContainerID: "c", Stream: LogStreamStdout, Message: "a"},
+		{ContainerID: "c", Stream: LogStreamStdout, Message: "b"},
+		{ContainerID: "c", Stream: LogStreamStdout, Message: "c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLogParserErrors(t *testing.T) {
+	oversized := make([]byte, 8)
+	oversized[0] = logFrameStdout
+	binary.BigEndian.PutUint32(oversized[4:], maxLogFrameSize+1)
+	tests := []struct {
+		name   string
+		stream []byte
+		want   string
+	}{
+		{"oversized frame", oversized, "EXCEEDS THE LIMIT"},
+		{"truncated header", []byte{logFrameStdout, 0, 0}, "TRUNCATED LOG FRAME HEADER"},
+		{"truncated payload", logFrame(logFrameStdout, "hello\n")[:10], "TRUNCATED LOG FRAME"},
+		{"daemon error", logFrame(logFrameSystem, "boom"), "DAEMON ERROR => boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readLogRecords(t, NewLogParser(bytes.NewReader(tt.stream), "c", false, false))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
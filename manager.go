@@ -0,0 +1,192 @@
+package containers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// ContainerManager ~ The operations of the package behind an interface, so code that depends on them can be unit tested with
+// a mock or fake instead of a daemon. Docker is the implementation backed by the package functions
+type ContainerManager interface {
+	BuildImage(path string, imageName string) error
+	EnsureImage(ctx context.Context, imageName string) error
+	DeleteImage(imageName string) (bool, error)
+	PruneDanglingImages() (image.PruneReport, error)
+
+	CreateContainer(config *ContainerCreateConfig) (container.CreateResponse, error)
+	StartContainer(cont container.CreateResponse) error
+	StopContainer(containerID string) error
+	RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error
+	PauseContainer(ctx context.Context, containerID string) error
+	UnpauseContainer(ctx context.Context, containerID string) error
+	KillContainer(ctx context.Context, containerID string, signal string) error
+	RenameContainer(ctx context.Context, containerID string, newName string) error
+	PurgeContainer(containerID string) error
+	InspectContainer(ctx context.Context, containerID string) (ContainerInfo, error)
+	GetContainerHealthStatus(containerID string) (HealthStatus, error)
+	GetContainerStats(ctx context.Context, containerID string) (ContainerStats, error)
+	GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error)
+	GetContainerIPs(ctx context.Context, containerID string) (map[string]NetworkAddresses, error)
+
+	Exec(containerID string, cmd []string) (string, error)
+	ExecWithResult(ctx context.Context, containerID string, cmd []string, opts ExecOptions) (ExecResult, error)
+	StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(LogLine)) error
+	CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error
+	CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error)
+
+	WaitForExit(ctx context.Context, containerID string) (int64, error)
+	WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error
+
+	CreateNetwork(ctx context.Context, name string, opts NetworkOptions) (string, error)
+	RemoveNetwork(ctx context.Context, name string) error
+	ConnectNetwork(ctx context.Context, networkName string, containerID string, opts EndpointOptions) error
+	DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error
+	InspectNetwork(ctx context.Context, name string) (NetworkInfo, error)
+
+	CreateVolume(ctx context.Context, name string, opts VolumeOptions) (string, error)
+	RemoveVolume(ctx context.Context, name string, force bool) error
+	ListVolumes(ctx context.Context, filter VolumeFilter) ([]*volume.Volume, error)
+}
+
+// DockerManager ~ The ContainerManager that calls the package functions against DockerClient
+type DockerManager struct{}
+
+// Docker ~ The ContainerManager backed by the daemon. InitializeDockerClient must have been called before it is used
+var Docker ContainerManager = DockerManager{}
+
+func (DockerManager) BuildImage(path string, imageName string) error {
+	return BuildImage(path, imageName)
+}
+
+func (DockerManager) EnsureImage(ctx context.Context, imageName string) error {
+	return EnsureImage(ctx, imageName)
+}
+
+func (DockerManager) DeleteImage(imageName string) (bool, error) {
+	return DeleteImage(imageName)
+}
+
+func (DockerManager) PruneDanglingImages() (image.PruneReport, error) {
+	return PruneDanglingImages()
+}
+
+func (DockerManager) CreateContainer(config *ContainerCreateConfig) (container.CreateResponse, error) {
+	return CreateContainer(config)
+}
+
+func (DockerManager) StartContainer(cont container.CreateResponse) error {
+	return StartContainer(cont)
+}
+
+func (DockerManager) StopContainer(containerID string) error {
+	return StopContainer(containerID)
+}
+
+func (DockerManager) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	return RestartContainer(ctx, containerID, timeout)
+}
+
+func (DockerManager) PauseContainer(ctx context.Context, containerID string) error {
+	return PauseContainer(ctx, containerID)
+}
+
+func (DockerManager) UnpauseContainer(ctx context.Context, containerID string) error {
+	return UnpauseContainer(ctx, containerID)
+}
+
+func (DockerManager) KillContainer(ctx context.Context, containerID string, signal string) error {
+	return KillContainer(ctx, containerID, signal)
+}
+
+func (DockerManager) RenameContainer(ctx context.Context, containerID string, newName string) error {
+	return RenameContainer(ctx, containerID, newName)
+}
+
+func (DockerManager) PurgeContainer(containerID string) error {
+	return PurgeContainer(containerID)
+}
+
+func (DockerManager) InspectContainer(ctx context.Context, containerID string) (ContainerInfo, error) {
+	return InspectContainer(ctx, containerID)
+}
+
+func (DockerManager) GetContainerHealthStatus(containerID string) (HealthStatus, error) {
+	return GetContainerHealthStatus(containerID)
+}
+
+func (DockerManager) GetContainerStats(ctx context.Context, containerID string) (ContainerStats, error) {
+	return GetContainerStats(ctx, containerID)
+}
+
+func (DockerManager) GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error) {
+	return GetHostPort(ctx, containerID, containerPort)
+}
+
+func (DockerManager) GetContainerIPs(ctx context.Context, containerID string) (map[string]NetworkAddresses, error) {
+	return GetContainerIPs(ctx, containerID)
+}
+
+func (DockerManager) Exec(containerID string, cmd []string) (string, error) {
+	return Exec(containerID, cmd)
+}
+
+func (DockerManager) ExecWithResult(ctx context.Context, containerID string, cmd []string, opts ExecOptions) (ExecResult, error) {
+	return ExecWithResult(ctx, containerID, cmd, opts)
+}
+
+func (DockerManager) StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(LogLine)) error {
+	return StreamContainerLogs(ctx, containerID, opts, fn)
+}
+
+func (DockerManager) CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error {
+	return CopyToContainer(ctx, containerID, destPath, src)
+}
+
+func (DockerManager) CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	return CopyFromContainerStream(ctx, containerID, srcPath)
+}
+
+func (DockerManager) WaitForExit(ctx context.Context, containerID string) (int64, error) {
+	return WaitForExit(ctx, containerID)
+}
+
+func (DockerManager) WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	return WaitForHealthy(ctx, containerID, timeout, interval)
+}
+
+func (DockerManager) CreateNetwork(ctx context.Context, name string, opts NetworkOptions) (string, error) {
+	return CreateNetwork(ctx, name, opts)
+}
+
+func (DockerManager) RemoveNetwork(ctx context.Context, name string) error {
+	return RemoveNetwork(ctx, name)
+}
+
+func (DockerManager) ConnectNetwork(ctx context.Context, networkName string, containerID string, opts EndpointOptions) error {
+	return ConnectNetwork(ctx, networkName, containerID, opts)
+}
+
+func (DockerManager) DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error {
+	return DisconnectNetwork(ctx, networkName, containerID, force)
+}
+
+func (DockerManager) InspectNetwork(ctx context.Context, name string) (NetworkInfo, error) {
+	return InspectNetwork(ctx, name)
+}
+
+func (DockerManager) CreateVolume(ctx context.Context, name string, opts VolumeOptions) (string, error) {
+	return CreateVolume(ctx, name, opts)
+}
+
+func (DockerManager) RemoveVolume(ctx context.Context, name string, force bool) error {
+	return RemoveVolume(ctx, name, force)
+}
+
+func (DockerManager) ListVolumes(ctx context.Context, filter VolumeFilter) ([]*volume.Volume, error) {
+	return ListVolumes(ctx, filter)
+}
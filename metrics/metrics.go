@@ -0,0 +1,94 @@
+// Package metrics exposes the operations of the containers package as Prometheus metrics
+package metrics
+
+import (
+	"context"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector ~ Counts and times the operations of the containers package. It is a prometheus.Collector for a registry and a
+// containers.Observer for the package
+type Collector struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+}
+
+// NewCollector ~ Creates a collector whose metrics are prefixed with namespace, e.g. myapp_containers_operations_total. An empty namespace
+// leaves the metrics unprefixed
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "containers",
+			Name:      "operations_total",
+			Help:      "Operations of the containers package by operation and result (success or error).",
+		}, []string{"operation", "result"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "containers",
+			Name:      "operation_errors_total",
+			Help:      "Failed operations of the containers package by operation and error type.",
+		}, []string{"operation", "type"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "containers",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the operations of the containers package, including builds, pulls and execs.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"operation"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "containers",
+			Name:      "operations_in_flight",
+			Help:      "Operations of the containers package currently running.",
+		}, []string{"operation"}),
+	}
+}
+
+// Register ~ Creates a collector, registers it with registry and starts observing the containers package
+func Register(registry prometheus.Registerer, namespace string) (*Collector, error) {
+	collector := NewCollector(namespace)
+	if err := registry.Register(collector); err != nil {
+		return nil, err
+	}
+	containers.AddObserver(collector)
+	return collector, nil
+}
+
+// Describe ~ Implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.operations.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.inFlight.Describe(ch)
+}
+
+// Collect ~ Implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.operations.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.inFlight.Collect(ch)
+}
+
+// OperationStarted ~ Implements containers.Observer
+func (c *Collector) OperationStarted(ctx context.Context, op containers.Operation) context.Context {
+	c.inFlight.WithLabelValues(op.Name).Inc()
+	return ctx
+}
+
+// OperationFinished ~ Implements containers.Observer
+func (c *Collector) OperationFinished(ctx context.Context, op containers.Operation) {
+	c.inFlight.WithLabelValues(op.Name).Dec()
+	c.duration.WithLabelValues(op.Name).Observe(op.Duration.Seconds())
+	if op.Err != nil {
+		c.operations.WithLabelValues(op.Name, "error").Inc()
+		c.errors.WithLabelValues(op.Name, containers.ErrorType(op.Err)).Inc()
+		return
+	}
+	c.operations.WithLabelValues(op.Name, "success").Inc()
+}
@@ -0,0 +1,361 @@
+// Package mock provides a scriptable containers.ContainerManager for unit tests that must not talk to a daemon
+package mock
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// Call ~ A recorded call of a Manager method with its arguments, contexts excluded
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Manager ~ A containers.ContainerManager whose responses are scripted through its func fields. A method whose func is nil
+// returns zero values and a nil error. Every call is recorded, whether it is scripted or not. The zero value is ready to use
+type Manager struct {
+	BuildImageFunc               func(path string, imageName string) error
+	EnsureImageFunc              func(ctx context.Context, imageName string) error
+	DeleteImageFunc              func(imageName string) (bool, error)
+	PruneDanglingImagesFunc      func() (image.PruneReport, error)
+	CreateContainerFunc          func(config *containers.ContainerCreateConfig) (container.CreateResponse, error)
+	StartContainerFunc           func(cont container.CreateResponse) error
+	StopContainerFunc            func(containerID string) error
+	RestartContainerFunc         func(ctx context.Context, containerID string, timeout time.Duration) error
+	PauseContainerFunc           func(ctx context.Context, containerID string) error
+	UnpauseContainerFunc         func(ctx context.Context, containerID string) error
+	KillContainerFunc            func(ctx context.Context, containerID string, signal string) error
+	RenameContainerFunc          func(ctx context.Context, containerID string, newName string) error
+	PurgeContainerFunc           func(containerID string) error
+	InspectContainerFunc         func(ctx context.Context, containerID string) (containers.ContainerInfo, error)
+	GetContainerHealthStatusFunc func(containerID string) (containers.HealthStatus, error)
+	GetContainerStatsFunc        func(ctx context.Context, containerID string) (containers.ContainerStats, error)
+	GetHostPortFunc              func(ctx context.Context, containerID string, containerPort string) (string, error)
+	GetContainerIPsFunc          func(ctx context.Context, containerID string) (map[string]containers.NetworkAddresses, error)
+	ExecFunc                     func(containerID string, cmd []string) (string, error)
+	ExecWithResultFunc           func(ctx context.Context, containerID string, cmd []string, opts containers.ExecOptions) (containers.ExecResult, error)
+	StreamContainerLogsFunc      func(ctx context.Context, containerID string, opts container.LogsOptions, fn func(containers.LogLine)) error
+	CopyToContainerFunc          func(ctx context.Context, containerID string, destPath string, src interface{}) error
+	CopyFromContainerStreamFunc  func(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error)
+	WaitForExitFunc              func(ctx context.Context, containerID string) (int64, error)
+	WaitForHealthyFunc           func(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error
+	CreateNetworkFunc            func(ctx context.Context, name string, opts containers.NetworkOptions) (string, error)
+	RemoveNetworkFunc            func(ctx context.Context, name string) error
+	ConnectNetworkFunc           func(ctx context.Context, networkName string, containerID string, opts containers.EndpointOptions) error
+	DisconnectNetworkFunc        func(ctx context.Context, networkName string, containerID string, force bool) error
+	InspectNetworkFunc           func(ctx context.Context, name string) (containers.NetworkInfo, error)
+	CreateVolumeFunc             func(ctx context.Context, name string, opts containers.VolumeOptions) (string, error)
+	RemoveVolumeFunc             func(ctx context.Context, name string, force bool) error
+	ListVolumesFunc              func(ctx context.Context, filter containers.VolumeFilter) ([]*volume.Volume, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ containers.ContainerManager = (*Manager)(nil)
+
+// Calls ~ Returns every recorded call in order
+func (m *Manager) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo ~ Returns the recorded calls of a single method in order
+func (m *Manager) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []Call
+	for _, call := range m.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset ~ Forgets the recorded calls, keeping the scripted responses
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *Manager) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *Manager) BuildImage(path string, imageName string) error {
+	m.record("BuildImage", path, imageName)
+	if m.BuildImageFunc == nil {
+		return nil
+	}
+	return m.BuildImageFunc(path, imageName)
+}
+
+func (m *Manager) EnsureImage(ctx context.Context, imageName string) error {
+	m.record("EnsureImage", imageName)
+	if m.EnsureImageFunc == nil {
+		return nil
+	}
+	return m.EnsureImageFunc(ctx, imageName)
+}
+
+func (m *Manager) DeleteImage(imageName string) (bool, error) {
+	m.record("DeleteImage", imageName)
+	if m.DeleteImageFunc == nil {
+		return false, nil
+	}
+	return m.DeleteImageFunc(imageName)
+}
+
+func (m *Manager) PruneDanglingImages() (image.PruneReport, error) {
+	m.record("PruneDanglingImages")
+	if m.PruneDanglingImagesFunc == nil {
+		return image.PruneReport{}, nil
+	}
+	return m.PruneDanglingImagesFunc()
+}
+
+func (m *Manager) CreateContainer(config *containers.ContainerCreateConfig) (container.CreateResponse, error) {
+	m.record("CreateContainer", config)
+	if m.CreateContainerFunc == nil {
+		return container.CreateResponse{}, nil
+	}
+	return m.CreateContainerFunc(config)
+}
+
+func (m *Manager) StartContainer(cont container.CreateResponse) error {
+	m.record("StartContainer", cont)
+	if m.StartContainerFunc == nil {
+		return nil
+	}
+	return m.StartContainerFunc(cont)
+}
+
+func (m *Manager) StopContainer(containerID string) error {
+	m.record("StopContainer", containerID)
+	if m.StopContainerFunc == nil {
+		return nil
+	}
+	return m.StopContainerFunc(containerID)
+}
+
+func (m *Manager) RestartContainer(ctx context.Context, containerID string, timeout time.Duration) error {
+	m.record("RestartContainer", containerID, timeout)
+	if m.RestartContainerFunc == nil {
+		return nil
+	}
+	return m.RestartContainerFunc(ctx, containerID, timeout)
+}
+
+func (m *Manager) PauseContainer(ctx context.Context, containerID string) error {
+	m.record("PauseContainer", containerID)
+	if m.PauseContainerFunc == nil {
+		return nil
+	}
+	return m.PauseContainerFunc(ctx, containerID)
+}
+
+func (m *Manager) UnpauseContainer(ctx context.Context, containerID string) error {
+	m.record("UnpauseContainer", containerID)
+	if m.UnpauseContainerFunc == nil {
+		return nil
+	}
+	return m.UnpauseContainerFunc(ctx, containerID)
+}
+
+func (m *Manager) KillContainer(ctx context.Context, containerID string, signal string) error {
+	m.record("KillContainer", containerID, signal)
+	if m.KillContainerFunc == nil {
+		return nil
+	}
+	return m.KillContainerFunc(ctx, containerID, signal)
+}
+
+func (m *Manager) RenameContainer(ctx context.Context, containerID string, newName string) error {
+	m.record("RenameContainer", containerID, newName)
+	if m.RenameContainerFunc == nil {
+		return nil
+	}
+	return m.RenameContainerFunc(ctx, containerID, newName)
+}
+
+func (m *Manager) PurgeContainer(containerID string) error {
+	m.record("PurgeContainer", containerID)
+	if m.PurgeContainerFunc == nil {
+		return nil
+	}
+	return m.PurgeContainerFunc(containerID)
+}
+
+func (m *Manager) InspectContainer(ctx context.Context, containerID string) (containers.ContainerInfo, error) {
+	m.record("InspectContainer", containerID)
+	if m.InspectContainerFunc == nil {
+		return containers.ContainerInfo{}, nil
+	}
+	return m.InspectContainerFunc(ctx, containerID)
+}
+
+func (m *Manager) GetContainerHealthStatus(containerID string) (containers.HealthStatus, error) {
+	m.record("GetContainerHealthStatus", containerID)
+	if m.GetContainerHealthStatusFunc == nil {
+		return "", nil
+	}
+	return m.GetContainerHealthStatusFunc(containerID)
+}
+
+func (m *Manager) GetContainerStats(ctx context.Context, containerID string) (containers.ContainerStats, error) {
+	m.record("GetContainerStats", containerID)
+	if m.GetContainerStatsFunc == nil {
+		return containers.ContainerStats{}, nil
+	}
+	return m.GetContainerStatsFunc(ctx, containerID)
+}
+
+func (m *Manager) GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error) {
+	m.record("GetHostPort", containerID, containerPort)
+	if m.GetHostPortFunc == nil {
+		return "", nil
+	}
+	return m.GetHostPortFunc(ctx, containerID, containerPort)
+}
+
+func (m *Manager) GetContainerIPs(ctx context.Context, containerID string) (map[string]containers.NetworkAddresses, error) {
+	m.record("GetContainerIPs", containerID)
+	if m.GetContainerIPsFunc == nil {
+		return map[string]containers.NetworkAddresses{}, nil
+	}
+	return m.GetContainerIPsFunc(ctx, containerID)
+}
+
+func (m *Manager) Exec(containerID string, cmd []string) (string, error) {
+	m.record("Exec", containerID, cmd)
+	if m.ExecFunc == nil {
+		return "", nil
+	}
+	return m.ExecFunc(containerID, cmd)
+}
+
+func (m *Manager) ExecWithResult(ctx context.Context, containerID string, cmd []string, opts containers.ExecOptions) (containers.ExecResult, error) {
+	m.record("ExecWithResult", containerID, cmd, opts)
+	if m.ExecWithResultFunc == nil {
+		return containers.ExecResult{}, nil
+	}
+	return m.ExecWithResultFunc(ctx, containerID, cmd, opts)
+}
+
+func (m *Manager) StreamContainerLogs(ctx context.Context, containerID string, opts container.LogsOptions, fn func(containers.LogLine)) error {
+	m.record("StreamContainerLogs", containerID, opts)
+	if m.StreamContainerLogsFunc == nil {
+		return nil
+	}
+	return m.StreamContainerLogsFunc(ctx, containerID, opts, fn)
+}
+
+func (m *Manager) CopyToContainer(ctx context.Context, containerID string, destPath string, src interface{}) error {
+	m.record("CopyToContainer", containerID, destPath, src)
+	if m.CopyToContainerFunc == nil {
+		return nil
+	}
+	return m.CopyToContainerFunc(ctx, containerID, destPath, src)
+}
+
+func (m *Manager) CopyFromContainerStream(ctx context.Context, containerID string, srcPath string) (io.ReadCloser, error) {
+	m.record("CopyFromContainerStream", containerID, srcPath)
+	if m.CopyFromContainerStreamFunc == nil {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return m.CopyFromContainerStreamFunc(ctx, containerID, srcPath)
+}
+
+func (m *Manager) WaitForExit(ctx context.Context, containerID string) (int64, error) {
+	m.record("WaitForExit", containerID)
+	if m.WaitForExitFunc == nil {
+		return 0, nil
+	}
+	return m.WaitForExitFunc(ctx, containerID)
+}
+
+func (m *Manager) WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	m.record("WaitForHealthy", containerID, timeout, interval)
+	if m.WaitForHealthyFunc == nil {
+		return nil
+	}
+	return m.WaitForHealthyFunc(ctx, containerID, timeout, interval)
+}
+
+func (m *Manager) CreateNetwork(ctx context.Context, name string, opts containers.NetworkOptions) (string, error) {
+	m.record("CreateNetwork", name, opts)
+	if m.CreateNetworkFunc == nil {
+		return "", nil
+	}
+	return m.CreateNetworkFunc(ctx, name, opts)
+}
+
+func (m *Manager) RemoveNetwork(ctx context.Context, name string) error {
+	m.record("RemoveNetwork", name)
+	if m.RemoveNetworkFunc == nil {
+		return nil
+	}
+	return m.RemoveNetworkFunc(ctx, name)
+}
+
+func (m *Manager) ConnectNetwork(ctx context.Context, networkName string, containerID string, opts containers.EndpointOptions) error {
+	m.record("ConnectNetwork", networkName, containerID, opts)
+	if m.ConnectNetworkFunc == nil {
+		return nil
+	}
+	return m.ConnectNetworkFunc(ctx, networkName, containerID, opts)
+}
+
+func (m *Manager) DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error {
+	m.record("DisconnectNetwork", networkName, containerID, force)
+	if m.DisconnectNetworkFunc == nil {
+		return nil
+	}
+	return m.DisconnectNetworkFunc(ctx, networkName, containerID, force)
+}
+
+func (m *Manager) InspectNetwork(ctx context.Context, name string) (containers.NetworkInfo, error) {
+	m.record("InspectNetwork", name)
+	if m.InspectNetworkFunc == nil {
+		return containers.NetworkInfo{}, nil
+	}
+	return m.InspectNetworkFunc(ctx, name)
+}
+
+func (m *Manager) CreateVolume(ctx context.Context, name string, opts containers.VolumeOptions) (string, error) {
+	m.record("CreateVolume", name, opts)
+	if m.CreateVolumeFunc == nil {
+		return "", nil
+	}
+	return m.CreateVolumeFunc(ctx, name, opts)
+}
+
+func (m *Manager) RemoveVolume(ctx context.Context, name string, force bool) error {
+	m.record("RemoveVolume", name, force)
+	if m.RemoveVolumeFunc == nil {
+		return nil
+	}
+	return m.RemoveVolumeFunc(ctx, name, force)
+}
+
+func (m *Manager) ListVolumes(ctx context.Context, filter containers.VolumeFilter) ([]*volume.Volume, error) {
+	m.record("ListVolumes", filter)
+	if m.ListVolumesFunc == nil {
+		return nil, nil
+	}
+	return m.ListVolumesFunc(ctx, filter)
+}
@@ -0,0 +1,65 @@
+package containers
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+// BindMount ~ Mounts the host path source at target. Propagation is optional and only applies to bind mounts
+func BindMount(source string, target string, readOnly bool, propagation mount.Propagation) mount.Mount {
+	m := mount.Mount{
+		Type:     mount.TypeBind,
+		Source:   source,
+		Target:   target,
+		ReadOnly: readOnly,
+	}
+	if propagation != "" {
+		m.BindOptions = &mount.BindOptions{Propagation: propagation}
+	}
+	return m
+}
+
+// VolumeMount ~ Mounts the named volume at target, creating the volume if it does not exist
+func VolumeMount(name string, target string, readOnly bool) mount.Mount {
+	return mount.Mount{
+		Type:     mount.TypeVolume,
+		Source:   name,
+		Target:   target,
+		ReadOnly: readOnly,
+	}
+}
+
+// TmpfsMount ~ Mounts an in-memory filesystem at target. A zero size or mode uses the daemon defaults
+func TmpfsMount(target string, sizeBytes int64, mode os.FileMode) mount.Mount {
+	return mount.Mount{
+		Type:   mount.TypeTmpfs,
+		Target: target,
+		TmpfsOptions: &mount.TmpfsOptions{
+			SizeBytes: sizeBytes,
+			Mode:      mode,
+		},
+	}
+}
+
+// ParseBind ~ Splits a bind of HostConfig.Binds, source:target[:options], into its parts. The bind is read only when one of its comma
+// separated options is exactly ro
+func ParseBind(bind string) (source string, target string, readOnly bool, err error) {
+	parts := strings.Split(bind, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", false, errors.New("[ERR:] [DOCKER] => INVALID BIND " + bind)
+	}
+	if len(parts) == 3 {
+		readOnly = slices.Contains(strings.Split(parts[2], ","), "ro")
+	}
+	return parts[0], parts[1], readOnly, nil
+}
+
+// AddMounts ~ Adds mounts to the host config of a container
+func AddMounts(config *ContainerCreateConfig, mounts ...mount.Mount) {
+	ensureContainerConfigs(config)
+	config.HostConfig.Mounts = append(config.HostConfig.Mounts, mounts...)
+}
@@ -0,0 +1,34 @@
+package containers
+
+import "testing"
+
+func TestParseBind(t *testing.T) {
+	tests := []struct {
+		bind     string
+		source   string
+		target   string
+		readOnly bool
+		wantErr  bool
+	}{
+		{bind: "/data:/data", source: "/data", target: "/data"},
+		{bind: "/data:/data:ro", source: "/data", target: "/data", readOnly: true},
+		{bind: "/data:/data:rw", source: "/data", target: "/data"},
+		{bind: "/data:/data:z,ro", source: "/data", target: "/data", readOnly: true},
+		{bind: "/data:/data:rprivate", source: "/data", target: "/data"},
+		{bind: "/data:/data:rshared,z", source: "/data", target: "/data"},
+		{bind: "cache:/cache:ro", source: "cache", target: "/cache", readOnly: true},
+		{bind: "/data", wantErr: true},
+		{bind: ":/data", wantErr: true},
+		{bind: "/a:/b:ro:extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		source, target, readOnly, err := ParseBind(tt.bind)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBind(%q) error = %v, want error %v", tt.bind, err, tt.wantErr)
+			continue
+		}
+		if source != tt.source || target != tt.target || readOnly != tt.readOnly {
+			t.Errorf("ParseBind(%q) = %q, %q, %v, want %q, %q, %v", tt.bind, source, target, readOnly, tt.source, tt.target, tt.readOnly)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// CreateNetwork ~ Creates a network and returns its ID. An empty driver defaults to bridge
+func CreateNetwork(ctx context.Context, name string, opts NetworkOptions) (string, error) {
+	createOptions := network.CreateOptions{
+		Driver:     opts.Driver,
+		Internal:   opts.Internal,
+		Attachable: opts.Attachable,
+		Options:    opts.Options,
+		Labels:     withManagedLabel(opts.Labels),
+	}
+	if createOptions.Driver == "" {
+		createOptions.Driver = "bridge"
+	}
+	if opts.EnableIPv6 {
+		enableIPv6 := true
+		createOptions.EnableIPv6 = &enableIPv6
+	}
+	if opts.Subnet != "" {
+		createOptions.IPAM = &network.IPAM{
+			Driver: "default",
+			Config: []network.IPAMConfig{{
+				Subnet:  opts.Subnet,
+				IPRange: opts.IPRange,
+				Gateway: opts.Gateway,
+			}},
+		}
+	}
+
+	if dryRun(OpCreateNetwork, name, "") {
+		return dryRunID(name), nil
+	}
+	ctx, done := beginOperation(ctx, OpCreateNetwork, name, "")
+	networkRes, err := DockerClient.NetworkCreate(ctx, name, createOptions)
+	done(err)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO CREATE NETWORK " + name + " => " + err.Error())
+	}
+	return networkRes.ID, nil
+}
+
+// RemoveNetwork ~ Removes a network by name or ID
+func RemoveNetwork(ctx context.Context, name string) error {
+	if dryRun(OpRemoveNetwork, name, "") {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpRemoveNetwork, name, "")
+	err := DockerClient.NetworkRemove(ctx, name)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO REMOVE NETWORK " + name + " => " + err.Error())
+	}
+	return nil
+}
+
+// ConnectNetwork ~ Connects a container to a network, optionally with aliases and static IPv4/IPv6 addresses
+func ConnectNetwork(ctx context.Context, networkName string, containerID string, opts EndpointOptions) error {
+	endpoint := &network.EndpointSettings{Aliases: opts.Aliases}
+	if opts.IPv4Address != "" || opts.IPv6Address != "" {
+		endpoint.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: opts.IPv4Address,
+			IPv6Address: opts.IPv6Address,
+		}
+	}
+
+	if dryRun(OpConnectNetwork, containerID, "", "network", networkName) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpConnectNetwork, containerID, "", "network", networkName)
+	err := DockerClient.NetworkConnect(ctx, networkName, containerID, endpoint)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO CONNECT CONTAINER WITH ID: " + containerID + " TO NETWORK " + networkName + " => " + err.Error())
+	}
+	return nil
+}
+
+// DisconnectNetwork ~ Disconnects a container from a network. force also disconnects containers that are not running
+func DisconnectNetwork(ctx context.Context, networkName string, containerID string, force bool) error {
+	if dryRun(OpDisconnectNetwork, containerID, "", "network", networkName, "force", strconv.FormatBool(force)) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpDisconnectNetwork, containerID, "", "network", networkName, "force", strconv.FormatBool(force))
+	err := DockerClient.NetworkDisconnect(ctx, networkName, containerID, force)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO DISCONNECT CONTAINER WITH ID: " + containerID + " FROM NETWORK " + networkName + " => " + err.Error())
+	}
+	return nil
+}
+
+// ListNetworks ~ Lists networks, optionally filtered by name and labels, with their subnets and attached containers
+func ListNetworks(ctx context.Context, filter NetworkFilter) ([]NetworkInfo, error) {
+	listFilters := filters.NewArgs()
+	if filter.Name != "" {
+		listFilters.Add("name", filter.Name)
+	}
+	addLabelFilters(listFilters, filter.Labels)
+
+	networkList, err := retryResult(ctx, func() ([]network.Summary, error) {
+		return DockerClient.NetworkList(ctx, network.ListOptions{Filters: listFilters})
+	})
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO LIST NETWORKS => " + err.Error())
+	}
+
+	// The list endpoint does not report attached containers, so every network is inspected
+	networks := make([]NetworkInfo, 0, len(networkList))
+	for _, summary := range networkList {
+		info, err := InspectNetwork(ctx, summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, info)
+	}
+	return networks, nil
+}
+
+// InspectNetwork ~ Returns a simplified view of a network by name or ID
+func InspectNetwork(ctx context.Context, name string) (NetworkInfo, error) {
+	inspect, err := retryResult(ctx, func() (network.Inspect, error) {
+		return DockerClient.NetworkInspect(ctx, name, network.InspectOptions{})
+	})
+	if err != nil {
+		return NetworkInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT NETWORK " + name + " => " + err.Error())
+	}
+	return toNetworkInfo(inspect), nil
+}
+
+func toNetworkInfo(inspect network.Inspect) NetworkInfo {
+	info := NetworkInfo{
+		ID:         inspect.ID,
+		Name:       inspect.Name,
+		Driver:     inspect.Driver,
+		Scope:      inspect.Scope,
+		Internal:   inspect.Internal,
+		Attachable: inspect.Attachable,
+		EnableIPv6: inspect.EnableIPv6,
+		Labels:     inspect.Labels,
+		Options:    inspect.Options,
+	}
+	for _, pool := range inspect.IPAM.Config {
+		info.Subnets = append(info.Subnets, pool.Subnet)
+		if pool.Gateway != "" {
+			info.Gateways = append(info.Gateways, pool.Gateway)
+		}
+	}
+	for id, endpoint := range inspect.Containers {
+		info.Containers = append(info.Containers, NetworkContainer{
+			ID:          id,
+			Name:        endpoint.Name,
+			IPv4Address: endpoint.IPv4Address,
+			IPv6Address: endpoint.IPv6Address,
+		})
+	}
+	sort.Slice(info.Containers, func(i, j int) bool {
+		return info.Containers[i].Name < info.Containers[j].Name
+	})
+	return info
+}
+
+// EnsureNetwork ~ Returns the ID of the network and whether it had to be created, creating it only if it does not exist yet.
+// An existing network whose driver, internal flag or subnet differ from opts is reported as an error
+func EnsureNetwork(ctx context.Context, name string, opts NetworkOptions) (string, bool, error) {
+	inspect, err := retryResult(ctx, func() (network.Inspect, error) {
+		return DockerClient.NetworkInspect(ctx, name, network.InspectOptions{})
+	})
+	if err != nil && !client.IsErrNotFound(err) {
+		return "", false, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT NETWORK " + name + " => " + err.Error())
+	}
+	if err != nil {
+		id, err := CreateNetwork(ctx, name, opts)
+		return id, err == nil, err
+	}
+
+	var mismatches []string
+	driver := opts.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+	if inspect.Driver != driver {
+		mismatches = append(mismatches, "driver "+inspect.Driver+" instead of "+driver)
+	}
+	if inspect.Internal != opts.Internal {
+		mismatches = append(mismatches, "internal "+strconv.FormatBool(inspect.Internal)+" instead of "+strconv.FormatBool(opts.Internal))
+	}
+	if opts.Subnet != "" {
+		found := false
+		for _, pool := range inspect.IPAM.Config {
+			if pool.Subnet == opts.Subnet {
+				found = true
+				break
+			}
+		}
+		if !found {
+			mismatches = append(mismatches, "subnet "+opts.Subnet+" missing")
+		}
+	}
+	if len(mismatches) > 0 {
+		return inspect.ID, false, errors.New("[ERR:] [DOCKER] => NETWORK " + name + " EXISTS WITH INCOMPATIBLE OPTIONS => " + strings.Join(mismatches, ", "))
+	}
+	return inspect.ID, false, nil
+}
@@ -0,0 +1,154 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+)
+
+// Names of the operations reported to observers
+const (
+	OpBuildImage        = "BuildImage"
+	OpPullImage         = "PullImage"
+	OpPushImage         = "PushImage"
+	OpTagImage          = "TagImage"
+	OpDeleteImage       = "DeleteImage"
+	OpPruneImages       = "PruneImages"
+	OpCreateContainer   = "CreateContainer"
+	OpStartContainer    = "StartContainer"
+	OpStopContainer     = "StopContainer"
+	OpRestartContainer  = "RestartContainer"
+	OpPauseContainer    = "PauseContainer"
+	OpUnpauseContainer  = "UnpauseContainer"
+	OpKillContainer     = "KillContainer"
+	OpRenameContainer   = "RenameContainer"
+	OpUpdateContainer   = "UpdateContainer"
+	OpPurgeContainer    = "PurgeContainer"
+	OpCommitContainer   = "CommitContainer"
+	OpExec              = "Exec"
+	OpCopyToContainer   = "CopyToContainer"
+	OpCreateNetwork     = "CreateNetwork"
+	OpRemoveNetwork     = "RemoveNetwork"
+	OpConnectNetwork    = "ConnectNetwork"
+	OpDisconnectNetwork = "DisconnectNetwork"
+	OpCreateVolume      = "CreateVolume"
+	OpRemoveVolume      = "RemoveVolume"
+	OpPruneVolumes      = "PruneVolumes"
+	OpBackupVolume      = "BackupVolume"
+	OpRestoreVolume     = "RestoreVolume"
+	OpSystemPrune       = "SystemPrune"
+)
+
+// Operation ~ A call the package made to the daemon. Target is the container, image, network or volume acted on, Image the image involved
+// if any, Parameters the arguments that shape the call (signal, new name, command, ...). Duration and Err are only set once the operation finished
+type Operation struct {
+	Name       string
+	Target     string
+	Image      string
+	Parameters map[string]string
+	Start      time.Time
+	Duration   time.Duration
+	Err        error
+}
+
+// Observer ~ Is told about every operation when it starts and when it finishes. OperationStarted may return a derived context, e.g. carrying
+// a trace span, that the operation uses for its daemon calls and hands back to OperationFinished
+type Observer interface {
+	OperationStarted(ctx context.Context, op Operation) context.Context
+	OperationFinished(ctx context.Context, op Operation)
+}
+
+var (
+	observersMu sync.RWMutex
+	observers   []Observer
+)
+
+// AddObserver ~ Registers an observer of every operation
+func AddObserver(observer Observer) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	observers = append(observers, observer)
+}
+
+// RemoveObserver ~ Unregisters an observer
+func RemoveObserver(observer Observer) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	for i, o := range observers {
+		if o == observer {
+			observers = append(observers[:i:i], observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// ErrorType ~ Classifies an error of the package for metrics and logs: not_found, conflict, invalid, unauthorized, forbidden, unavailable,
+// rate_limited, timeout, cancelled, system or unknown
+func ErrorType(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case IsRateLimitError(err):
+		return "rate_limited"
+	case errors.Is(err, context.DeadlineExceeded) || errdefs.IsDeadline(err):
+		return "timeout"
+	case errors.Is(err, context.Canceled) || errdefs.IsCancelled(err):
+		return "cancelled"
+	case errdefs.IsNotFound(err):
+		return "not_found"
+	case errdefs.IsConflict(err):
+		return "conflict"
+	case errdefs.IsInvalidParameter(err):
+		return "invalid"
+	case errdefs.IsUnauthorized(err):
+		return "unauthorized"
+	case errdefs.IsForbidden(err):
+		return "forbidden"
+	case errdefs.IsUnavailable(err) || IsTransientError(err):
+		return "unavailable"
+	case errdefs.IsSystem(err):
+		return "system"
+	}
+	return "unknown"
+}
+
+// beginOperation reports the start of an operation to the observers. params are key, value pairs. The returned function reports its end
+// and is handed the error of the docker client as is, so observers can classify it with ErrorType
+func beginOperation(ctx context.Context, name string, target string, image string, params ...string) (context.Context, func(err error)) {
+	observersMu.RLock()
+	current := observers
+	observersMu.RUnlock()
+
+	if len(current) == 0 {
+		return ctx, func(error) {}
+	}
+	op := Operation{Name: name, Target: target, Image: image, Start: time.Now()}
+	if len(params) > 0 {
+		op.Parameters = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			op.Parameters[params[i]] = params[i+1]
+		}
+	}
+
+	contexts := make([]context.Context, len(current))
+	for i, observer := range current {
+		contexts[i] = observer.OperationStarted(ctx, op)
+		if contexts[i] != nil {
+			ctx = contexts[i]
+		}
+	}
+	return ctx, func(err error) {
+		op.Duration = time.Since(op.Start)
+		op.Err = err
+		for i := len(current) - 1; i >= 0; i-- {
+			observerCtx := contexts[i]
+			if observerCtx == nil {
+				observerCtx = ctx
+			}
+			current[i].OperationFinished(observerCtx, op)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+// Package ocispec renders container configs of the containers package as OCI runtime-spec config.json files and reads them back,
+// for interop with runc based tooling. Only the subset of the runtime spec that a container config can express is covered
+package ocispec
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/G-MAKROGLOU/containers"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// Version ~ The runtime-spec version written into rendered specs
+const Version = "1.1.0"
+
+// Spec ~ The subset of an OCI runtime-spec config.json this package reads and writes, with the field names of the spec
+type Spec struct {
+	Version     string            `json:"ociVersion"`
+	Process     *Process          `json:"process,omitempty"`
+	Root        *Root             `json:"root,omitempty"`
+	Hostname    string            `json:"hostname,omitempty"`
+	Mounts      []Mount           `json:"mounts,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Linux       *Linux            `json:"linux,omitempty"`
+}
+
+// Process ~ The process of a runtime spec
+type Process struct {
+	Terminal        bool          `json:"terminal,omitempty"`
+	User            User          `json:"user"`
+	Args            []string      `json:"args,omitempty"`
+	Env             []string      `json:"env,omitempty"`
+	Cwd             string        `json:"cwd"`
+	Capabilities    *Capabilities `json:"capabilities,omitempty"`
+	NoNewPrivileges bool          `json:"noNewPrivileges,omitempty"`
+}
+
+// User ~ The numeric user of a process. Username is only honoured on Windows by runtimes
+type User struct {
+	UID      uint32 `json:"uid"`
+	GID      uint32 `json:"gid"`
+	Username string `json:"username,omitempty"`
+}
+
+// Capabilities ~ The capability sets of a process
+type Capabilities struct {
+	Bounding    []string `json:"bounding,omitempty"`
+	Effective   []string `json:"effective,omitempty"`
+	Permitted   []string `json:"permitted,omitempty"`
+	Inheritable []string `json:"inheritable,omitempty"`
+	Ambient     []string `json:"ambient,omitempty"`
+}
+
+// Root ~ The root filesystem of a runtime spec, relative to the bundle
+type Root struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly,omitempty"`
+}
+
+// Mount ~ A mount of a runtime spec
+type Mount struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// Linux ~ The Linux specific part of a runtime spec
+type Linux struct {
+	Resources   *Resources  `json:"resources,omitempty"`
+	Namespaces  []Namespace `json:"namespaces,omitempty"`
+	MaskedPaths []string    `json:"maskedPaths,omitempty"`
+}
+
+// Resources ~ The cgroup limits of a runtime spec
+type Resources struct {
+	Memory *Memory `json:"memory,omitempty"`
+	CPU    *CPU    `json:"cpu,omitempty"`
+	Pids   *Pids   `json:"pids,omitempty"`
+}
+
+// Memory ~ The memory limit in bytes
+type Memory struct {
+	Limit *int64 `json:"limit,omitempty"`
+}
+
+// CPU ~ The CPU quota per period, both in microseconds
+type CPU struct {
+	Quota  *int64  `json:"quota,omitempty"`
+	Period *uint64 `json:"period,omitempty"`
+}
+
+// Pids ~ The process limit
+type Pids struct {
+	Limit int64 `json:"limit"`
+}
+
+// Namespace ~ A namespace the container gets, e.g. pid, network, ipc, uts or mount
+type Namespace struct {
+	Type string `json:"type"`
+	Path string `json:"path,omitempty"`
+}
+
+// defaultCapabilities are the capabilities Docker grants a container that adds and drops none
+var defaultCapabilities = []string{
+	"CAP_AUDIT_WRITE", "CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_FOWNER", "CAP_FSETID", "CAP_KILL", "CAP_MKNOD", "CAP_NET_BIND_SERVICE",
+	"CAP_NET_RAW", "CAP_SETFCAP", "CAP_SETGID", "CAP_SETPCAP", "CAP_SETUID", "CAP_SYS_CHROOT",
+}
+
+// allCapabilities are the capabilities of Linux 5.9 and later, which privileged containers and "ALL" grant
+var allCapabilities = []string{
+	"CAP_AUDIT_CONTROL", "CAP_AUDIT_READ", "CAP_AUDIT_WRITE", "CAP_BLOCK_SUSPEND", "CAP_BPF", "CAP_CHECKPOINT_RESTORE", "CAP_CHOWN",
+	"CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER", "CAP_FSETID", "CAP_IPC_LOCK", "CAP_IPC_OWNER", "CAP_KILL", "CAP_LEASE",
+	"CAP_LINUX_IMMUTABLE", "CAP_MAC_ADMIN", "CAP_MAC_OVERRIDE", "CAP_MKNOD", "CAP_NET_ADMIN", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_RAW", "CAP_PERFMON", "CAP_SETFCAP", "CAP_SETGID", "CAP_SETPCAP", "CAP_SETUID", "CAP_SYSLOG", "CAP_SYS_ADMIN", "CAP_SYS_BOOT",
+	"CAP_SYS_CHROOT", "CAP_SYS_MODULE", "CAP_SYS_NICE", "CAP_SYS_PACCT", "CAP_SYS_PTRACE", "CAP_SYS_RAWIO", "CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_WAKE_ALARM",
+}
+
+// defaultMounts are the mounts every runc container gets, they are not container config mounts
+var defaultMounts = []Mount{
+	{Destination: "/proc", Type: "proc", Source: "proc"},
+	{Destination: "/dev", Type: "tmpfs", Source: "tmpfs", Options: []string{"nosuid", "strictatime", "mode=755", "size=65536k"}},
+	{Destination: "/dev/pts", Type: "devpts", Source: "devpts", Options: []string{"nosuid", "noexec", "newinstance", "ptmxmode=0666", "mode=0620", "gid=5"}},
+	{Destination: "/dev/shm", Type: "tmpfs", Source: "shm", Options: []string{"nosuid", "noexec", "nodev", "mode=1777", "size=65536k"}},
+	{Destination: "/dev/mqueue", Type: "mqueue", Source: "mqueue", Options: []string{"nosuid", "noexec", "nodev"}},
+	{Destination: "/sys", Type: "sysfs", Source: "sysfs", Options: []string{"nosuid", "noexec", "nodev", "ro"}},
+	{Destination: "/sys/fs/cgroup", Type: "cgroup", Source: "cgroup", Options: []string{"nosuid", "noexec", "nodev", "relatime", "ro"}},
+}
+
+const cpuPeriod = 100000
+
+// FromConfig ~ Renders a container config as a runtime spec with the root filesystem at rootfs inside the bundle. The args are the
+// entrypoint followed by the command, so configs that rely on the defaults of their image should be taken from an existing container
+// (see containers.ConfigFromContainer). Named volumes cannot be expressed and are reported as an error, as are non numeric users
+func FromConfig(config *containers.ContainerCreateConfig) (*Spec, error) {
+	if config == nil || config.Config == nil {
+		return nil, errors.New("[ERR:] [OCI] => THE CONTAINER CONFIG IS EMPTY")
+	}
+	cfg := config.Config
+	hc := config.HostConfig
+	if hc == nil {
+		hc = &container.HostConfig{}
+	}
+
+	args := append(append([]string{}, cfg.Entrypoint...), cfg.Cmd...)
+	if len(args) == 0 {
+		return nil, errors.New("[ERR:] [OCI] => THE CONTAINER CONFIG HAS NO ENTRYPOINT OR COMMAND")
+	}
+	user, err := parseUser(cfg.User)
+	if err != nil {
+		return nil, err
+	}
+	cwd := cfg.WorkingDir
+	if cwd == "" {
+		cwd = "/"
+	}
+	caps := capabilitySet(hc.CapAdd, hc.CapDrop, hc.Privileged)
+
+	spec := &Spec{
+		Version: Version,
+		Process: &Process{
+			Terminal: cfg.Tty,
+			User:     user,
+			Args:     args,
+			Env:      cfg.Env,
+			Cwd:      cwd,
+			Capabilities: &Capabilities{
+				Bounding:  caps,
+				Effective: caps,
+				Permitted: caps,
+			},
+			NoNewPrivileges: hasSecurityOpt(hc.SecurityOpt, "no-new-privileges"),
+		},
+		Root:        &Root{Path: "rootfs", Readonly: hc.ReadonlyRootfs},
+		Hostname:    cfg.Hostname,
+		Mounts:      append([]Mount{}, defaultMounts...),
+		Annotations: cfg.Labels,
+		Linux:       &Linux{},
+	}
+	if spec.Hostname == "" {
+		spec.Hostname = config.Name
+	}
+
+	for _, m := range hc.Mounts {
+		switch m.Type {
+		case mount.TypeBind:
+			spec.Mounts = append(spec.Mounts, bindMount(m.Source, m.Target, m.ReadOnly))
+		case mount.TypeTmpfs:
+			options := []string{"nosuid", "nodev"}
+			if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+				options = append(options, "size="+strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10))
+			}
+			spec.Mounts = append(spec.Mounts, Mount{Destination: m.Target, Type: "tmpfs", Source: "tmpfs", Options: options})
+		default:
+			return nil, errors.New("[ERR:] [OCI] => " + strings.ToUpper(string(m.Type)) + " MOUNT OF " + m.Target + " CANNOT BE EXPRESSED IN A RUNTIME SPEC")
+		}
+	}
+	for _, bind := range hc.Binds {
+		source, target, readOnly, err := containers.ParseBind(bind)
+		if err != nil || !strings.HasPrefix(source, "/") {
+			return nil, errors.New("[ERR:] [OCI] => BIND " + bind + " CANNOT BE EXPRESSED IN A RUNTIME SPEC")
+		}
+		spec.Mounts = append(spec.Mounts, bindMount(source, target, readOnly))
+	}
+
+	resources := &Resources{}
+	if hc.Memory > 0 {
+		limit := hc.Memory
+		resources.Memory = &Memory{Limit: &limit}
+	}
+	if hc.NanoCPUs > 0 {
+		quota := hc.NanoCPUs * cpuPeriod / 1e9
+		period := uint64(cpuPeriod)
+		resources.CPU = &CPU{Quota: &quota, Period: &period}
+	}
+	if hc.PidsLimit != nil && *hc.PidsLimit > 0 {
+		resources.Pids = &Pids{Limit: *hc.PidsLimit}
+	}
+	if resources.Memory != nil || resources.CPU != nil || resources.Pids != nil {
+		spec.Linux.Resources = resources
+	}
+
+	for _, namespace := range []string{"pid", "ipc", "uts", "mount", "network"} {
+		if namespace == "network" && hc.NetworkMode.IsHost() {
+			continue
+		}
+		if namespace == "pid" && hc.PidMode.IsHost() {
+			continue
+		}
+		if namespace == "ipc" && hc.IpcMode.IsHost() {
+			continue
+		}
+		spec.Linux.Namespaces = append(spec.Linux.Namespaces, Namespace{Type: namespace})
+	}
+	return spec, nil
+}
+
+// ToConfig ~ Builds a container config from a runtime spec. The spec does not name an image, so it has to be given. The default mounts
+// of runc and the default capabilities of Docker are left out, everything else the config can express is carried over
+func ToConfig(spec *Spec, image string, name string) (*containers.ContainerCreateConfig, error) {
+	if spec == nil || spec.Process == nil {
+		return nil, errors.New("[ERR:] [OCI] => THE RUNTIME SPEC HAS NO PROCESS")
+	}
+	cfg := &container.Config{
+		Image:      image,
+		Cmd:        spec.Process.Args,
+		Env:        spec.Process.Env,
+		Tty:        spec.Process.Terminal,
+		WorkingDir: spec.Process.Cwd,
+		Hostname:   spec.Hostname,
+		Labels:     spec.Annotations,
+	}
+	// An empty entrypoint would leave the one of the image in front of the args
+	cfg.Entrypoint = []string{}
+	if cfg.WorkingDir == "/" {
+		cfg.WorkingDir = ""
+	}
+	if user := spec.Process.User; user.UID != 0 || user.GID != 0 {
+		cfg.User = strconv.FormatUint(uint64(user.UID), 10) + ":" + strconv.FormatUint(uint64(user.GID), 10)
+	}
+
+	hc := &container.HostConfig{}
+	if spec.Root != nil {
+		hc.ReadonlyRootfs = spec.Root.Readonly
+	}
+	if spec.Process.NoNewPrivileges {
+		hc.SecurityOpt = append(hc.SecurityOpt, "no-new-privileges")
+	}
+	if spec.Process.Capabilities != nil {
+		hc.CapAdd, hc.CapDrop = capabilityDiff(spec.Process.Capabilities.Bounding)
+	}
+
+	for _, m := range spec.Mounts {
+		if isDefaultMount(m) {
+			continue
+		}
+		switch {
+		case m.Type == "bind" || hasOption(m.Options, "bind") || hasOption(m.Options, "rbind"):
+			hc.Mounts = append(hc.Mounts, mount.Mount{Type: mount.TypeBind, Source: m.Source, Target: m.Destination, ReadOnly: hasOption(m.Options, "ro")})
+		case m.Type == "tmpfs":
+			tmpfs := mount.Mount{Type: mount.TypeTmpfs, Target: m.Destination}
+			for _, option := range m.Options {
+				if size, ok := strings.CutPrefix(option, "size="); ok {
+					if bytes, err := strconv.ParseInt(size, 10, 64); err == nil {
+						tmpfs.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: bytes}
+					}
+				}
+			}
+			hc.Mounts = append(hc.Mounts, tmpfs)
+		default:
+			return nil, errors.New("[ERR:] [OCI] => " + strings.ToUpper(m.Type) + " MOUNT OF " + m.Destination + " CANNOT BE EXPRESSED IN A CONTAINER CONFIG")
+		}
+	}
+
+	if spec.Linux != nil {
+		if r := spec.Linux.Resources; r != nil {
+			if r.Memory != nil && r.Memory.Limit != nil {
+				hc.Memory = *r.Memory.Limit
+			}
+			if r.CPU != nil && r.CPU.Quota != nil && *r.CPU.Quota > 0 {
+				period := int64(cpuPeriod)
+				if r.CPU.Period != nil && *r.CPU.Period > 0 {
+					period = int64(*r.CPU.Period)
+				}
+				hc.NanoCPUs = *r.CPU.Quota * 1e9 / period
+			}
+			if r.Pids != nil && r.Pids.Limit > 0 {
+				limit := r.Pids.Limit
+				hc.PidsLimit = &limit
+			}
+		}
+		namespaces := map[string]bool{}
+		for _, namespace := range spec.Linux.Namespaces {
+			// A namespace joined by path is shared with another process, which a container config cannot name
+			if namespace.Path == "" {
+				namespaces[namespace.Type] = true
+			}
+		}
+		if !namespaces["network"] {
+			hc.NetworkMode = "host"
+		}
+		if !namespaces["pid"] {
+			hc.PidMode = "host"
+		}
+		if !namespaces["ipc"] {
+			hc.IpcMode = "host"
+		}
+	}
+
+	return &containers.ContainerCreateConfig{Name: name, Config: cfg, HostConfig: hc}, nil
+}
+
+// Write ~ Writes a runtime spec as indented config.json content
+func Write(w io.Writer, spec *Spec) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(spec); err != nil {
+		return errors.New("[ERR:] [OCI] => FAILED TO WRITE RUNTIME SPEC => " + err.Error())
+	}
+	return nil
+}
+
+// Read ~ Reads a runtime spec from config.json content
+func Read(r io.Reader) (*Spec, error) {
+	spec := &Spec{}
+	if err := json.NewDecoder(r).Decode(spec); err != nil {
+		return nil, errors.New("[ERR:] [OCI] => FAILED TO READ RUNTIME SPEC => " + err.Error())
+	}
+	return spec, nil
+}
+
+func parseUser(user string) (User, error) {
+	if user == "" {
+		return User{}, nil
+	}
+	uid, gid, hasGroup := strings.Cut(user, ":")
+	parsed := User{}
+	id, err := strconv.ParseUint(uid, 10, 32)
+	if err != nil {
+		return parsed, errors.New("[ERR:] [OCI] => USER " + user + " IS NOT NUMERIC, THE RUNTIME SPEC NEEDS A UID")
+	}
+	parsed.UID = uint32(id)
+	if hasGroup {
+		group, err := strconv.ParseUint(gid, 10, 32)
+		if err != nil {
+			return parsed, errors.New("[ERR:] [OCI] => GROUP OF USER " + user + " IS NOT NUMERIC, THE RUNTIME SPEC NEEDS A GID")
+		}
+		parsed.GID = uint32(group)
+	}
+	return parsed, nil
+}
+
+func bindMount(source string, target string, readOnly bool) Mount {
+	mode := "rw"
+	if readOnly {
+		mode = "ro"
+	}
+	return Mount{Destination: target, Type: "bind", Source: source, Options: []string{"rbind", mode}}
+}
+
+// capabilitySet applies added and dropped capabilities to the default set. "ALL" works in both lists, like for Docker
+func capabilitySet(add []string, drop []string, privileged bool) []string {
+	set := map[string]bool{}
+	for _, capability := range defaultCapabilities {
+		set[capability] = true
+	}
+	for _, capability := range drop {
+		if strings.EqualFold(capability, "ALL") {
+			set = map[string]bool{}
+			continue
+		}
+		delete(set, capabilityName(capability))
+	}
+	for _, capability := range add {
+		if strings.EqualFold(capability, "ALL") {
+			privileged = true
+			continue
+		}
+		set[capabilityName(capability)] = true
+	}
+	if privileged {
+		for _, capability := range allCapabilities {
+			set[capability] = true
+		}
+	}
+	caps := make([]string, 0, len(set))
+	for capability := range set {
+		caps = append(caps, capability)
+	}
+	sort.Strings(caps)
+	return caps
+}
+
+// capabilityDiff expresses a capability set as additions to and removals from the default set
+func capabilityDiff(caps []string) ([]string, []string) {
+	have := map[string]bool{}
+	for _, capability := range caps {
+		have[capabilityName(capability)] = true
+	}
+	all := true
+	for _, capability := range allCapabilities {
+		all = all && have[capability]
+	}
+	if all {
+		return []string{"ALL"}, nil
+	}
+	var add, drop []string
+	for capability := range have {
+		if !contains(defaultCapabilities, capability) {
+			add = append(add, strings.TrimPrefix(capability, "CAP_"))
+		}
+	}
+	for _, capability := range defaultCapabilities {
+		if !have[capability] {
+			drop = append(drop, strings.TrimPrefix(capability, "CAP_"))
+		}
+	}
+	sort.Strings(add)
+	return add, drop
+}
+
+func capabilityName(capability string) string {
+	capability = strings.ToUpper(capability)
+	if !strings.HasPrefix(capability, "CAP_") {
+		capability = "CAP_" + capability
+	}
+	return capability
+}
+
+func isDefaultMount(m Mount) bool {
+	for _, d := range defaultMounts {
+		if d.Destination == m.Destination && d.Type == m.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSecurityOpt(opts []string, name string) bool {
+	for _, opt := range opts {
+		if opt == name || strings.HasPrefix(opt, name+"=true") || strings.HasPrefix(opt, name+":true") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(options []string, option string) bool {
+	return contains(options, option)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
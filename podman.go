@@ -0,0 +1,132 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+var (
+	podmanMu      sync.RWMutex
+	podmanEnabled bool
+)
+
+// PodmanSocket ~ Returns the Podman socket to connect to: CONTAINER_HOST if set, else the rootless socket of the current user,
+// else the system socket. The rootless and system sockets are only returned if they exist, an empty string means none was found
+func PodmanSocket() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	candidates := []string{}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	candidates = append(candidates, "/run/podman/podman.sock")
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return "unix://" + candidate
+		}
+	}
+	return ""
+}
+
+// InitializePodmanClient ~ Initializes the docker client against the Docker compatible API of Podman and turns Podman mode on.
+// socket is a host such as unix:///run/podman/podman.sock, a bare path is taken as a unix socket. An empty socket is looked up with PodmanSocket
+func InitializePodmanClient(socket string) error {
+	if socket == "" {
+		socket = PodmanSocket()
+	}
+	if socket == "" {
+		return errors.New("[ERR:] [PODMAN] => NO PODMAN SOCKET FOUND, START podman.socket OR SET CONTAINER_HOST")
+	}
+	if strings.HasPrefix(socket, "/") {
+		socket = "unix://" + socket
+	}
+
+	cli, err := client.NewClientWithOpts(
+		client.WithHost(socket),
+		client.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return errors.New("[ERR:] [PODMAN] => FAILED TO INITIALIZE PODMAN CLIENT! => " + err.Error())
+	}
+	DockerClient = cli
+	setPodman(true)
+	return nil
+}
+
+// DetectPodman ~ Asks the daemon DockerClient is connected to whether it is Podman and turns Podman mode on or off accordingly.
+// Useful after InitializeDockerClient when DOCKER_HOST may point to a Podman socket
+func DetectPodman(ctx context.Context) (bool, error) {
+	version, err := retryResult(ctx, func() (types.Version, error) {
+		return DockerClient.ServerVersion(ctx)
+	})
+	if err != nil {
+		return false, errors.New("[ERR:] [PODMAN] => FAILED TO GET SERVER VERSION => " + err.Error())
+	}
+	isPodman := false
+	for _, component := range version.Components {
+		if strings.Contains(strings.ToLower(component.Name), "podman") {
+			isPodman = true
+			break
+		}
+	}
+	setPodman(isPodman)
+	return isPodman, nil
+}
+
+// IsPodman ~ Reports whether Podman mode is on
+func IsPodman() bool {
+	podmanMu.RLock()
+	defer podmanMu.RUnlock()
+	return podmanEnabled
+}
+
+func setPodman(enabled bool) {
+	podmanMu.Lock()
+	defer podmanMu.Unlock()
+	podmanEnabled = enabled
+}
+
+// adaptCreateConfig drops the fields the Podman compat API rejects. The healthcheck start interval needs API 1.44, which Podman does not
+// speak, so the client would refuse to create the container. The config is copied, the caller's one is left untouched
+func adaptCreateConfig(config *ContainerCreateConfig) *ContainerCreateConfig {
+	if !IsPodman() || config.Config == nil || config.Config.Healthcheck == nil || config.Config.Healthcheck.StartInterval == 0 {
+		return config
+	}
+	adapted := *config
+	containerConfig := *config.Config
+	healthcheck := *config.Config.Healthcheck
+	healthcheck.StartInterval = 0
+	containerConfig.Healthcheck = &healthcheck
+	adapted.Config = &containerConfig
+	return &adapted
+}
+
+// inspectContainer inspects a container. In Podman mode the health of older Podman releases, which report it under State.Healthcheck
+// instead of State.Health, is moved to State.Health so callers only look in one place
+func inspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	if !IsPodman() {
+		return DockerClient.ContainerInspect(ctx, containerID)
+	}
+	containerJSON, raw, err := DockerClient.ContainerInspectWithRaw(ctx, containerID, false)
+	if err != nil || containerJSON.State == nil || containerJSON.State.Health != nil {
+		return containerJSON, err
+	}
+	var legacy struct {
+		State struct {
+			Healthcheck *types.Health
+		}
+	}
+	if json.Unmarshal(raw, &legacy) == nil && legacy.State.Healthcheck != nil {
+		containerJSON.State.Health = legacy.State.Healthcheck
+	}
+	return containerJSON, nil
+}
@@ -0,0 +1,307 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// PoolLabel ~ The label a Pool stamps on its workers, valued with the pool name
+const PoolLabel = "com.github.g-makroglou.containers.pool"
+
+// Pool ~ A set of identical, running worker containers handed out one caller at a time, e.g. sandboxes for untrusted code
+type Pool struct {
+	name    string
+	config  ContainerCreateConfig
+	opts    PoolOptions
+	mu      sync.Mutex
+	idle    []poolWorker
+	busy    map[string]struct{}
+	total   int
+	seq     int
+	closed  bool
+	changed chan struct{}
+	done    chan struct{}
+}
+
+type poolWorker struct {
+	id       string
+	lastUsed time.Time
+}
+
+// NewPool ~ Creates a pool of workers created from config and starts its Min workers. config.Name is ignored, workers are named "<name>-worker-<n>"
+func NewPool(ctx context.Context, name string, config ContainerCreateConfig, opts PoolOptions) (*Pool, error) {
+	if opts.Max < opts.Min {
+		opts.Max = opts.Min
+	}
+	if opts.Max < 1 {
+		return nil, errors.New("[ERR:] [POOL] => POOL " + name + " NEEDS A MAX OF AT LEAST ONE WORKER")
+	}
+	if config.Config != nil && config.Config.Image != "" {
+		if err := EnsureImage(ctx, config.Config.Image); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Pool{
+		name:    name,
+		config:  config,
+		opts:    opts,
+		busy:    map[string]struct{}{},
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < opts.Min; i++ {
+		id, err := p.createWorker(ctx)
+		if err != nil {
+			p.Close(ctx)
+			return nil, err
+		}
+		p.total++
+		p.idle = append(p.idle, poolWorker{id: id, lastUsed: time.Now()})
+	}
+	if opts.IdleTimeout > 0 {
+		go p.expireIdle()
+	}
+	return p, nil
+}
+
+// Acquire ~ Hands out a running worker, replacing idle workers that crashed and growing the pool up to Max. Blocks until a worker is
+// released or the context is done when all Max workers are busy
+func (p *Pool) Acquire(ctx context.Context) (string, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return "", errors.New("[ERR:] [POOL] => POOL " + p.name + " IS CLOSED")
+		}
+
+		if n := len(p.idle); n > 0 {
+			worker := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.mu.Unlock()
+
+			id, err := p.ensureRunning(ctx, worker.id)
+			return p.markBusy(ctx, id, err)
+		}
+
+		if p.total < p.opts.Max {
+			p.total++
+			p.mu.Unlock()
+
+			id, err := p.createWorker(ctx)
+			return p.markBusy(ctx, id, err)
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return "", errors.New("[ERR:] [POOL] => NO WORKER OF POOL " + p.name + " BECAME AVAILABLE => " + ctx.Err().Error())
+		case <-changed:
+		}
+	}
+}
+
+// Release ~ Returns a worker to the pool. Recycled and crashed workers are replaced with a fresh container
+func (p *Pool) Release(ctx context.Context, containerID string) error {
+	p.mu.Lock()
+	if p.closed {
+		// Close purged the busy workers already
+		p.mu.Unlock()
+		return nil
+	}
+	if _, ok := p.busy[containerID]; !ok {
+		p.mu.Unlock()
+		return errors.New("[ERR:] [POOL] => CONTAINER WITH ID: " + containerID + " IS NOT A BUSY WORKER OF POOL " + p.name)
+	}
+	delete(p.busy, containerID)
+	p.mu.Unlock()
+
+	var id string
+	var err error
+	if p.opts.Recycle {
+		id, err = p.replaceWorker(ctx, containerID)
+	} else {
+		id, err = p.ensureRunning(ctx, containerID)
+	}
+
+	p.mu.Lock()
+	var expired []string
+	switch {
+	case p.closed:
+		// Close reset the count already
+		if err == nil {
+			expired = append(expired, id)
+		}
+	case err != nil:
+		p.total--
+	default:
+		p.idle = append(p.idle, poolWorker{id: id, lastUsed: time.Now()})
+	}
+	p.broadcastLocked()
+	expired = append(expired, p.expiredLocked()...)
+	p.mu.Unlock()
+
+	for _, worker := range expired {
+		PurgeContainerContext(ctx, worker)
+	}
+	return err
+}
+
+// Size ~ Returns the number of idle and busy workers
+func (p *Pool) Size() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle), len(p.busy)
+}
+
+// Close ~ Purges the idle workers and every busy worker. Acquire fails from now on and Release does nothing
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		close(p.done)
+	}
+	p.closed = true
+	workers := make([]string, 0, len(p.idle)+len(p.busy))
+	for _, worker := range p.idle {
+		workers = append(workers, worker.id)
+	}
+	for id := range p.busy {
+		workers = append(workers, id)
+	}
+	p.idle = nil
+	p.busy = map[string]struct{}{}
+	p.total = 0
+	p.broadcastLocked()
+	p.mu.Unlock()
+
+	var failures []string
+	for _, id := range workers {
+		if err := PurgeContainerContext(ctx, id); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [POOL] => FAILED TO CLOSE POOL " + p.name + " => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// markBusy records an acquired worker. A failed acquisition gives its slot back, a worker acquired while the pool was closed is purged
+func (p *Pool) markBusy(ctx context.Context, id string, err error) (string, error) {
+	p.mu.Lock()
+	if p.closed {
+		// Close reset the count already
+		p.mu.Unlock()
+		if err == nil {
+			PurgeContainerContext(context.WithoutCancel(ctx), id)
+		}
+		return "", errors.New("[ERR:] [POOL] => POOL " + p.name + " IS CLOSED")
+	}
+	defer p.mu.Unlock()
+	if err != nil {
+		p.total--
+		p.broadcastLocked()
+		return "", err
+	}
+	p.busy[id] = struct{}{}
+	return id, nil
+}
+
+// ensureRunning returns the worker if it is still running and a fresh replacement otherwise
+func (p *Pool) ensureRunning(ctx context.Context, containerID string) (string, error) {
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err == nil && containerJSON.State != nil && containerJSON.State.Running {
+		return containerID, nil
+	}
+	return p.replaceWorker(ctx, containerID)
+}
+
+// replaceWorker purges a worker and creates a fresh one in its place
+func (p *Pool) replaceWorker(ctx context.Context, containerID string) (string, error) {
+	PurgeContainerContext(ctx, containerID)
+	return p.createWorker(ctx)
+}
+
+// createWorker creates and starts a worker container
+func (p *Pool) createWorker(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	p.seq++
+	name := p.name + "-worker-" + fmt.Sprint(p.seq)
+	p.mu.Unlock()
+
+	config := p.config
+	config.Name = name
+	containerConfig := container.Config{}
+	if config.Config != nil {
+		containerConfig = *config.Config
+	}
+	labels := make(map[string]string, len(containerConfig.Labels)+1)
+	for key, value := range containerConfig.Labels {
+		labels[key] = value
+	}
+	labels[PoolLabel] = p.name
+	containerConfig.Labels = labels
+	config.Config = &containerConfig
+
+	cont, err := CreateContainerContext(ctx, &config)
+	if err != nil {
+		return "", err
+	}
+	if err := StartContainerContext(ctx, cont); err != nil {
+		PurgeContainerContext(context.WithoutCancel(ctx), cont.ID)
+		return "", err
+	}
+	return cont.ID, nil
+}
+
+// expireIdle removes the idle workers that expired every half IdleTimeout until the pool is closed, so a pool nobody uses shrinks
+// back to Min
+func (p *Pool) expireIdle() {
+	ticker := time.NewTicker(p.opts.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		p.mu.Lock()
+		expired := p.expiredLocked()
+		p.mu.Unlock()
+		for _, worker := range expired {
+			PurgeContainer(worker)
+		}
+	}
+}
+
+// expiredLocked takes the idle workers unused for IdleTimeout out of the pool, as long as more than Min workers exist
+func (p *Pool) expiredLocked() []string {
+	if p.opts.IdleTimeout == 0 {
+		return nil
+	}
+	var expired []string
+	kept := p.idle[:0]
+	for _, worker := range p.idle {
+		if p.total > p.opts.Min && time.Since(worker.lastUsed) > p.opts.IdleTimeout {
+			expired = append(expired, worker.id)
+			p.total--
+			continue
+		}
+		kept = append(kept, worker)
+	}
+	p.idle = kept
+	return expired
+}
+
+// broadcastLocked wakes every Acquire waiting for a worker
+func (p *Pool) broadcastLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
@@ -0,0 +1,91 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// PublishPort ~ Publishes ports of a container from a docker style spec, e.g. "8080:80", "127.0.0.1:8080:80/udp",
+// "80" for a random host port or "8000-8010:8000-8010" for a range. Fills both ExposedPorts and PortBindings
+func PublishPort(config *ContainerCreateConfig, spec string) error {
+	mappings, err := nat.ParsePortSpec(spec)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => INVALID PORT SPEC " + spec + " => " + err.Error())
+	}
+
+	ensureContainerConfigs(config)
+	if config.Config.ExposedPorts == nil {
+		config.Config.ExposedPorts = nat.PortSet{}
+	}
+	if config.HostConfig.PortBindings == nil {
+		config.HostConfig.PortBindings = nat.PortMap{}
+	}
+	for _, mapping := range mappings {
+		config.Config.ExposedPorts[mapping.Port] = struct{}{}
+		config.HostConfig.PortBindings[mapping.Port] = append(config.HostConfig.PortBindings[mapping.Port], mapping.Binding)
+	}
+	return nil
+}
+
+// PublishRandomPort ~ Publishes a container port (e.g. "5432/tcp") on a host port picked by the daemon
+func PublishRandomPort(config *ContainerCreateConfig, containerPort string) error {
+	return PublishPort(config, containerPort)
+}
+
+// GetHostPort ~ Returns the host port a container port (e.g. "5432/tcp") was published on, useful to read back random host ports after start
+func GetHostPort(ctx context.Context, containerID string, containerPort string) (string, error) {
+	address, err := resolveHostAddress(ctx, containerID, containerPort)
+	if err != nil {
+		return "", err
+	}
+	if address == "" {
+		return "", errors.New("[ERR:] [DOCKER] => PORT " + containerPort + " OF CONTAINER WITH ID: " + containerID + " IS NOT PUBLISHED")
+	}
+	_, hostPort, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => INVALID HOST ADDRESS " + address + " => " + err.Error())
+	}
+	return hostPort, nil
+}
+
+// resolveHostAddress finds the host ip:port a container port is published on, or "" if it is not published yet
+func resolveHostAddress(ctx context.Context, containerID string, containerPort string) (string, error) {
+	port, err := nat.NewPort(nat.SplitProtoPort(containerPort))
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => INVALID CONTAINER PORT " + containerPort + " => " + err.Error())
+	}
+
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	if containerJSON.NetworkSettings == nil {
+		return "", nil
+	}
+
+	for _, binding := range containerJSON.NetworkSettings.Ports[port] {
+		if binding.HostPort == "" {
+			continue
+		}
+		hostIP := binding.HostIP
+		if hostIP == "" || hostIP == "0.0.0.0" || hostIP == "::" {
+			hostIP = "localhost"
+		}
+		return net.JoinHostPort(hostIP, binding.HostPort), nil
+	}
+	return "", nil
+}
+
+// ensureContainerConfigs allocates the config structs helpers write into
+func ensureContainerConfigs(config *ContainerCreateConfig) {
+	if config.Config == nil {
+		config.Config = &container.Config{}
+	}
+	if config.HostConfig == nil {
+		config.HostConfig = &container.HostConfig{}
+	}
+}
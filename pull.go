@@ -0,0 +1,301 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+)
+
+const (
+	// PullAlways ~ The image is pulled before every container create, picking up a moved tag
+	PullAlways = "Always"
+	// PullIfNotPresent ~ The image is pulled only when it is missing locally
+	PullIfNotPresent = "IfNotPresent"
+	// PullNever ~ The image is never pulled and creating a container fails when it is missing locally
+	PullNever = "Never"
+)
+
+// pullLimits ~ The throttling applied to every image pull, none until SetPullLimits is called
+var (
+	pullLimitsMu sync.RWMutex
+	pullLimits   PullLimits
+	pullBucket   = &tokenBucket{}
+)
+
+// SetPullLimits ~ Sets the throttling and rate limit backoff of image pulls, see SetRegistryMirrors for mirrors
+func SetPullLimits(limits PullLimits) {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	if limits.Backoff == 0 {
+		limits.Backoff = 30 * time.Second
+	}
+	if limits.MaxBackoff == 0 {
+		limits.MaxBackoff = 5 * time.Minute
+	}
+
+	pullLimitsMu.Lock()
+	defer pullLimitsMu.Unlock()
+	pullLimits = limits
+	pullBucket = &tokenBucket{rate: limits.Rate, burst: float64(limits.Burst), tokens: float64(limits.Burst), last: time.Now()}
+}
+
+// registryMirrors ~ The mirrors pulls from a registry go to first, by registry host, see SetRegistryMirrors
+var (
+	registryMirrorsMu sync.RWMutex
+	registryMirrors   = map[string][]string{}
+)
+
+// SetRegistryMirrors ~ Makes pulls of images from registry (a host such as docker.io or ghcr.io) try mirrors first, in order, before
+// the registry itself, which is also how Docker Hub pull quotas are spared (e.g. SetRegistryMirrors("docker.io", "mirror.gcr.io")).
+// A mirror is a registry host with an optional path prefix, e.g. proxy.internal/dockerhub, and pulled images are tagged with their
+// original names. Images pinned by digest always come from the registry itself. No mirrors removes the mapping
+func SetRegistryMirrors(registry string, mirrors ...string) {
+	registry = registryHost(registry)
+	registryMirrorsMu.Lock()
+	defer registryMirrorsMu.Unlock()
+	if len(mirrors) == 0 {
+		delete(registryMirrors, registry)
+		return
+	}
+	registryMirrors[registry] = append([]string(nil), mirrors...)
+}
+
+// rateLimitError ~ A pull the registry refused with HTTP 429, as reported in the pull progress stream
+type rateLimitError struct {
+	message string
+}
+
+func (e *rateLimitError) Error() string {
+	return e.message
+}
+
+// IsRateLimitError ~ Reports whether a registry refused a pull because a rate limit such as Docker Hub's pull quota was hit. The daemon
+// hands registry errors on as text, so apart from a 429 status in the pull stream the TOOMANYREQUESTS error code of the registry API
+// and the "429 Too Many Requests" status line are recognized. Other numbers, such as a digest or a size containing 429, are not
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rateLimited *rateLimitError
+	if errors.As(err, &rateLimited) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "toomanyrequests") || strings.Contains(message, "429 too many requests")
+}
+
+// applyPullPolicy makes sure an image is present for a container create according to policy. An empty policy leaves the image to
+// the daemon, which fails the create when it is missing
+func applyPullPolicy(ctx context.Context, imageName string, policy string) error {
+	switch policy {
+	case "":
+		return nil
+	case PullAlways:
+		return PullImage(ctx, imageName)
+	case PullIfNotPresent:
+		return EnsureImage(ctx, imageName)
+	case PullNever:
+		if _, _, err := DockerClient.ImageInspectWithRaw(ctx, imageName); err != nil {
+			return errors.New("[ERR:] [DOCKER] => IMAGE " + imageName + " IS NOT PRESENT AND THE PULL POLICY IS " + PullNever + " => " + err.Error())
+		}
+		return nil
+	}
+	return errors.New("[ERR:] [DOCKER] => UNKNOWN PULL POLICY " + policy + " FOR IMAGE " + imageName)
+}
+
+// PullImage ~ Pulls an image even if it is present already. References to a registry with mirrors set by SetRegistryMirrors are
+// rewritten to each mirror in turn, the first mirror that has the image wins and the image is tagged with its original name. When no
+// mirror has it the registry itself is pulled from, throttled by SetPullLimits and backing off on rate limits
+func PullImage(ctx context.Context, imageName string) error {
+	if dryRun(OpPullImage, imageName, imageName) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpPullImage, imageName, imageName)
+	err := pullWithLimits(ctx, imageName)
+	done(err)
+	return err
+}
+
+// pullWithLimits does the work of PullImage. The mirrors of the registry of the image are tried first, the registry itself last
+func pullWithLimits(ctx context.Context, imageName string) error {
+	pullLimitsMu.RLock()
+	limits := pullLimits
+	bucket := pullBucket
+	pullLimitsMu.RUnlock()
+
+	mirrored, mirrorFailures, err := pullFromMirrors(ctx, bucket, imageName)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO PULL IMAGE " + imageName + " => " + err.Error())
+	}
+	if mirrored {
+		return nil
+	}
+
+	backoff := limits.Backoff
+	for attempt := 0; ; attempt++ {
+		if waitErr := bucket.wait(ctx); waitErr != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO PULL IMAGE " + imageName + " => " + waitErr.Error())
+		}
+		err = pullOnce(ctx, imageName)
+		if err == nil {
+			return nil
+		}
+		if !IsRateLimitError(err) || attempt >= limits.MaxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("[ERR:] [DOCKER] => FAILED TO PULL IMAGE " + imageName + " => " + err.Error())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > limits.MaxBackoff {
+			backoff = limits.MaxBackoff
+		}
+	}
+	return errors.New("[ERR:] [DOCKER] => FAILED TO PULL IMAGE " + imageName + " => " + err.Error() + mirrorFailures)
+}
+
+// pullFromMirrors tries the mirrors set for the registry of an image in order and tags the first image pulled with its original
+// name. Reports whether a mirror had the image and what the others failed with, the error is only set when ctx ends while waiting
+func pullFromMirrors(ctx context.Context, bucket *tokenBucket, imageName string) (bool, string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return false, "", nil
+	}
+	registry := reference.Domain(named)
+	registryMirrorsMu.RLock()
+	mirrors := registryMirrors[registry]
+	registryMirrorsMu.RUnlock()
+
+	failures := ""
+	for _, mirror := range mirrors {
+		mirrorRef, ok := mirrorReference(mirror, imageName, registry)
+		if !ok {
+			break
+		}
+		if err := bucket.wait(ctx); err != nil {
+			return false, failures, err
+		}
+		err := pullOnce(ctx, mirrorRef)
+		if err == nil {
+			// Callers refer to the image by its original name
+			err = tagImage(ctx, mirrorRef, imageName)
+		}
+		if err != nil {
+			failures += " | MIRROR " + mirror + " => " + err.Error()
+			continue
+		}
+		return true, "", nil
+	}
+	return false, failures, nil
+}
+
+// pullOnce pulls an image, with the credentials of ConfigureRegistry if any, and drains its progress stream, which is where the
+// registry reports most failures. Images of registries configured with a CA bundle or as insecure are pulled by the package itself
+func pullOnce(ctx context.Context, imageName string) error {
+	if r := registryFor(imageName); r != nil && r.transport != nil {
+		return r.pull(ctx, imageName)
+	}
+	auth, err := registryAuth(imageName)
+	if err != nil {
+		return err
+	}
+	pull, err := retryResult(ctx, func() (io.ReadCloser, error) {
+		return DockerClient.ImagePull(ctx, imageName, image.PullOptions{RegistryAuth: auth})
+	})
+	if err != nil {
+		return err
+	}
+	defer pull.Close()
+
+	// The pull only completes once its progress stream is drained
+	decoder := json.NewDecoder(pull)
+	for {
+		var pullOut ImageBuildOut
+		if err := decoder.Decode(&pullOut); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message := pullOut.errorMessage(); message != "" {
+			if pullOut.ErrorDetail.Code == http.StatusTooManyRequests {
+				return &rateLimitError{message: message}
+			}
+			return errors.New(message)
+		}
+	}
+}
+
+// tagImage tags source as target
+func tagImage(ctx context.Context, source string, target string) error {
+	if dryRun(OpTagImage, target, source) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpTagImage, target, source)
+	err := DockerClient.ImageTag(ctx, source, target)
+	done(err)
+	return err
+}
+
+// mirrorReference rewrites an image reference of registry to the same repository on a mirror. Images of other registries have no
+// mirror, and neither do images pinned by digest: the daemon only finds those under the repository they were pulled from
+func mirrorReference(mirror string, imageName string, registry string) (string, bool) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil || reference.Domain(named) != registry {
+		return "", false
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return "", false
+	}
+	named = reference.TagNameOnly(named)
+	return strings.TrimSuffix(mirror, "/") + "/" + reference.Path(named) + ":" + named.(reference.Tagged).Tag(), true
+}
+
+// tokenBucket ~ Admits rate events per second with bursts of burst. A zero rate admits everything
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.rate <= 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
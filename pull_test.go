@@ -0,0 +1,88 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		bucket  *tokenBucket
+		waits   int
+		minimum time.Duration
+	}{
+		{"unthrottled", &tokenBucket{}, 100, 0},
+		{"within burst", &tokenBucket{rate: 1, burst: 3, tokens: 3, last: time.Now()}, 3, 0},
+		{"beyond burst", &tokenBucket{rate: 20, burst: 1, tokens: 1, last: time.Now()}, 3, 90 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			for i := 0; i < tt.waits; i++ {
+				if err := tt.bucket.wait(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			elapsed := time.Since(start)
+			if elapsed < tt.minimum || elapsed > tt.minimum+time.Second {
+				t.Errorf("waited %v, want about %v", elapsed, tt.minimum)
+			}
+		})
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	bucket := &tokenBucket{rate: 0.001, burst: 1, last: time.Now()}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&rateLimitError{message: "quota exceeded"}, true},
+		{fmt.Errorf("pull: %w", &rateLimitError{message: "quota exceeded"}), true},
+		{errors.New("toomanyrequests: You have reached your pull rate limit"), true},
+		{errors.New("unexpected status: 429 Too Many Requests"), true},
+		{errors.New("pull access denied for app"), false},
+		{errors.New("layer sha256:4291aa size 429 failed"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRateLimitError(tt.err); got != tt.want {
+			t.Errorf("IsRateLimitError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+func TestMirrorReference(t *testing.T) {
+	tests := []struct {
+		mirror   string
+		image    string
+		registry string
+		want     string
+		ok       bool
+	}{
+		{"mirror.local", "nginx", "docker.io", "mirror.local/library/nginx:latest", true},
+		{"mirror.local/", "nginx:1.25", "docker.io", "mirror.local/library/nginx:1.25", true},
+		{"mirror.local:5000", "grafana/grafana:10", "docker.io", "mirror.local:5000/grafana/grafana:10", true},
+		{"mirror.local", "ghcr.io/org/app:v1", "ghcr.io", "mirror.local/org/app:v1", true},
+		{"mirror.local", "ghcr.io/org/app:v1", "docker.io", "", false},
+		{"mirror.local", "nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000", "docker.io", "", false},
+		{"mirror.local", "Invalid:Ref", "docker.io", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := mirrorReference(tt.mirror, tt.image, tt.registry)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("mirrorReference(%q, %q, %q) = %q, %v, want %q, %v", tt.mirror, tt.image, tt.registry, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ProjectLabel ~ The label a Reconciler stamps on its containers to find them again, valued with the project name
+const ProjectLabel = "com.github.g-makroglou.containers.project"
+
+const (
+	// ActionCreate ~ The container does not exist and is created and started
+	ActionCreate = "create"
+	// ActionRecreate ~ The container drifted from its spec and is stopped, purged, created and started
+	ActionRecreate = "recreate"
+	// ActionStart ~ The container matches its spec but is not running
+	ActionStart = "start"
+	// ActionRemove ~ The container belongs to the project but has no spec anymore
+	ActionRemove = "remove"
+	// ActionNone ~ The container matches its spec and is running
+	ActionNone = "none"
+)
+
+// Reconciler ~ Converges the containers of a project to a list of desired specs, a minimal single host orchestrator
+type Reconciler struct {
+	Project string
+}
+
+// NewReconciler ~ Creates a reconciler owning the containers labelled with ProjectLabel=project
+func NewReconciler(project string) *Reconciler {
+	return &Reconciler{Project: project}
+}
+
+// Plan ~ Computes the changes Apply would make without touching any container. Changes are ordered so that dependencies come first
+func (r *Reconciler) Plan(ctx context.Context, desired []ContainerSpec) ([]PlannedChange, error) {
+	existing, err := r.existingContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, spec := range desired {
+		if spec.Name == "" {
+			return nil, errors.New("[ERR:] [RECONCILE] => EVERY SPEC OF PROJECT " + r.Project + " NEEDS A NAME")
+		}
+		if seen[spec.Name] {
+			return nil, errors.New("[ERR:] [RECONCILE] => DUPLICATE SPEC " + spec.Name + " IN PROJECT " + r.Project)
+		}
+		seen[spec.Name] = true
+	}
+	ordered, err := orderSpecs(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedChange
+	for _, spec := range ordered {
+
+		config := r.stamp(spec.ContainerCreateConfig)
+		current, ok := existing[spec.Name]
+		switch {
+		case !ok:
+			plan = append(plan, PlannedChange{Name: spec.Name, Action: ActionCreate})
+		default:
+			if changes := diffContainerConfig(current, config); len(changes) > 0 {
+				plan = append(plan, PlannedChange{Name: spec.Name, ContainerID: current.ID, Action: ActionRecreate, Reasons: changes})
+			} else if current.State == nil || !current.State.Running {
+				plan = append(plan, PlannedChange{Name: spec.Name, ContainerID: current.ID, Action: ActionStart})
+			} else {
+				plan = append(plan, PlannedChange{Name: spec.Name, ContainerID: current.ID, Action: ActionNone})
+			}
+		}
+	}
+
+	var orphans []string
+	for name := range existing {
+		if !seen[name] {
+			orphans = append(orphans, name)
+		}
+	}
+	sort.Strings(orphans)
+	for _, name := range orphans {
+		plan = append(plan, PlannedChange{Name: name, ContainerID: existing[name].ID, Action: ActionRemove})
+	}
+	return plan, nil
+}
+
+// Apply ~ Creates, recreates, starts and removes containers until the project matches desired, holding back every container until
+// its dependencies meet their conditions. Returns the plan that was executed
+func (r *Reconciler) Apply(ctx context.Context, desired []ContainerSpec) ([]PlannedChange, error) {
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make(map[string]ContainerSpec, len(desired))
+	for _, spec := range desired {
+		specs[spec.Name] = spec
+	}
+
+	ids := map[string]string{}
+	for _, change := range plan {
+		spec := specs[change.Name]
+		for dependency, condition := range spec.DependsOn {
+			if err := waitForDependency(ctx, dependency, ids[dependency], condition); err != nil {
+				return plan, errors.New("[ERR:] [RECONCILE] => CANNOT START " + change.Name + " => " + err.Error())
+			}
+		}
+		id, err := r.execute(ctx, change, spec)
+		if err != nil {
+			return plan, err
+		}
+		ids[change.Name] = id
+	}
+	return plan, nil
+}
+
+// execute carries out a single planned change and returns the ID of the resulting container
+func (r *Reconciler) execute(ctx context.Context, change PlannedChange, spec ContainerSpec) (string, error) {
+	switch change.Action {
+	case ActionStart:
+		return change.ContainerID, StartContainerContext(ctx, container.CreateResponse{ID: change.ContainerID})
+	case ActionRemove, ActionRecreate:
+		if err := StopContainerContext(ctx, change.ContainerID); err != nil {
+			return change.ContainerID, err
+		}
+		if err := PurgeContainerContext(ctx, change.ContainerID); err != nil {
+			return change.ContainerID, err
+		}
+		if change.Action == ActionRemove {
+			return "", nil
+		}
+		fallthrough
+	case ActionCreate:
+		cont, err := CreateContainerContext(ctx, r.stamp(spec.ContainerCreateConfig))
+		if err != nil {
+			return "", err
+		}
+		return cont.ID, StartContainerContext(ctx, cont)
+	}
+	return change.ContainerID, nil
+}
+
+// existingContainers inspects every container of the project, keyed by name
+func (r *Reconciler) existingContainers(ctx context.Context) (map[string]types.ContainerJSON, error) {
+	containerList, err := DockerClient.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", ProjectLabel+"="+r.Project)),
+	})
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO LIST CONTAINERS OF PROJECT " + r.Project + " => " + err.Error())
+	}
+
+	existing := make(map[string]types.ContainerJSON, len(containerList))
+	for _, c := range containerList {
+		containerJSON, err := DockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + c.ID + " => " + err.Error())
+		}
+		existing[strings.TrimPrefix(containerJSON.Name, "/")] = containerJSON
+	}
+	return existing, nil
+}
+
+// stamp returns a copy of config labelled as part of the project
+func (r *Reconciler) stamp(config ContainerCreateConfig) *ContainerCreateConfig {
+	stamped := config
+	containerConfig := container.Config{}
+	if config.Config != nil {
+		containerConfig = *config.Config
+	}
+	labels := make(map[string]string, len(containerConfig.Labels)+1)
+	for key, value := range containerConfig.Labels {
+		labels[key] = value
+	}
+	labels[ProjectLabel] = r.Project
+	containerConfig.Labels = labels
+	stamped.Config = &containerConfig
+	return &stamped
+}
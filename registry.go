@@ -0,0 +1,298 @@
+package containers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/opencontainers/go-digest"
+)
+
+// registries ~ The registries configured with ConfigureRegistry, by host
+var (
+	registriesMu sync.RWMutex
+	registries   = map[string]*registryClient{}
+)
+
+// registryClient ~ A registry configured with ConfigureRegistry. transport is set when the package speaks to the registry itself
+type registryClient struct {
+	cfg       RegistryConfig
+	transport http.RoundTripper
+}
+
+// ConfigureRegistry ~ Sets how images of the registry at host (e.g. "registry.internal:5000") are pulled and pushed. Credentials alone
+// are handed to the daemon, so callers don't depend on a `docker login` of the daemon host. With a CAFile, trusted on top of the
+// system roots, or Insecure, which allows plain HTTP, the package pulls and pushes through the registry API itself and moves images to
+// and from the daemon as archives, so registries with self-signed certificates or without TLS work without certs.d or
+// insecure-registries entries on the daemon host. Such images can only be pulled by tag
+func ConfigureRegistry(host string, cfg RegistryConfig) error {
+	if host == "" {
+		return errors.New("[ERR:] [REGISTRY] => NO REGISTRY HOST GIVEN")
+	}
+	r := &registryClient{cfg: cfg}
+	if cfg.CAFile != "" || cfg.Insecure {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return errors.New("[ERR:] [REGISTRY] => FAILED TO READ CA BUNDLE " + cfg.CAFile + " => " + err.Error())
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return errors.New("[ERR:] [REGISTRY] => NO CERTIFICATES FOUND IN CA BUNDLE " + cfg.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		r.transport = transport
+	}
+
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+	registries[registryHost(host)] = r
+	return nil
+}
+
+// RemoveRegistryConfig ~ Hands the registry at host back to the daemon and stops sending credentials to it
+func RemoveRegistryConfig(host string) {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+	delete(registries, registryHost(host))
+}
+
+// PushImage ~ Pushes an image to its registry, through the daemon with the credentials of ConfigureRegistry if any, or by the package
+// itself for registries configured with a CA bundle or as insecure
+func PushImage(ctx context.Context, imageName string) error {
+	if dryRun(OpPushImage, imageName, imageName) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpPushImage, imageName, imageName)
+	err := pushImage(ctx, imageName)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO PUSH IMAGE " + imageName + " => " + err.Error())
+	}
+	return nil
+}
+
+// pushImage does the work of PushImage
+func pushImage(ctx context.Context, imageName string) error {
+	if r := registryFor(imageName); r != nil && r.transport != nil {
+		return r.push(ctx, imageName)
+	}
+	auth, err := registryAuth(imageName)
+	if err != nil {
+		return err
+	}
+	push, err := DockerClient.ImagePush(ctx, imageName, image.PushOptions{RegistryAuth: auth})
+	if err != nil {
+		return err
+	}
+	defer push.Close()
+	return drainProgress(push)
+}
+
+// drainProgress reads a progress stream of the daemon to its end, returning the first error it reports
+func drainProgress(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var out ImageBuildOut
+		if err := decoder.Decode(&out); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message := out.errorMessage(); message != "" {
+			return errors.New(message)
+		}
+	}
+}
+
+// registryFor returns the configured registry an image lives on, nil when it has none
+func registryFor(imageName string) *registryClient {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return nil
+	}
+	registriesMu.RLock()
+	defer registriesMu.RUnlock()
+	return registries[reference.Domain(named)]
+}
+
+// registryAuth returns the encoded credentials configured for the registry of an image, empty when there are none
+func registryAuth(imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", nil
+	}
+	r := registryFor(imageName)
+	if r == nil {
+		return "", nil
+	}
+	return registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      r.cfg.Username,
+		Password:      r.cfg.Password,
+		IdentityToken: r.cfg.IdentityToken,
+		ServerAddress: reference.Domain(named),
+	})
+}
+
+// pull fetches an image from the registry for the platform of the daemon and loads it into the daemon, tagged with its name. The
+// archive streams into the daemon while the layers download
+func (r *registryClient) pull(ctx context.Context, imageName string) error {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return err
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok {
+		return errors.New("IMAGES OF REGISTRIES WITH A CA BUNDLE OR WITHOUT TLS CAN ONLY BE PULLED BY TAG")
+	}
+	ref, err := name.ParseReference(imageName, r.nameOptions()...)
+	if err != nil {
+		return err
+	}
+	tag, err := name.NewTag(reference.FamiliarName(named)+":"+tagged.Tag(), r.nameOptions()...)
+	if err != nil {
+		return err
+	}
+	img, err := r.image(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	archive, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(tarball.Write(tag, img, writer))
+	}()
+	defer archive.Close()
+	load, err := DockerClient.ImageLoad(ctx, archive, true)
+	if err != nil {
+		return err
+	}
+	defer load.Body.Close()
+	return drainProgress(load.Body)
+}
+
+// image resolves ref to the image of the platform of the daemon
+func (r *registryClient) image(ctx context.Context, ref name.Reference) (v1.Image, error) {
+	version, err := DockerClient.ServerVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	img, err := remote.Image(ref, append(r.remoteOptions(ctx), remote.WithPlatform(v1.Platform{OS: version.Os, Architecture: version.Arch}))...)
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusTooManyRequests {
+		return nil, &rateLimitError{message: err.Error()}
+	}
+	return img, err
+}
+
+// push saves an image from the daemon and uploads it to the registry. The archive is kept in a temporary file as its layers are read
+// more than once
+func (r *registryClient) push(ctx context.Context, imageName string) error {
+	ref, err := name.ParseReference(imageName, r.nameOptions()...)
+	if err != nil {
+		return err
+	}
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return errors.New("IMAGES OF REGISTRIES WITH A CA BUNDLE OR WITHOUT TLS CAN ONLY BE PUSHED BY TAG")
+	}
+
+	save, err := DockerClient.ImageSave(ctx, []string{imageName})
+	if err != nil {
+		return err
+	}
+	defer save.Close()
+	file, err := os.CreateTemp("", "containers-push-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := io.Copy(file, save); err != nil {
+		return err
+	}
+
+	img, err := tarball.ImageFromPath(file.Name(), &tag)
+	if err != nil {
+		return err
+	}
+	return remote.Write(tag, img, r.remoteOptions(ctx)...)
+}
+
+// digest returns the digest the registry serves for ref
+func (r *registryClient) digest(ctx context.Context, imageName string) (digest.Digest, error) {
+	ref, err := name.ParseReference(imageName, r.nameOptions()...)
+	if err != nil {
+		return "", err
+	}
+	descriptor, err := remote.Head(ref, r.remoteOptions(ctx)...)
+	if err != nil {
+		return "", err
+	}
+	return digest.Parse(descriptor.Digest.String())
+}
+
+// imageID returns the ID the daemon gives the image the registry serves for imageName on its platform
+func (r *registryClient) imageID(ctx context.Context, imageName string) (string, error) {
+	ref, err := name.ParseReference(imageName, r.nameOptions()...)
+	if err != nil {
+		return "", err
+	}
+	img, err := r.image(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	config, err := img.ConfigName()
+	if err != nil {
+		return "", err
+	}
+	return config.String(), nil
+}
+
+// nameOptions are the options references to the registry are parsed with
+func (r *registryClient) nameOptions() []name.Option {
+	if r.cfg.Insecure {
+		return []name.Option{name.Insecure}
+	}
+	return nil
+}
+
+// remoteOptions are the options of requests to the registry, with its transport and credentials
+func (r *registryClient) remoteOptions(ctx context.Context) []remote.Option {
+	auth := authn.Anonymous
+	if r.cfg.Username != "" || r.cfg.Password != "" || r.cfg.IdentityToken != "" {
+		auth = authn.FromConfig(authn.AuthConfig{Username: r.cfg.Username, Password: r.cfg.Password, IdentityToken: r.cfg.IdentityToken})
+	}
+	return []remote.Option{remote.WithContext(ctx), remote.WithTransport(r.transport), remote.WithAuth(auth)}
+}
+
+// registryHost normalizes the aliases of Docker Hub to the domain image references resolve to
+func registryHost(host string) string {
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
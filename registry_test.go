@@ -0,0 +1,104 @@
+package containers
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestConfigureRegistryTransport(t *testing.T) {
+	quiet := registry.Logger(log.New(io.Discard, "", 0))
+	tlsServer := httptest.NewTLSServer(registry.New(quiet))
+	defer tlsServer.Close()
+	plainServer := httptest.NewServer(registry.New(quiet))
+	defer plainServer.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		host string
+		cfg  RegistryConfig
+	}{
+		{"ca bundle", strings.TrimPrefix(tlsServer.URL, "https://"), RegistryConfig{CAFile: caFile}},
+		{"insecure", strings.TrimPrefix(plainServer.URL, "http://"), RegistryConfig{Insecure: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ConfigureRegistry(tt.host, tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			defer RemoveRegistryConfig(tt.host)
+			r := registryFor(tt.host + "/app:1.0")
+			if r == nil || r.transport == nil {
+				t.Fatal("registry is not spoken to by the package")
+			}
+
+			img, err := random.Image(64, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.ParseReference(tt.host+"/app:1.0", r.nameOptions()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Write(ref, img, r.remoteOptions(context.Background())...); err != nil {
+				t.Fatal(err)
+			}
+			want, err := img.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.digest(context.Background(), tt.host+"/app:1.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("digest = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestConfigureRegistryCredentialsOnly(t *testing.T) {
+	if err := ConfigureRegistry("registry.internal:5000", RegistryConfig{Username: "user", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveRegistryConfig("registry.internal:5000")
+	r := registryFor("registry.internal:5000/app:1.0")
+	if r == nil {
+		t.Fatal("registry is not configured")
+	}
+	if r.transport != nil {
+		t.Error("a registry with credentials only is spoken to by the package instead of the daemon")
+	}
+	auth, err := registryAuth("registry.internal:5000/app:1.0")
+	if err != nil || auth == "" {
+		t.Errorf("auth = %q, %v", auth, err)
+	}
+}
+
+func TestConfigureRegistryBadCA(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureRegistry("registry.internal:5000", RegistryConfig{CAFile: caFile}); err == nil {
+		t.Error("expected an error for a bundle without certificates")
+	}
+}
@@ -0,0 +1,95 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+)
+
+// retryPolicy ~ The policy idempotent operations retry with. Retries are off until SetRetryPolicy is called
+var (
+	retryPolicyMu sync.RWMutex
+	retryPolicy   = RetryPolicy{MaxAttempts: 1}
+)
+
+// DefaultRetryPolicy ~ Returns a policy of 4 attempts, backing off from 200ms up to 5s, on transient errors
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// SetRetryPolicy ~ Sets the policy idempotent operations (starts, stops, restarts, pulls, inspects, lists and system queries) retry with
+func SetRetryPolicy(policy RetryPolicy) {
+	retryPolicyMu.Lock()
+	defer retryPolicyMu.Unlock()
+	retryPolicy = policy
+}
+
+// IsTransientError ~ Reports whether an error of the docker client is worth retrying: daemon side 5xx errors, an unavailable daemon
+// and dropped or refused connections
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errdefs.IsSystem(err) || errdefs.IsUnavailable(err) || client.IsErrConnectionFailed(err) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retry calls fn until it succeeds, fails with an error the policy does not retry, runs out of attempts or the context is done
+func retry(ctx context.Context, fn func() error) error {
+	_, err := retryResult(ctx, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// retryResult is retry for calls that return a value
+func retryResult[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	retryPolicyMu.RLock()
+	policy := retryPolicy
+	retryPolicyMu.RUnlock()
+
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = IsTransientError
+	}
+	multiplier := policy.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= policy.MaxAttempts || !retryOn(err) {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff = time.Duration(float64(backoff) * multiplier)
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
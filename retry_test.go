@@ -0,0 +1,82 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestRetry(t *testing.T) {
+	transient := errdefs.Unavailable(errors.New("daemon busy"))
+	permanent := errdefs.NotFound(errors.New("no such container"))
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		errs     []error
+		wantErr  error
+		attempts int
+	}{
+		{"success", DefaultRetryPolicy(), []error{nil}, nil, 1},
+		{"retries off by default", RetryPolicy{MaxAttempts: 1}, []error{transient, nil}, transient, 1},
+		{"transient then success", RetryPolicy{MaxAttempts: 3}, []error{transient, io.ErrUnexpectedEOF, nil}, nil, 3},
+		{"out of attempts", RetryPolicy{MaxAttempts: 2}, []error{transient, transient, nil}, transient, 2},
+		{"permanent error", RetryPolicy{MaxAttempts: 3}, []error{permanent, nil}, permanent, 1},
+		{"custom retry on", RetryPolicy{MaxAttempts: 3, RetryOn: errdefs.IsNotFound}, []error{permanent, nil}, nil, 2},
+		{"cancelled not retried", RetryPolicy{MaxAttempts: 3}, []error{context.Canceled, nil}, context.Canceled, 1},
+	}
+	defer SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			policy.InitialBackoff = time.Millisecond
+			SetRetryPolicy(policy)
+			attempts := 0
+			got, err := retryResult(context.Background(), func() (int, error) {
+				err := tt.errs[attempts]
+				attempts++
+				return attempts, err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.attempts || got != tt.attempts {
+				t.Errorf("attempts = %d, result %d, want %d", attempts, got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	defer SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 4, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, Multiplier: 2})
+	transient := errdefs.System(errors.New("internal error"))
+	start := time.Now()
+	err := retry(context.Background(), func() error { return transient })
+	elapsed := time.Since(start)
+	if !errors.Is(err, transient) {
+		t.Errorf("err = %v, want %v", err, transient)
+	}
+	// 10ms, then 20ms twice once capped
+	if elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("backed off %v, want about 50ms", elapsed)
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	defer SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	attempts := 0
+	err := retry(ctx, func() error {
+		attempts++
+		return io.ErrUnexpectedEOF
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || attempts != 1 {
+		t.Errorf("err = %v after %d attempts, want %v after 1", err, attempts, io.ErrUnexpectedEOF)
+	}
+}
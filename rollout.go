@@ -0,0 +1,208 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+// RollingUpdate ~ Moves the replica containers named in replicas to image, replacing BatchSize of them at a time through Deploy and
+// only moving on once a batch is healthy. The first failure aborts the update. Returns the IDs of the replicas now running, in order
+func RollingUpdate(ctx context.Context, replicas []string, image string, opts RollingUpdateOptions) ([]string, error) {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1
+	}
+
+	previous := make([]*ContainerCreateConfig, len(replicas))
+	for i, name := range replicas {
+		containerJSON, err := DockerClient.ContainerInspect(ctx, name)
+		if err != nil {
+			return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT REPLICA " + name + " => " + err.Error())
+		}
+		previous[i] = configFromInspect(containerJSON, imageConfigOf(ctx, containerJSON.Image))
+	}
+	if err := EnsureImage(ctx, image); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(replicas))
+	for start := 0; start < len(replicas); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(replicas) {
+			end = len(replicas)
+		}
+
+		var wg sync.WaitGroup
+		failures := make([]string, end-start)
+		for i := start; i < end; i++ {
+			desired := *previous[i]
+			updatedConfig := *desired.Config
+			updatedConfig.Image = image
+			desired.Config = &updatedConfig
+
+			wg.Add(1)
+			go func(i int, desired ContainerCreateConfig) {
+				defer wg.Done()
+				id, err := Deploy(ctx, &desired, opts.Deploy)
+				if err != nil {
+					failures[i-start] = replicas[i] + " => " + err.Error()
+					return
+				}
+				ids[i] = id
+			}(i, desired)
+		}
+		wg.Wait()
+
+		var failed []string
+		for _, failure := range failures {
+			if failure != "" {
+				failed = append(failed, failure)
+			}
+		}
+		if len(failed) == 0 {
+			continue
+		}
+
+		message := "[ERR:] [ROLLOUT] => ROLLING UPDATE TO " + image + " ABORTED => " + strings.Join(failed, " | ")
+		if opts.Rollback {
+			// Deploy already put back the replicas that failed, only the ones that made it have to be reverted
+			for i := 0; i < end; i++ {
+				if ids[i] == "" {
+					continue
+				}
+				id, err := Deploy(ctx, previous[i], opts.Deploy)
+				if err != nil {
+					message += " | FAILED TO ROLL BACK " + replicas[i] + " => " + err.Error()
+					continue
+				}
+				ids[i] = id
+			}
+		}
+		return ids, errors.New(message)
+	}
+	return ids, nil
+}
+
+// ConfigFromContainer ~ Rebuilds the config an existing container was created with, e.g. to export or recreate it. Env, labels,
+// command, entrypoint, working directory, user and healthcheck only hold what was set on top of the image, as long as the image
+// still exists. Without it they hold the merged values, the daemon does not keep them apart
+func ConfigFromContainer(ctx context.Context, containerID string) (*ContainerCreateConfig, error) {
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return configFromInspect(containerJSON, imageConfigOf(ctx, containerJSON.Image)), nil
+}
+
+// imageConfigOf returns the config baked into an image, nil when the image is gone
+func imageConfigOf(ctx context.Context, imageID string) *container.Config {
+	imageJSON, _, err := DockerClient.ImageInspectWithRaw(ctx, imageID)
+	if err != nil || imageJSON.Config == nil {
+		return nil
+	}
+	return &container.Config{
+		Cmd:         imageJSON.Config.Cmd,
+		Entrypoint:  imageJSON.Config.Entrypoint,
+		Env:         imageJSON.Config.Env,
+		Labels:      imageJSON.Config.Labels,
+		WorkingDir:  imageJSON.Config.WorkingDir,
+		User:        imageJSON.Config.User,
+		Healthcheck: imageJSON.Config.Healthcheck,
+	}
+}
+
+// configFromInspect rebuilds the config a container was created with from its inspect data, so it can be created again. The values
+// inherited from imageConfig, the config of the image the container was created from, are left out so that a new image brings its own
+func configFromInspect(containerJSON types.ContainerJSON, imageConfig *container.Config) *ContainerCreateConfig {
+	name := strings.TrimPrefix(containerJSON.Name, "/")
+	config := &ContainerCreateConfig{Name: name}
+
+	if containerJSON.Config != nil {
+		containerConfig := *containerJSON.Config
+		// The daemon defaults the hostname to the short container ID, which must not carry over to a new container
+		if len(containerJSON.ID) >= 12 && containerConfig.Hostname == containerJSON.ID[:12] {
+			containerConfig.Hostname = ""
+		}
+		if imageConfig != nil {
+			withoutImageConfig(&containerConfig, imageConfig)
+		}
+		config.Config = &containerConfig
+	}
+	if containerJSON.HostConfig != nil {
+		hostConfig := *containerJSON.HostConfig
+		config.HostConfig = &hostConfig
+	}
+
+	if containerJSON.NetworkSettings != nil && len(containerJSON.NetworkSettings.Networks) > 0 {
+		endpoints := map[string]*network.EndpointSettings{}
+		for networkName, endpoint := range containerJSON.NetworkSettings.Networks {
+			if endpoint == nil {
+				continue
+			}
+			var aliases []string
+			for _, alias := range endpoint.Aliases {
+				// The daemon adds the short container ID as an alias on user defined networks
+				if !strings.HasPrefix(containerJSON.ID, alias) {
+					aliases = append(aliases, alias)
+				}
+			}
+			endpoints[networkName] = &network.EndpointSettings{
+				Aliases:    aliases,
+				Links:      endpoint.Links,
+				IPAMConfig: endpoint.IPAMConfig,
+			}
+		}
+		config.NetworkingConfig = &network.NetworkingConfig{EndpointsConfig: endpoints}
+	}
+	return config
+}
+
+// withoutImageConfig removes from a container config what it inherited from the config of its image
+func withoutImageConfig(containerConfig *container.Config, imageConfig *container.Config) {
+	imageEnv := make(map[string]bool, len(imageConfig.Env))
+	for _, variable := range imageConfig.Env {
+		imageEnv[variable] = true
+	}
+	var env []string
+	for _, variable := range containerConfig.Env {
+		if !imageEnv[variable] {
+			env = append(env, variable)
+		}
+	}
+	containerConfig.Env = env
+
+	var labels map[string]string
+	for key, value := range containerConfig.Labels {
+		if imageValue, ok := imageConfig.Labels[key]; ok && imageValue == value {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+	containerConfig.Labels = labels
+
+	// A custom entrypoint drops the command of the image, so the command is only inherited along with the entrypoint
+	if reflect.DeepEqual(containerConfig.Entrypoint, imageConfig.Entrypoint) {
+		containerConfig.Entrypoint = nil
+		if reflect.DeepEqual(containerConfig.Cmd, imageConfig.Cmd) {
+			containerConfig.Cmd = nil
+		}
+	}
+	if containerConfig.WorkingDir == imageConfig.WorkingDir {
+		containerConfig.WorkingDir = ""
+	}
+	if containerConfig.User == imageConfig.User {
+		containerConfig.User = ""
+	}
+	if reflect.DeepEqual(containerConfig.Healthcheck, imageConfig.Healthcheck) {
+		containerConfig.Healthcheck = nil
+	}
+}
@@ -0,0 +1,132 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Run ~ Creates and starts a container, waits for it to exit and collects its output, like `docker run` for one-shot jobs.
+// The container is left in place, see RunAndRemove for ephemeral runs
+func Run(ctx context.Context, spec RunSpec) (RunResult, error) {
+	return run(ctx, spec, nil)
+}
+
+// run does the work of Run. prepare, if set, is handed the created container before it is started
+func run(ctx context.Context, spec RunSpec, prepare func(ctx context.Context, containerID string) error) (RunResult, error) {
+	result := RunResult{ExitCode: -1}
+	config := spec.createConfig()
+
+	started := time.Now()
+	cont, err := CreateContainerContext(ctx, config)
+	if err != nil {
+		return result, err
+	}
+	result.ContainerID = cont.ID
+
+	if prepare != nil {
+		if err := prepare(ctx, cont.ID); err != nil {
+			return result, err
+		}
+	}
+	if err := StartContainerContext(ctx, cont); err != nil {
+		return result, err
+	}
+
+	exitCode, err := WaitForExit(ctx, cont.ID)
+	if err != nil {
+		return result, err
+	}
+	result.ExitCode = exitCode
+	result.Duration = time.Since(started)
+
+	tty := config.Config != nil && config.Config.Tty
+	result.Stdout, result.Stderr, err = collectLogs(ctx, cont.ID, tty)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// createConfig merges the shortcuts of a RunSpec into its container config
+func (spec RunSpec) createConfig() *ContainerCreateConfig {
+	config := &ContainerCreateConfig{}
+	if spec.Create != nil {
+		*config = *spec.Create
+	}
+	if config.Config == nil {
+		config.Config = &container.Config{}
+	} else {
+		containerConfig := *config.Config
+		config.Config = &containerConfig
+	}
+	if spec.Image != "" {
+		config.Config.Image = spec.Image
+	}
+	if len(spec.Cmd) > 0 {
+		config.Config.Cmd = spec.Cmd
+	}
+	if len(spec.Env) > 0 {
+		config.Config.Env = append(config.Config.Env, spec.Env...)
+	}
+	if spec.PullPolicy != "" {
+		config.PullPolicy = spec.PullPolicy
+	}
+	return config
+}
+
+// collectLogs reads the complete stdout and stderr of a container
+func collectLogs(ctx context.Context, containerID string, tty bool) (string, string, error) {
+	logs, err := DockerClient.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return "", "", errors.New("[ERR:] [DOCKER] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer logs.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	// TTY containers do not multiplex their output, everything arrives on stdout
+	if tty {
+		_, err = io.Copy(&outBuf, logs)
+	} else {
+		_, err = stdcopy.StdCopy(&outBuf, &errBuf, logs)
+	}
+	if err != nil {
+		return "", "", errors.New("[ERR:] [DOCKER] => FAILED TO READ LOGS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	return outBuf.String(), errBuf.String(), nil
+}
+
+// RunAndRemove ~ Runs a one-shot container like Run and always purges it afterwards, even when the context is cancelled mid-run
+func RunAndRemove(ctx context.Context, spec RunSpec) (RunResult, error) {
+	return runAndRemove(ctx, spec, nil)
+}
+
+// runAndRemove does the work of RunAndRemove, handing prepare to run
+func runAndRemove(ctx context.Context, spec RunSpec, prepare func(ctx context.Context, containerID string) error) (RunResult, error) {
+	// The daemon's AutoRemove would delete the container before its logs are collected, the purge below replaces it
+	if spec.Create != nil && spec.Create.HostConfig != nil && spec.Create.HostConfig.AutoRemove {
+		create := *spec.Create
+		hostConfig := *create.HostConfig
+		hostConfig.AutoRemove = false
+		create.HostConfig = &hostConfig
+		spec.Create = &create
+	}
+
+	result, err := run(ctx, spec, prepare)
+	if result.ContainerID != "" {
+		// The purge ignores the cancellation of ctx, so cleanup still happens
+		purgeErr := PurgeContainerContext(context.WithoutCancel(ctx), result.ContainerID)
+		if purgeErr != nil && err == nil {
+			err = purgeErr
+		}
+	}
+	return result, err
+}
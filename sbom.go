@@ -0,0 +1,41 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SBOMFormatSPDX ~ SPDX as JSON
+	SBOMFormatSPDX = "spdx-json"
+	// SBOMFormatCycloneDX ~ CycloneDX as JSON
+	SBOMFormatCycloneDX = "cyclonedx-json"
+	// SBOMFormatCycloneDXXML ~ CycloneDX as XML
+	SBOMFormatCycloneDXXML = "cyclonedx-xml"
+)
+
+// DefaultSBOMGenerator ~ The syft image GenerateSBOM runs
+var DefaultSBOMGenerator = "anchore/syft:v1.8.0"
+
+// SBOMSocket ~ The daemon socket mounted into the SBOM generator, which reads the image through it
+var SBOMSocket = "/var/run/docker.sock"
+
+// GenerateSBOM ~ Returns a software bill of materials of an image in format (SBOMFormatSPDX, SBOMFormatCycloneDX or another output
+// format of syft), generated by syft in a helper container. An empty format defaults to SPDX
+func GenerateSBOM(ctx context.Context, ref string, format string) ([]byte, error) {
+	if format == "" {
+		format = SBOMFormatSPDX
+	}
+	// The docker: scheme keeps syft from falling back to pulling the image from a registry
+	result, err := runImageTool(ctx, DefaultSBOMGenerator, []string{"docker:" + ref, "--output", format, "--quiet"}, nil, nil,
+		BindMount(SBOMSocket, "/var/run/docker.sock", false, ""))
+	if err != nil {
+		return nil, errors.New("[ERR:] [SBOM] => FAILED TO GENERATE SBOM OF IMAGE " + ref + " => " + err.Error())
+	}
+	if result.ExitCode != 0 {
+		return nil, errors.New("[ERR:] [SBOM] => FAILED TO GENERATE SBOM OF IMAGE " + ref + " => GENERATOR EXITED WITH CODE " + strconv.FormatInt(result.ExitCode, 10) + " => " + strings.TrimSpace(result.Stderr))
+	}
+	return []byte(result.Stdout), nil
+}
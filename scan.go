@@ -0,0 +1,146 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+const (
+	// SeverityUnknown ~ A vulnerability the scanner could not rate
+	SeverityUnknown = "UNKNOWN"
+	// SeverityLow ~ A low severity vulnerability
+	SeverityLow = "LOW"
+	// SeverityMedium ~ A medium severity vulnerability
+	SeverityMedium = "MEDIUM"
+	// SeverityHigh ~ A high severity vulnerability
+	SeverityHigh = "HIGH"
+	// SeverityCritical ~ A critical vulnerability
+	SeverityCritical = "CRITICAL"
+)
+
+// DefaultScanner ~ The Trivy image ScanImage runs unless ScanOptions.Scanner is set
+var DefaultScanner = "aquasec/trivy:0.52.2"
+
+// severityRank orders the severities from the least to the most severe
+var severityRank = map[string]int{SeverityUnknown: 0, SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+
+// trivyReport ~ The parts of Trivy's JSON report that ScanImage reads
+type trivyReport struct {
+	Results []struct {
+		Target          string
+		Vulnerabilities []struct {
+			VulnerabilityID  string
+			PkgName          string
+			InstalledVersion string
+			FixedVersion     string
+			Severity         string
+			Title            string
+			PrimaryURL       string
+		}
+	}
+}
+
+// ScanImage ~ Scans an image for vulnerabilities with Trivy, run in a helper container that reaches the image through the daemon socket.
+// The report is returned even when vulnerabilities at or above opts.FailOn make the scan fail, so builds can print what failed them
+func ScanImage(ctx context.Context, ref string, opts ScanOptions) (ScanReport, error) {
+	report := ScanReport{Image: ref, Counts: map[string]int{}}
+	if opts.Scanner == "" {
+		opts.Scanner = DefaultScanner
+	}
+	if opts.Socket == "" {
+		opts.Socket = "/var/run/docker.sock"
+	}
+	if opts.CacheVolume == "" {
+		opts.CacheVolume = "containers-trivy-cache"
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Minute
+	}
+	failOn := strings.ToUpper(opts.FailOn)
+	if _, ok := severityRank[failOn]; failOn != "" && !ok {
+		return report, errors.New("[ERR:] [SCAN] => UNKNOWN SEVERITY " + opts.FailOn)
+	}
+
+	cmd := []string{"image", "--format", "json", "--quiet", "--scanners", "vuln", "--timeout", opts.Timeout.String()}
+	if opts.IgnoreUnfixed {
+		cmd = append(cmd, "--ignore-unfixed")
+	}
+	if opts.Server != "" {
+		cmd = append(cmd, "--server", opts.Server)
+	}
+	cmd = append(cmd, ref)
+
+	result, err := runImageTool(ctx, opts.Scanner, cmd, nil, nil,
+		BindMount(opts.Socket, "/var/run/docker.sock", false, ""),
+		VolumeMount(opts.CacheVolume, "/root/.cache", false),
+	)
+	if err != nil {
+		return report, errors.New("[ERR:] [SCAN] => FAILED TO SCAN IMAGE " + ref + " => " + err.Error())
+	}
+	if result.ExitCode != 0 {
+		return report, errors.New("[ERR:] [SCAN] => FAILED TO SCAN IMAGE " + ref + " => SCANNER EXITED WITH CODE " + strconv.FormatInt(result.ExitCode, 10) + " => " + strings.TrimSpace(result.Stderr))
+	}
+
+	var trivy trivyReport
+	if err := json.Unmarshal([]byte(result.Stdout), &trivy); err != nil {
+		return report, errors.New("[ERR:] [SCAN] => FAILED TO READ SCAN REPORT OF IMAGE " + ref + " => " + err.Error())
+	}
+	failing := 0
+	for _, target := range trivy.Results {
+		for _, v := range target.Vulnerabilities {
+			severity := strings.ToUpper(v.Severity)
+			if _, ok := severityRank[severity]; !ok {
+				severity = SeverityUnknown
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         severity,
+				Title:            v.Title,
+				URL:              v.PrimaryURL,
+				Target:           target.Target,
+			})
+			report.Counts[severity]++
+			if failOn != "" && severityRank[severity] >= severityRank[failOn] {
+				failing++
+			}
+		}
+	}
+
+	if failing > 0 {
+		return report, errors.New("[ERR:] [SCAN] => IMAGE " + ref + " HAS " + strconv.Itoa(failing) + " VULNERABILITIES OF SEVERITY " + failOn + " OR ABOVE")
+	}
+	return report, nil
+}
+
+// runImageTool runs a tool working on images, such as a scanner or a signer, in a throwaway container and returns its output.
+// files are copied into the container before it starts, so they need not exist on the daemon's host
+func runImageTool(ctx context.Context, tool string, cmd []string, env []string, files []Secret, mounts ...mount.Mount) (RunResult, error) {
+	if err := EnsureImage(ctx, tool); err != nil {
+		return RunResult{ExitCode: -1}, err
+	}
+	var prepare func(ctx context.Context, containerID string) error
+	if len(files) > 0 {
+		prepare = func(ctx context.Context, containerID string) error {
+			return CopyToContainer(ctx, containerID, "/", secretsArchive(files))
+		}
+	}
+	return runAndRemove(ctx, RunSpec{
+		Image: tool,
+		Cmd:   cmd,
+		Env:   env,
+		Create: &ContainerCreateConfig{
+			Config:     &container.Config{},
+			HostConfig: &container.HostConfig{Mounts: mounts},
+		},
+	}, prepare)
+}
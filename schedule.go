@@ -0,0 +1,342 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler ~ Runs one-shot container jobs on cron schedules and keeps the status of their last runs. A job still running when it
+// is due again is skipped rather than run twice
+type Scheduler struct {
+	opts SchedulerOptions
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	wake chan struct{}
+}
+
+// scheduledJob ~ A registered job and its bookkeeping
+type scheduledJob struct {
+	spec     RunSpec
+	schedule *cronSchedule
+	next     time.Time
+	status   JobStatus
+}
+
+// NewScheduler ~ Creates a scheduler, see Scheduler.Run. Schedules are evaluated in Location, the local time zone if unset
+func NewScheduler(opts SchedulerOptions) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	return &Scheduler{opts: opts, jobs: map[string]*scheduledJob{}, wake: make(chan struct{}, 1)}
+}
+
+// Add ~ Registers a job running spec on a cron schedule: five fields (minute, hour, day of month, month, day of week) supporting
+// *, lists, ranges, steps and month and day names, or one of @yearly, @monthly, @weekly, @daily, @hourly and @every <duration>.
+// Replaces the job of the same name, a run of it in progress finishes
+func (s *Scheduler) Add(name string, schedule string, spec RunSpec) error {
+	if name == "" {
+		return errors.New("[ERR:] [SCHEDULER] => JOBS NEED A NAME")
+	}
+	parsed, err := parseCron(schedule)
+	if err != nil {
+		return errors.New("[ERR:] [SCHEDULER] => INVALID SCHEDULE \"" + schedule + "\" FOR JOB " + name + " => " + err.Error())
+	}
+
+	next := parsed.next(time.Now().In(s.opts.Location))
+	if next.IsZero() {
+		return errors.New("[ERR:] [SCHEDULER] => SCHEDULE \"" + schedule + "\" OF JOB " + name + " NEVER FIRES")
+	}
+
+	s.mu.Lock()
+	job := &scheduledJob{spec: spec, schedule: parsed, next: next}
+	job.status = JobStatus{Name: name, Schedule: schedule, NextRun: job.next}
+	s.jobs[name] = job
+	s.mu.Unlock()
+	s.notify()
+	return nil
+}
+
+// Remove ~ Unregisters a job. A run in progress finishes
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	delete(s.jobs, name)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Status ~ Returns the status of a job
+func (s *Scheduler) Status(name string) (JobStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[name]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return job.status, true
+}
+
+// Statuses ~ Returns the status of every job, by name
+func (s *Scheduler) Statuses() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Run ~ Runs the jobs as they come due until the context is cancelled, then waits for the runs in progress. Each run is a
+// RunAndRemove of the job's spec, so containers do not pile up
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		now := time.Now().In(s.opts.Location)
+		s.mu.Lock()
+		var wait time.Duration = -1
+		for name, job := range s.jobs {
+			// A job without a next run, such as one for a date that has passed, never fires again
+			if job.next.IsZero() {
+				continue
+			}
+			if !job.next.After(now) {
+				job.next = job.schedule.next(now)
+				job.status.NextRun = job.next
+				if job.status.Running {
+					job.status.Skipped++
+				} else {
+					job.status.Running = true
+					wg.Add(1)
+					go func(name string, job *scheduledJob) {
+						defer wg.Done()
+						s.runJob(ctx, name, job)
+					}(name, job)
+				}
+			}
+			if job.next.IsZero() {
+				continue
+			}
+			if until := job.next.Sub(now); wait < 0 || until < wait {
+				wait = until
+			}
+		}
+		s.mu.Unlock()
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait >= 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// runJob runs a job once and records the outcome
+func (s *Scheduler) runJob(ctx context.Context, name string, job *scheduledJob) {
+	started := time.Now()
+	result, err := RunAndRemove(ctx, job.spec)
+
+	s.mu.Lock()
+	job.status.Running = false
+	job.status.Runs++
+	job.status.LastRun = started
+	job.status.LastResult = result
+	job.status.LastError = ""
+	if err != nil {
+		job.status.LastError = err.Error()
+	}
+	if err != nil || result.ExitCode != 0 {
+		job.status.Failures++
+	}
+	status := job.status
+	s.mu.Unlock()
+
+	if s.opts.OnResult != nil {
+		s.opts.OnResult(status)
+	}
+}
+
+// notify wakes Run to recompute the next due job
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// cronSchedule ~ A parsed cron expression, as bit sets of the allowed values of each field, or a fixed interval for @every
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record an unrestricted day field, cron matches either day field when both are restricted
+	domStar, dowStar bool
+	every            time.Duration
+}
+
+// cronField ~ The range and names of a cron field
+type cronField struct {
+	min, max int
+	names    []string
+}
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronDow    = cronField{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// cronDescriptors ~ The expressions the @ shorthands stand for
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a cron expression
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if every, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || interval <= 0 {
+			return nil, errors.New("INVALID INTERVAL " + every)
+		}
+		return &cronSchedule{every: interval}, nil
+	}
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.New("EXPECTED 5 FIELDS, GOT " + strconv.Itoa(len(fields)))
+	}
+	schedule := &cronSchedule{domStar: fields[2] == "*", dowStar: fields[4] == "*"}
+	var err error
+	for i, target := range []struct {
+		bits  *uint64
+		field cronField
+	}{{&schedule.minute, cronMinute}, {&schedule.hour, cronHour}, {&schedule.dom, cronDom}, {&schedule.month, cronMonth}, {&schedule.dow, cronDow}} {
+		if *target.bits, err = parseCronField(fields[i], target.field); err != nil {
+			return nil, err
+		}
+	}
+	// Sunday is both 0 and 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps into a bit set
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, errors.New("INVALID STEP IN " + part)
+			}
+		}
+
+		low, high := spec.min, spec.max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = cronValue(lowPart, spec); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = cronValue(highPart, spec); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = spec.max
+			}
+			if high < low {
+				return 0, errors.New("INVALID RANGE " + rangePart)
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or name of a field
+func cronValue(value string, spec cronField) (int, error) {
+	for i, name := range spec.names {
+		if strings.EqualFold(value, name) {
+			return spec.min + i, nil
+		}
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < spec.min || number > spec.max {
+		return 0, errors.New("VALUE " + value + " OUT OF RANGE " + strconv.Itoa(spec.min) + "-" + strconv.Itoa(spec.max))
+	}
+	return number, nil
+}
+
+// next returns the first time after t the schedule fires, in the location of t
+func (c *cronSchedule) next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every).Truncate(time.Second)
+	}
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Five years covers every valid expression, Feb 29 included
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for the two day fields: both must match when either is unrestricted, otherwise either may
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
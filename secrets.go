@@ -0,0 +1,264 @@
+package containers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+)
+
+const (
+	// SecretsCopy ~ Secrets are copied into the created container through the daemon before it starts. They end up in the writable
+	// layer of the container and go away with it, which works with remote, Desktop and rootless daemons alike
+	SecretsCopy = "copy"
+	// SecretsHostFiles ~ Secrets are written to files on the host and bind mounted read only, they are in place before the container
+	// starts. Only works when the daemon runs on the same host and filesystem as the caller
+	SecretsHostFiles = "files"
+	// SecretsTmpfs ~ Secrets are written into a tmpfs mounted in the container once it runs, nothing is written to the host
+	SecretsTmpfs = "tmpfs"
+)
+
+// SecretsLabel ~ The label holding the host directory the secrets of a container were written to in SecretsHostFiles mode
+const SecretsLabel = "com.github.g-makroglou.containers.secrets"
+
+// Secrets ~ Materializes secret values into containers without baking them into images or environment variables, and removes them
+// again when the containers are removed
+type Secrets struct {
+	opts    SecretsOptions
+	mu      sync.Mutex
+	pending map[string][]Secret
+}
+
+// NewSecrets ~ Creates a Secrets helper. The mode defaults to SecretsCopy and Dir to a directory under /dev/shm where available
+func NewSecrets(opts SecretsOptions) *Secrets {
+	if opts.Mode == "" {
+		opts.Mode = SecretsCopy
+	}
+	if opts.Dir == "" {
+		base := os.TempDir()
+		if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+			base = "/dev/shm"
+		}
+		opts.Dir = filepath.Join(base, "containers-secrets")
+	}
+	return &Secrets{opts: opts, pending: map[string][]Secret{}}
+}
+
+// Apply ~ Adds secrets to the config of a container that is about to be created. In SecretsHostFiles mode the values are written and
+// mounted right away, see Release for configs that are never created. In SecretsCopy and SecretsTmpfs mode the values are kept in
+// memory until Inject
+func (s *Secrets) Apply(config *ContainerCreateConfig, secrets ...Secret) error {
+	ensureContainerConfigs(config)
+	resolved := make([]Secret, len(secrets))
+	targets := map[string]bool{}
+	for i, secret := range secrets {
+		if secret.Name == "" || filepath.Base(secret.Name) != secret.Name || secret.Name == "." || secret.Name == ".." {
+			return errors.New("[ERR:] [SECRETS] => INVALID SECRET NAME \"" + secret.Name + "\"")
+		}
+		if secret.Target == "" {
+			secret.Target = "/run/secrets/" + secret.Name
+		}
+		if !path.IsAbs(secret.Target) {
+			return errors.New("[ERR:] [SECRETS] => TARGET OF SECRET " + secret.Name + " MUST BE AN ABSOLUTE PATH")
+		}
+		secret.Target = path.Clean(secret.Target)
+		if targets[secret.Target] {
+			return errors.New("[ERR:] [SECRETS] => MORE THAN ONE SECRET TARGETS " + secret.Target)
+		}
+		targets[secret.Target] = true
+		if secret.Mode == 0 {
+			secret.Mode = 0400
+		}
+		resolved[i] = secret
+	}
+
+	switch s.opts.Mode {
+	case SecretsHostFiles:
+		if err := s.writeHostFiles(config, resolved); err != nil {
+			return err
+		}
+	case SecretsCopy:
+		if config.Name == "" {
+			return errors.New("[ERR:] [SECRETS] => CONTAINERS NEED A NAME TO RECEIVE COPIED SECRETS")
+		}
+		s.mu.Lock()
+		s.pending[config.Name] = resolved
+		s.mu.Unlock()
+	case SecretsTmpfs:
+		if config.Name == "" {
+			return errors.New("[ERR:] [SECRETS] => CONTAINERS NEED A NAME TO RECEIVE TMPFS SECRETS")
+		}
+		mounted := map[string]bool{}
+		for _, m := range config.HostConfig.Mounts {
+			mounted[path.Clean(m.Target)] = true
+		}
+		for _, secret := range resolved {
+			if dir := path.Dir(secret.Target); !mounted[dir] {
+				AddMounts(config, TmpfsMount(dir, 0, 0755))
+				mounted[dir] = true
+			}
+		}
+		s.mu.Lock()
+		s.pending[config.Name] = resolved
+		s.mu.Unlock()
+	default:
+		return errors.New("[ERR:] [SECRETS] => UNKNOWN SECRETS MODE " + s.opts.Mode)
+	}
+
+	for _, secret := range resolved {
+		if secret.Env != "" {
+			config.Config.Env = append(config.Config.Env, secret.Env+"="+string(secret.Value))
+		}
+	}
+	return nil
+}
+
+// writeHostFiles writes the secrets to a fresh directory under Dir, mounts them into the config and labels it with the directory
+func (s *Secrets) writeHostFiles(config *ContainerCreateConfig, secrets []Secret) error {
+	if err := os.MkdirAll(s.opts.Dir, 0700); err != nil {
+		return errors.New("[ERR:] [SECRETS] => FAILED TO CREATE SECRETS DIRECTORY " + s.opts.Dir + " => " + err.Error())
+	}
+	prefix := config.Name
+	if prefix == "" {
+		prefix = "container"
+	}
+	dir, err := os.MkdirTemp(s.opts.Dir, prefix+"-")
+	if err != nil {
+		return errors.New("[ERR:] [SECRETS] => FAILED TO CREATE SECRETS DIRECTORY => " + err.Error())
+	}
+
+	mounts := make([]mount.Mount, 0, len(secrets))
+	for _, secret := range secrets {
+		file := filepath.Join(dir, secret.Name)
+		err := os.WriteFile(file, secret.Value, 0600)
+		if err == nil {
+			// Root is chowned to as well, the file would otherwise belong to the caller
+			err = os.Chown(file, secret.UID, secret.GID)
+		}
+		if err == nil {
+			err = os.Chmod(file, secret.Mode)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return errors.New("[ERR:] [SECRETS] => FAILED TO WRITE SECRET " + secret.Name + " => " + err.Error())
+		}
+		mounts = append(mounts, BindMount(file, secret.Target, true, ""))
+	}
+
+	AddMounts(config, mounts...)
+	labels := make(map[string]string, len(config.Config.Labels)+1)
+	for key, value := range config.Config.Labels {
+		labels[key] = value
+	}
+	labels[SecretsLabel] = dir
+	config.Config.Labels = labels
+	return nil
+}
+
+// Inject ~ Delivers the secrets applied to a container. In SecretsCopy mode they are copied into the created container, call it
+// between creating and starting the container, which must not have a read only root filesystem. In SecretsTmpfs mode they are written
+// into the running container as root through sh, call it right after the container starts, its entrypoint has to wait for the files.
+// Does nothing in SecretsHostFiles mode
+func (s *Secrets) Inject(ctx context.Context, containerID string) error {
+	if s.opts.Mode != SecretsCopy && s.opts.Mode != SecretsTmpfs {
+		return nil
+	}
+	containerJSON, err := DockerClient.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return errors.New("[ERR:] [SECRETS] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	name := strings.TrimPrefix(containerJSON.Name, "/")
+	s.mu.Lock()
+	secrets := s.pending[name]
+	s.mu.Unlock()
+
+	if s.opts.Mode == SecretsCopy {
+		// The daemon copies beside the tmpfs mounts of a container, so copied secrets go to its root filesystem
+		if err := CopyToContainer(ctx, containerID, "/", secretsArchive(secrets)); err != nil {
+			return errors.New("[ERR:] [SECRETS] => FAILED TO COPY SECRETS INTO CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		return nil
+	}
+	for _, secret := range secrets {
+		cmd := []string{"sh", "-c", `umask 077 && cat > "$1" && chown "$2" "$1" && chmod "$3" "$1"`, "sh",
+			secret.Target, strconv.Itoa(secret.UID) + ":" + strconv.Itoa(secret.GID), strconv.FormatUint(uint64(secret.Mode.Perm()), 8)}
+		result, err := ExecWithResult(ctx, containerID, cmd, ExecOptions{User: "0", Stdin: bytes.NewReader(secret.Value)})
+		if err != nil {
+			return errors.New("[ERR:] [SECRETS] => FAILED TO INJECT SECRET " + secret.Name + " INTO CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		if result.ExitCode != 0 {
+			return errors.New("[ERR:] [SECRETS] => FAILED TO INJECT SECRET " + secret.Name + " INTO CONTAINER WITH ID: " + containerID + " => EXIT CODE " + strconv.Itoa(result.ExitCode) + " => " + strings.TrimSpace(result.Stderr))
+		}
+	}
+	return nil
+}
+
+// secretsArchive packages secrets as a tar archive to extract at /, owned by their UID and GID. The daemon keeps the owners of the
+// entries as long as the copy does not ask for the user of the container
+func secretsArchive(secrets []Secret) io.Reader {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, secret := range secrets {
+		// Writes to a bytes.Buffer do not fail
+		tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     strings.TrimPrefix(secret.Target, "/"),
+			Size:     int64(len(secret.Value)),
+			Mode:     int64(secret.Mode.Perm()),
+			Uid:      secret.UID,
+			Gid:      secret.GID,
+			ModTime:  time.Now(),
+		})
+		tw.Write(secret.Value)
+	}
+	tw.Close()
+	return &buf
+}
+
+// Release ~ Removes the secrets applied to a config, for containers that were never created or were removed while Run was not watching
+func (s *Secrets) Release(config *ContainerCreateConfig) error {
+	s.mu.Lock()
+	delete(s.pending, config.Name)
+	s.mu.Unlock()
+	if config.Config == nil {
+		return nil
+	}
+	return s.removeDir(config.Config.Labels[SecretsLabel])
+}
+
+// Run ~ Removes the secrets of containers as they are removed, until the context is cancelled
+func (s *Secrets) Run(ctx context.Context) error {
+	eventFilters := filters.NewArgs(filters.Arg("type", "container"), filters.Arg("event", "destroy"))
+	return SubscribeEvents(ctx, eventFilters, func(event Event) {
+		s.mu.Lock()
+		delete(s.pending, event.Name)
+		s.mu.Unlock()
+		s.removeDir(event.Attributes[SecretsLabel])
+	})
+}
+
+// removeDir removes a secrets directory, refusing anything outside of Dir since the path comes from a label
+func (s *Secrets) removeDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(s.opts.Dir, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") || strings.ContainsRune(rel, filepath.Separator) {
+		return errors.New("[ERR:] [SECRETS] => REFUSING TO REMOVE " + dir + " OUTSIDE OF " + s.opts.Dir)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.New("[ERR:] [SECRETS] => FAILED TO REMOVE SECRETS DIRECTORY " + dir + " => " + err.Error())
+	}
+	return nil
+}
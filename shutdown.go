@@ -0,0 +1,184 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ShutdownManager ~ Stops the containers it tracks when the program shuts down, dependents before their dependencies and otherwise
+// in reverse start order, each with its own grace period
+type ShutdownManager struct {
+	opts    ShutdownOptions
+	mu      sync.Mutex
+	order   []string
+	tracked map[string]trackedContainer
+	once    sync.Once
+	err     error
+	done    chan struct{}
+}
+
+type trackedContainer struct {
+	timeout   time.Duration
+	dependsOn []string
+}
+
+// startListeners ~ The managers that track every container StartContainer starts
+var (
+	startListenersMu sync.Mutex
+	startListeners   = map[*ShutdownManager]struct{}{}
+)
+
+// NewShutdownManager ~ Creates a shutdown manager and, depending on opts, starts listening for signals and container starts
+func NewShutdownManager(opts ShutdownOptions) *ShutdownManager {
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	m := &ShutdownManager{
+		opts:    opts,
+		tracked: map[string]trackedContainer{},
+		done:    make(chan struct{}),
+	}
+
+	if opts.TrackStarts {
+		startListenersMu.Lock()
+		startListeners[m] = struct{}{}
+		startListenersMu.Unlock()
+	}
+
+	if opts.HandleSignals {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			defer signal.Stop(signals)
+			select {
+			case <-signals:
+				err := m.Close(context.Background())
+				if m.opts.OnShutdown != nil {
+					m.opts.OnShutdown(err)
+				}
+			case <-m.done:
+			}
+		}()
+	}
+	return m
+}
+
+// Track ~ Registers a container to stop on shutdown. A zero timeout uses the default of the manager. dependsOn lists containers that
+// must outlive this one. Tracking a container again updates its timeout and dependencies
+func (m *ShutdownManager) Track(containerID string, timeout time.Duration, dependsOn ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if timeout == 0 {
+		timeout = m.opts.Timeout
+	}
+	if _, ok := m.tracked[containerID]; !ok {
+		m.order = append(m.order, containerID)
+	}
+	m.tracked[containerID] = trackedContainer{timeout: timeout, dependsOn: dependsOn}
+}
+
+// Untrack ~ Leaves a container running on shutdown
+func (m *ShutdownManager) Untrack(containerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tracked[containerID]; !ok {
+		return
+	}
+	delete(m.tracked, containerID)
+	for i, id := range m.order {
+		if id == containerID {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Close ~ Stops every tracked container. Only the first call does the work, later calls return its result. Containers that are gone
+// already are skipped, failures do not stop the remaining containers from being stopped
+func (m *ShutdownManager) Close(ctx context.Context) error {
+	m.once.Do(func() {
+		close(m.done)
+		startListenersMu.Lock()
+		delete(startListeners, m)
+		startListenersMu.Unlock()
+		m.err = m.stopAll(ctx)
+	})
+	return m.err
+}
+
+// stopAll stops the tracked containers, visiting the dependents of a container before the container itself
+func (m *ShutdownManager) stopAll(ctx context.Context) error {
+	m.mu.Lock()
+	order := append([]string{}, m.order...)
+	tracked := make(map[string]trackedContainer, len(m.tracked))
+	for id, t := range m.tracked {
+		tracked[id] = t
+	}
+	m.mu.Unlock()
+
+	dependents := map[string][]string{}
+	for _, id := range order {
+		for _, dependency := range tracked[id].dependsOn {
+			dependents[dependency] = append(dependents[dependency], id)
+		}
+	}
+
+	var failures []string
+	stopped := map[string]bool{}
+	var stop func(id string)
+	stop = func(id string) {
+		if stopped[id] {
+			return
+		}
+		stopped[id] = true
+		for i := len(dependents[id]) - 1; i >= 0; i-- {
+			stop(dependents[id][i])
+		}
+		if _, ok := tracked[id]; !ok {
+			return
+		}
+		timeoutSeconds := int(tracked[id].timeout.Seconds())
+		if dryRun(OpStopContainer, id, "", "timeout", strconv.Itoa(timeoutSeconds)) {
+			return
+		}
+		opCtx, done := beginOperation(ctx, OpStopContainer, id, "", "timeout", strconv.Itoa(timeoutSeconds))
+		err := DockerClient.ContainerStop(opCtx, id, container.StopOptions{Signal: "SIGTERM", Timeout: &timeoutSeconds})
+		done(err)
+		if err != nil && !client.IsErrNotFound(err) {
+			failures = append(failures, "FAILED TO STOP CONTAINER WITH ID: "+id+" => "+err.Error())
+		}
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		stop(order[i])
+	}
+
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [SHUTDOWN] => FAILED TO STOP TRACKED CONTAINERS => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// notifyStarted hands a container StartContainer started to every manager tracking starts
+func notifyStarted(containerID string) {
+	startListenersMu.Lock()
+	defer startListenersMu.Unlock()
+	for m := range startListeners {
+		m.mu.Lock()
+		// An explicit Track keeps its timeout and dependencies
+		if _, ok := m.tracked[containerID]; !ok {
+			m.order = append(m.order, containerID)
+			m.tracked[containerID] = trackedContainer{timeout: m.opts.Timeout}
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,311 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/client"
+)
+
+// DefaultCosign ~ The cosign image SignImage and VerifyImage run. Pin it by digest, as in image@sha256:..., for signature enforcement
+// to trust it without asking its registry for the digest of the tag
+var DefaultCosign = "gcr.io/projectsigstore/cosign:v2.2.4"
+
+// CosignDockerConfig ~ The directory holding the config.json cosign authenticates to registries with, ~/.docker when empty
+var CosignDockerConfig = ""
+
+// signaturePolicy ~ The policy CreateContainer enforces, none until EnableSignatureEnforcement is called. verifiedImages holds the IDs
+// of the images verified under it and exemptDigests the digests its exempt images resolved to
+var (
+	signatureMu     sync.Mutex
+	signaturePolicy *SignaturePolicy
+	verifiedImages  = map[string]bool{}
+	exemptDigests   = map[string]string{}
+)
+
+// SignImage ~ Signs a pushed image with the cosign private key at keyPath and uploads the signature to its registry.
+// COSIGN_PASSWORD is passed on from the environment for encrypted keys
+func SignImage(ctx context.Context, ref string, keyPath string) error {
+	files, err := cosignFiles(keyPath, "/cosign/cosign.key")
+	if err != nil {
+		return errors.New("[ERR:] [SIGN] => FAILED TO SIGN IMAGE " + ref + " => " + err.Error())
+	}
+	return runCosign(ctx, "SIGN", ref, []string{"sign", "--yes", "--key", "/cosign/cosign.key", ref}, files)
+}
+
+// VerifyImage ~ Verifies the signature of an image in its registry against policy. Returns an error when no signature satisfies it
+func VerifyImage(ctx context.Context, ref string, policy SignaturePolicy) error {
+	cmd := []string{"verify", "--output", "json"}
+	keyPath := ""
+	switch {
+	case policy.PublicKey != "":
+		keyPath = policy.PublicKey
+		cmd = append(cmd, "--key", "/cosign/cosign.pub")
+	case policy.Identity != "" && policy.Issuer != "":
+		cmd = append(cmd, "--certificate-identity", policy.Identity, "--certificate-oidc-issuer", policy.Issuer)
+	default:
+		return errors.New("[ERR:] [VERIFY] => SIGNATURE POLICY NEEDS A PUBLIC KEY OR AN IDENTITY AND AN ISSUER")
+	}
+	if policy.IgnoreTlog {
+		cmd = append(cmd, "--insecure-ignore-tlog")
+	}
+	cmd = append(cmd, ref)
+
+	files, err := cosignFiles(keyPath, "/cosign/cosign.pub")
+	if err != nil {
+		return errors.New("[ERR:] [VERIFY] => FAILED TO VERIFY IMAGE " + ref + " => " + err.Error())
+	}
+	return runCosign(ctx, "VERIFY", ref, cmd, files)
+}
+
+// EnableSignatureEnforcement ~ Makes CreateContainer refuse images whose signature does not satisfy policy. Images are verified by
+// the digest they were pulled with. Images without one, such as loaded ones, are verified by the digest their registry serves for
+// the tag once pulling that digest yields the same image, so images that were only built locally are refused. The images of
+// DefaultCosign, DefaultScanner and DefaultSBOMGenerator are let through, a custom scanner has to be listed in policy.Exempt. An
+// exempt image is only let through when one of its repo digests is the digest its reference pins or, for a tag, the digest its
+// registry serves for the tag, so an image tagged locally with an exempt name is verified like any other
+func EnableSignatureEnforcement(policy SignaturePolicy) {
+	signatureMu.Lock()
+	defer signatureMu.Unlock()
+	signaturePolicy = &policy
+	verifiedImages = map[string]bool{}
+	exemptDigests = map[string]string{}
+}
+
+// DisableSignatureEnforcement ~ Lets CreateContainer use unsigned images again
+func DisableSignatureEnforcement() {
+	signatureMu.Lock()
+	defer signatureMu.Unlock()
+	signaturePolicy = nil
+}
+
+// enforceSignature verifies the image of a container about to be created when enforcement is enabled. Verified images are
+// remembered by ID, so a tag that moves to other content is verified again
+func enforceSignature(ctx context.Context, imageName string) error {
+	signatureMu.Lock()
+	policy := signaturePolicy
+	signatureMu.Unlock()
+	if policy == nil || imageName == "" {
+		return nil
+	}
+
+	inspect, _, err := DockerClient.ImageInspectWithRaw(ctx, imageName)
+	if client.IsErrNotFound(err) {
+		// Creating the container reports the missing image
+		return nil
+	}
+	if err != nil {
+		return errors.New("[ERR:] [VERIFY] => REFUSING IMAGE " + imageName + " => FAILED TO INSPECT IT => " + err.Error())
+	}
+	signatureMu.Lock()
+	verified := verifiedImages[inspect.ID]
+	signatureMu.Unlock()
+	if verified {
+		return nil
+	}
+	exempt, err := exemptImage(ctx, policy, inspect.RepoDigests)
+	if err != nil {
+		return errors.New("[ERR:] [VERIFY] => REFUSING IMAGE " + imageName + " => " + err.Error())
+	}
+	if exempt {
+		signatureMu.Lock()
+		if signaturePolicy == policy {
+			verifiedImages[inspect.ID] = true
+		}
+		signatureMu.Unlock()
+		return nil
+	}
+	if imageName == DefaultCosign {
+		// Verifying it would run the very image being verified
+		return errors.New("[ERR:] [VERIFY] => REFUSING IMAGE " + imageName + " => IT IS NOT THE IMAGE ITS REGISTRY SERVES FOR " + DefaultCosign + ", SO IT CANNOT BE TRUSTED TO VERIFY SIGNATURES")
+	}
+	ref := ""
+	if len(inspect.RepoDigests) > 0 {
+		ref = inspect.RepoDigests[0]
+	} else if ref, err = registryDigest(ctx, imageName, inspect.ID); err != nil {
+		return errors.New("[ERR:] [VERIFY] => REFUSING IMAGE " + imageName + " => " + err.Error())
+	}
+	if err := VerifyImage(ctx, ref, *policy); err != nil {
+		return errors.New("[ERR:] [VERIFY] => REFUSING IMAGE " + imageName + " => " + err.Error())
+	}
+
+	signatureMu.Lock()
+	if signaturePolicy == policy {
+		verifiedImages[inspect.ID] = true
+	}
+	signatureMu.Unlock()
+	return nil
+}
+
+// exemptImage reports whether an image with repoDigests is one of the exempt images of policy. Exempt references pinned by digest
+// are compared as they are, tags are resolved through their registry once per policy, and only for repositories the image has
+// digests in
+func exemptImage(ctx context.Context, policy *SignaturePolicy, repoDigests []string) (bool, error) {
+	digests := map[string]bool{}
+	repositories := map[string]bool{}
+	for _, repoDigest := range repoDigests {
+		named, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if canonical, ok := named.(reference.Canonical); ok {
+			digests[canonical.String()] = true
+			repositories[canonical.Name()] = true
+		}
+	}
+	if len(digests) == 0 {
+		return false, nil
+	}
+
+	candidates := append([]string{DefaultCosign, DefaultScanner, DefaultSBOMGenerator}, policy.Exempt...)
+	for _, candidate := range candidates {
+		named, err := reference.ParseNormalizedNamed(candidate)
+		if err != nil || !repositories[named.Name()] {
+			continue
+		}
+		digest, err := exemptDigest(ctx, policy, candidate, named)
+		if err != nil {
+			return false, err
+		}
+		if digests[digest] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// exemptDigest returns the canonical reference an exempt reference stands for, resolving tags through their registry
+func exemptDigest(ctx context.Context, policy *SignaturePolicy, candidate string, named reference.Named) (string, error) {
+	if canonical, ok := named.(reference.Canonical); ok {
+		pinned, err := reference.WithDigest(reference.TrimNamed(named), canonical.Digest())
+		if err != nil {
+			return "", err
+		}
+		return pinned.String(), nil
+	}
+
+	signatureMu.Lock()
+	digest, ok := exemptDigests[candidate]
+	signatureMu.Unlock()
+	if ok {
+		return digest, nil
+	}
+	digest, err := resolveDigest(ctx, candidate, named)
+	if err != nil {
+		return "", errors.New("FAILED TO RESOLVE THE DIGEST OF EXEMPT IMAGE " + candidate + " => " + err.Error())
+	}
+	signatureMu.Lock()
+	if signaturePolicy == policy {
+		exemptDigests[candidate] = digest
+	}
+	signatureMu.Unlock()
+	return digest, nil
+}
+
+// resolveDigest returns the canonical reference of the content the registry of named serves for imageName
+func resolveDigest(ctx context.Context, imageName string, named reference.Named) (string, error) {
+	if r := registryFor(imageName); r != nil && r.transport != nil {
+		dgst, err := r.digest(ctx, imageName)
+		if err != nil {
+			return "", err
+		}
+		canonical, err := reference.WithDigest(reference.TrimNamed(named), dgst)
+		if err != nil {
+			return "", err
+		}
+		return canonical.String(), nil
+	}
+	auth, err := registryAuth(imageName)
+	if err != nil {
+		return "", err
+	}
+	distribution, err := DockerClient.DistributionInspect(ctx, imageName, auth)
+	if err != nil {
+		return "", err
+	}
+	canonical, err := reference.WithDigest(reference.TrimNamed(named), distribution.Descriptor.Digest)
+	if err != nil {
+		return "", err
+	}
+	return canonical.String(), nil
+}
+
+// registryDigest returns the canonical reference of the content the registry serves for an image without a repo digest. The digest
+// is pulled, or only fetched for registries the package speaks to itself, and only returned when it yields the local image, whose ID
+// is imageID
+func registryDigest(ctx context.Context, imageName string, imageID string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", errors.New("IT HAS NO REPO DIGEST AND IS NOT A REGISTRY REFERENCE => " + err.Error())
+	}
+	ref, err := resolveDigest(ctx, imageName, named)
+	if err != nil {
+		return "", errors.New("IT HAS NO REPO DIGEST AND ITS REGISTRY DID NOT RESOLVE ONE, SO ITS SIGNATURE CANNOT BE VERIFIED => " + err.Error())
+	}
+
+	pulledID := ""
+	if r := registryFor(imageName); r != nil && r.transport != nil {
+		if pulledID, err = r.imageID(ctx, ref); err != nil {
+			return "", errors.New("FAILED TO FETCH " + ref + " => " + err.Error())
+		}
+	} else {
+		if err := PullImage(ctx, ref); err != nil {
+			return "", err
+		}
+		pulled, _, err := DockerClient.ImageInspectWithRaw(ctx, ref)
+		if err != nil {
+			return "", errors.New("FAILED TO INSPECT " + ref + " => " + err.Error())
+		}
+		pulledID = pulled.ID
+	}
+	if pulledID != imageID {
+		return "", errors.New("IT DIFFERS FROM " + ref + " SERVED BY ITS REGISTRY, SO ITS SIGNATURE CANNOT BE VERIFIED")
+	}
+	return ref, nil
+}
+
+// cosignFiles reads the key at keyPath, if any, to be copied to target and the registry credentials, so that neither has to exist
+// on the host of the daemon. They are readable by all as cosign does not run as root
+func cosignFiles(keyPath string, target string) ([]Secret, error) {
+	var files []Secret
+	if keyPath != "" {
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, Secret{Target: target, Value: key, Mode: 0o444})
+	}
+
+	dockerConfig := CosignDockerConfig
+	if dockerConfig == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dockerConfig = filepath.Join(home, ".docker")
+		}
+	}
+	if config, err := os.ReadFile(filepath.Join(dockerConfig, "config.json")); err == nil {
+		files = append(files, Secret{Target: "/cosign/docker/config.json", Value: config, Mode: 0o444})
+	}
+	return files, nil
+}
+
+// runCosign runs a cosign command, passing on the key password and the registry credentials
+func runCosign(ctx context.Context, tag string, ref string, cmd []string, files []Secret) error {
+	env := []string{"DOCKER_CONFIG=/cosign/docker"}
+	if password, ok := os.LookupEnv("COSIGN_PASSWORD"); ok {
+		env = append(env, "COSIGN_PASSWORD="+password)
+	}
+	result, err := runImageTool(ctx, DefaultCosign, cmd, env, files)
+	if err != nil {
+		return errors.New("[ERR:] [" + tag + "] => FAILED TO " + tag + " IMAGE " + ref + " => " + err.Error())
+	}
+	if result.ExitCode != 0 {
+		return errors.New("[ERR:] [" + tag + "] => FAILED TO " + tag + " IMAGE " + ref + " => COSIGN EXITED WITH CODE " + strconv.FormatInt(result.ExitCode, 10) + " => " + strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
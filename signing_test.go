@@ -0,0 +1,34 @@
+package containers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExemptImage(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	const other = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	policy := &SignaturePolicy{Exempt: []string{"example.com/tools/scanner:1.0@" + digest}}
+
+	tests := []struct {
+		name        string
+		repoDigests []string
+		want        bool
+	}{
+		{"pinned digest", []string{"example.com/tools/scanner@" + digest}, true},
+		{"retagged image", []string{"example.com/tools/scanner@" + other}, false},
+		{"other repository", []string{"example.com/tools/other@" + digest}, false},
+		{"no repo digest", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exempt, err := exemptImage(context.Background(), policy, tt.repoDigests)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exempt != tt.want {
+				t.Errorf("exempt = %v, want %v", exempt, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package containers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/G-MAKROGLOU/containers/internal/yamlenv"
+	"gopkg.in/yaml.v3"
+)
+
+// stackMember ~ A container of a stack definition file
+type stackMember struct {
+	ContainerSpec
+	Profiles []string `json:"profiles"`
+}
+
+// stackFile ~ The layout of a stack definition file, see LoadStack
+type stackFile struct {
+	Name       string                    `json:"name"`
+	Containers map[string]stackMember    `json:"containers"`
+	Networks   map[string]NetworkOptions `json:"networks"`
+	Volumes    map[string]VolumeOptions  `json:"volumes"`
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// LoadSpec ~ Loads a container definition from a YAML or JSON file. The file holds the fields of ContainerCreateConfig, named like
+// the Docker API fields they map to in any case (name, config, hostConfig, networkingConfig, platform). Durations may be written
+// as strings such as "30s". Unknown fields are reported with their position and the closest known field.
+// ${VAR} and ${VAR:-default} in values are expanded from the process environment, $$ stands for a literal $
+func LoadSpec(path string) (*ContainerCreateConfig, error) {
+	return LoadSpecWithOptions(path, SpecOptions{})
+}
+
+// LoadSpecWithOptions ~ LoadSpec with the variables and templating of opts
+func LoadSpecWithOptions(path string, opts SpecOptions) (*ContainerCreateConfig, error) {
+	config := &ContainerCreateConfig{}
+	if _, err := loadSpecFile(path, opts, config); err != nil {
+		return nil, err
+	}
+	if config.Config == nil || config.Config.Image == "" {
+		return nil, errors.New("[ERR:] [SPEC] => " + path + " => config.image IS REQUIRED")
+	}
+	return config, nil
+}
+
+// LoadStack ~ Loads a stack definition from a YAML or JSON file with a name and maps of containers (container definitions as read
+// by LoadSpec plus dependsOn and profiles), networks (NetworkOptions) and volumes (VolumeOptions). Members are added in file order
+func LoadStack(path string) (*Stack, error) {
+	return LoadStackWithOptions(path, SpecOptions{})
+}
+
+// LoadStackWithOptions ~ LoadStack with the variables and templating of opts
+func LoadStackWithOptions(path string, opts SpecOptions) (*Stack, error) {
+	file := stackFile{}
+	root, err := loadSpecFile(path, opts, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if file.Name == "" {
+		problems = append(problems, "name IS REQUIRED")
+	}
+	if len(file.Containers) == 0 {
+		problems = append(problems, "AT LEAST ONE CONTAINER IS REQUIRED")
+	}
+	members := mappingKeys(root, "containers")
+	for _, member := range members {
+		spec := file.Containers[member]
+		if spec.Config == nil || spec.Config.Image == "" {
+			problems = append(problems, "containers."+member+".config.image IS REQUIRED")
+		}
+		for dependency, condition := range spec.DependsOn {
+			if _, ok := file.Containers[dependency]; !ok {
+				problems = append(problems, "containers."+member+" DEPENDS ON UNKNOWN CONTAINER "+dependency)
+			}
+			if condition != DependencyStarted && condition != DependencyHealthy && condition != DependencyCompleted {
+				problems = append(problems, "containers."+member+".dependsOn."+dependency+" HAS UNKNOWN CONDITION \""+condition+"\"")
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.New("[ERR:] [SPEC] => " + path + " => " + strings.Join(problems, " | "))
+	}
+
+	stack := NewStack(file.Name)
+	for _, member := range members {
+		spec := file.Containers[member]
+		stack.AddContainer(member, spec.ContainerCreateConfig)
+		for dependency, condition := range spec.DependsOn {
+			stack.DependsOn(member, dependency, condition)
+		}
+		if len(spec.Profiles) > 0 {
+			stack.SetProfiles(member, spec.Profiles...)
+		}
+	}
+	stack.ActivateProfiles(opts.Profiles...)
+	for name, opts := range file.Networks {
+		stack.AddNetwork(name, opts)
+	}
+	for name, opts := range file.Volumes {
+		stack.AddVolume(name, opts)
+	}
+	return stack, nil
+}
+
+// loadSpecFile reads a definition file into target after rendering its template, expanding its variables and checking every field
+// against the type of target, and returns the root node
+func loadSpecFile(path string, opts SpecOptions, target interface{}) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => FAILED TO READ " + path + " => " + err.Error())
+	}
+	env, err := specEnv(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Template {
+		if data, err = renderSpecTemplate(path, data, env, opts.Funcs); err != nil {
+			return nil, err
+		}
+	}
+	// YAML is a superset of JSON, both go through the same parser
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => FAILED TO PARSE " + path + " => " + err.Error())
+	}
+	if len(document.Content) == 0 {
+		return nil, errors.New("[ERR:] [SPEC] => " + path + " IS EMPTY")
+	}
+	root := document.Content[0]
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	yamlenv.Expand(root, func(s string) string { return ExpandEnv(s, lookup) })
+
+	var problems []string
+	value := specValue(root, reflect.TypeOf(target), "", &problems)
+	if len(problems) > 0 {
+		return nil, errors.New("[ERR:] [SPEC] => INVALID DEFINITION IN " + path + " => " + strings.Join(problems, " | "))
+	}
+	// The Docker types only know how to decode JSON, so the checked document is handed over as JSON
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => FAILED TO DECODE " + path + " => " + err.Error())
+	}
+	if err := json.Unmarshal(encoded, target); err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => INVALID DEFINITION IN " + path + " => " + err.Error())
+	}
+	return root, nil
+}
+
+// specValue converts node into a value encoding/json can decode into t, reporting unknown fields and misplaced values to problems
+func specValue(node *yaml.Node, t reflect.Type, where string, problems *[]string) interface{} {
+	if node.Kind == yaml.AliasNode {
+		return specValue(node.Alias, t, where, problems)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	position := strconv.Itoa(node.Line) + ":" + strconv.Itoa(node.Column)
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	switch {
+	case t == durationType && node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str":
+		duration, err := time.ParseDuration(node.Value)
+		if err != nil {
+			*problems = append(*problems, position+" "+describeSpecPath(where)+" IS NOT A DURATION: "+node.Value)
+			return nil
+		}
+		return int64(duration)
+	case t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) || t.Kind() == reflect.Interface:
+		// Types with their own decoding, such as command lines that take a string or a list, are checked by the decoder
+		return genericValue(node)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			*problems = append(*problems, position+" "+describeSpecPath(where)+" MUST BE A MAPPING")
+			return nil
+		}
+		fields := jsonFields(t)
+		value := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, content := node.Content[i], node.Content[i+1]
+			field, ok := matchField(fields, key.Value)
+			if !ok {
+				problem := strconv.Itoa(key.Line) + ":" + strconv.Itoa(key.Column) + " UNKNOWN FIELD \"" + key.Value + "\" IN " + describeSpecPath(where)
+				if suggestion := closestField(fields, key.Value); suggestion != "" {
+					problem += ", DID YOU MEAN \"" + suggestion + "\"?"
+				}
+				*problems = append(*problems, problem)
+				continue
+			}
+			value[field.name] = specValue(content, field.typ, specPath(where, key.Value), problems)
+		}
+		return value
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			*problems = append(*problems, position+" "+describeSpecPath(where)+" MUST BE A MAPPING")
+			return nil
+		}
+		value := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			value[key] = specValue(node.Content[i+1], t.Elem(), specPath(where, key), problems)
+		}
+		return value
+	case reflect.String:
+		// Unquoted numbers and booleans such as "hostPort: 8080" are meant as strings here
+		if node.Kind == yaml.ScalarNode {
+			return node.Value
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode || t.Elem().Kind() == reflect.Uint8 {
+			return genericValue(node)
+		}
+		value := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			value[i] = specValue(item, t.Elem(), where+"["+strconv.Itoa(i)+"]", problems)
+		}
+		return value
+	}
+	return genericValue(node)
+}
+
+// specEnv returns the variables a spec is expanded with: the process environment, then the env files and then Env of opts
+func specEnv(opts SpecOptions) (map[string]string, error) {
+	env := EnvSliceToMap(os.Environ())
+	for _, envFile := range opts.EnvFiles {
+		fileEnv, err := LoadEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+	return env, nil
+}
+
+// renderSpecTemplate executes a spec file as a Go template. The template sees the variables as .Env and can call env and default
+// besides the functions of funcs
+func renderSpecTemplate(path string, data []byte, env map[string]string, funcs template.FuncMap) ([]byte, error) {
+	tmpl := template.New(filepath.Base(path)).Option("missingkey=zero").Funcs(template.FuncMap{
+		"env": func(key string) string {
+			return env[key]
+		},
+		"default": func(fallback interface{}, value interface{}) interface{} {
+			if value == nil || reflect.ValueOf(value).IsZero() {
+				return fallback
+			}
+			return value
+		},
+	}).Funcs(funcs)
+	if _, err := tmpl.Parse(string(data)); err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => FAILED TO PARSE TEMPLATE " + path + " => " + err.Error())
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, map[string]interface{}{"Env": env}); err != nil {
+		return nil, errors.New("[ERR:] [SPEC] => FAILED TO RENDER TEMPLATE " + path + " => " + err.Error())
+	}
+	return rendered.Bytes(), nil
+}
+
+// genericValue converts node into plain maps, slices and scalars
+func genericValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return genericValue(node.Alias)
+	case yaml.MappingNode:
+		value := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value[node.Content[i].Value] = genericValue(node.Content[i+1])
+		}
+		return value
+	case yaml.SequenceNode:
+		value := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			value[i] = genericValue(item)
+		}
+		return value
+	}
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return value
+}
+
+// jsonField ~ A field of a struct as encoding/json sees it
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields lists the fields encoding/json decodes into t, promoting the fields of embedded structs
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: field.Type})
+	}
+	return fields
+}
+
+// matchField finds the field a key decodes into, exact matches first and then regardless of case like encoding/json
+func matchField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// closestField returns the field whose name is closest to key, or nothing when no field is close enough to be a typo
+func closestField(fields []jsonField, key string) string {
+	best, bestDistance := "", 3
+	for _, field := range fields {
+		if distance := editDistance(strings.ToLower(field.name), strings.ToLower(key)); distance < bestDistance {
+			best, bestDistance = field.name, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// mappingKeys returns the keys of the mapping under key in root in file order
+func mappingKeys(root *yaml.Node, key string) []string {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if !strings.EqualFold(root.Content[i].Value, key) {
+			continue
+		}
+		mapping := root.Content[i+1]
+		if mapping.Kind == yaml.AliasNode {
+			mapping = mapping.Alias
+		}
+		var keys []string
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			keys = append(keys, mapping.Content[j].Value)
+		}
+		return keys
+	}
+	return nil
+}
+
+func specPath(where string, key string) string {
+	if where == "" {
+		return key
+	}
+	return where + "." + key
+}
+
+func describeSpecPath(where string) string {
+	if where == "" {
+		return "THE DOCUMENT"
+	}
+	return where
+}
@@ -0,0 +1,80 @@
+package containers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSpecWithOptions(t *testing.T) {
+	path := writeSpec(t, `
+name: ${NAME}
+config:
+  image: nginx:${TAG:-latest}
+  cmd: ["sh", "-c", "echo $$HOME"]
+  env: ["MODE=${MODE}"]
+  healthcheck:
+    test: ["CMD", "true"]
+    interval: 30s
+hostConfig:
+  memory: ${MEMORY}
+`)
+	config, err := LoadSpecWithOptions(path, SpecOptions{Env: map[string]string{"NAME": "web", "MODE": "a: b", "MEMORY": "1048576"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "web" {
+		t.Errorf("name = %q", config.Name)
+	}
+	if config.Config.Image != "nginx:latest" {
+		t.Errorf("image = %q", config.Config.Image)
+	}
+	if want := []string{"sh", "-c", "echo $HOME"}; !reflect.DeepEqual([]string(config.Config.Cmd), want) {
+		t.Errorf("cmd = %q, want %q", config.Config.Cmd, want)
+	}
+	if want := []string{"MODE=a: b"}; !reflect.DeepEqual(config.Config.Env, want) {
+		t.Errorf("env = %q, want %q", config.Config.Env, want)
+	}
+	if config.Config.Healthcheck == nil || config.Config.Healthcheck.Interval != 30*time.Second {
+		t.Errorf("healthcheck = %+v", config.Config.Healthcheck)
+	}
+	if config.HostConfig.Memory != 1048576 {
+		t.Errorf("memory = %d", config.HostConfig.Memory)
+	}
+}
+
+func TestLoadSpecErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing image", "name: web\nconfig: {}\n", "config.image IS REQUIRED"},
+		{"unknown field", "config:\n  image: nginx\n  imagee: nginx\n", "image"},
+		{"empty", "", "IS EMPTY"},
+		{"not yaml", "config: [", "FAILED TO PARSE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSpec(writeSpec(t, tt.content))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// StackStateMissing ~ The state reported by Stack.Status for a member whose container does not exist
+const StackStateMissing = "missing"
+
+// stackDefaultNetwork ~ The network every member of a stack joins unless its config sets a network mode
+const stackDefaultNetwork = "default"
+
+// Stack ~ A multi-container application managed as a unit. Members are named within the stack and run as "<stack>-<member>",
+// networks and volumes as "<stack>_<name>". Members without a network mode join a shared network where they reach each other by member name
+type Stack struct {
+	Name     string
+	members  []string
+	configs  map[string]ContainerCreateConfig
+	depends  map[string]map[string]string
+	networks map[string]NetworkOptions
+	volumes  map[string]VolumeOptions
+	profiles map[string][]string
+	active   map[string]bool
+}
+
+// NewStack ~ Creates an empty stack
+func NewStack(name string) *Stack {
+	return &Stack{
+		Name:     name,
+		configs:  map[string]ContainerCreateConfig{},
+		depends:  map[string]map[string]string{},
+		networks: map[string]NetworkOptions{},
+		volumes:  map[string]VolumeOptions{},
+		profiles: map[string][]string{},
+		active:   map[string]bool{},
+	}
+}
+
+// AddContainer ~ Adds a member to the stack, replacing a previous member of the same name. config.Name is ignored in favour of ContainerName(name).
+// Members start in the order they were added, after their dependencies, and stop in reverse
+func (s *Stack) AddContainer(name string, config ContainerCreateConfig) *Stack {
+	if _, ok := s.configs[name]; !ok {
+		s.members = append(s.members, name)
+	}
+	s.configs[name] = config
+	return s
+}
+
+// DependsOn ~ Holds back member until dependency meets condition (DependencyStarted, DependencyHealthy or DependencyCompleted)
+func (s *Stack) DependsOn(member string, dependency string, condition string) *Stack {
+	if s.depends[member] == nil {
+		s.depends[member] = map[string]string{}
+	}
+	s.depends[member][dependency] = condition
+	return s
+}
+
+// SetProfiles ~ Puts member into profiles like compose profiles do. Members without profiles always run, the others only while one of
+// their profiles is active, see ActivateProfiles
+func (s *Stack) SetProfiles(member string, profiles ...string) *Stack {
+	s.profiles[member] = profiles
+	return s
+}
+
+// ActivateProfiles ~ Replaces the active profiles, "*" activates every profile. StartAll removes the containers of members that
+// are no longer active
+func (s *Stack) ActivateProfiles(profiles ...string) *Stack {
+	s.active = map[string]bool{}
+	for _, profile := range profiles {
+		s.active[profile] = true
+	}
+	return s
+}
+
+// ActiveMembers ~ Returns the members that run with the active profiles in the order they were added. Dependencies of active
+// members run as well, whatever their profiles
+func (s *Stack) ActiveMembers() []string {
+	enabled := map[string]bool{}
+	var enable func(member string)
+	enable = func(member string) {
+		if enabled[member] {
+			return
+		}
+		enabled[member] = true
+		for dependency := range s.depends[member] {
+			enable(dependency)
+		}
+	}
+	for _, member := range s.members {
+		if s.profileActive(member) {
+			enable(member)
+		}
+	}
+
+	active := make([]string, 0, len(enabled))
+	for _, member := range s.members {
+		if enabled[member] {
+			active = append(active, member)
+		}
+	}
+	return active
+}
+
+// profileActive reports whether a member runs on its own, without being a dependency of another one
+func (s *Stack) profileActive(member string) bool {
+	profiles := s.profiles[member]
+	if len(profiles) == 0 || s.active["*"] {
+		return true
+	}
+	for _, profile := range profiles {
+		if s.active[profile] {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNetwork ~ Declares an additional network of the stack. Members join it by setting their network mode to NetworkName(name)
+func (s *Stack) AddNetwork(name string, opts NetworkOptions) *Stack {
+	s.networks[name] = opts
+	return s
+}
+
+// AddVolume ~ Declares a volume of the stack. Members mount it by VolumeName(name)
+func (s *Stack) AddVolume(name string, opts VolumeOptions) *Stack {
+	s.volumes[name] = opts
+	return s
+}
+
+// ContainerName ~ Returns the container name of a member
+func (s *Stack) ContainerName(member string) string {
+	return s.Name + "-" + member
+}
+
+// NetworkName ~ Returns the daemon name of a network of the stack
+func (s *Stack) NetworkName(name string) string {
+	return s.Name + "_" + name
+}
+
+// VolumeName ~ Returns the daemon name of a volume of the stack
+func (s *Stack) VolumeName(name string) string {
+	return s.Name + "_" + name
+}
+
+// StartAll ~ Creates the networks and volumes of the stack and converges every member through a Reconciler for the stack name.
+// Containers labelled for the stack that are no longer members are removed
+func (s *Stack) StartAll(ctx context.Context) error {
+	if s.Name == "" {
+		return errors.New("[ERR:] [STACK] => CANNOT START A STACK WITHOUT A NAME")
+	}
+
+	networks := map[string]NetworkOptions{stackDefaultNetwork: {}}
+	for name, opts := range s.networks {
+		networks[name] = opts
+	}
+	for name, opts := range networks {
+		opts.Labels = s.labels(opts.Labels)
+		if _, _, err := EnsureNetwork(ctx, s.NetworkName(name), opts); err != nil {
+			return err
+		}
+	}
+	for name, opts := range s.volumes {
+		opts.Labels = s.labels(opts.Labels)
+		// Creating a volume that already exists returns the existing one
+		if _, err := CreateVolume(ctx, s.VolumeName(name), opts); err != nil {
+			return err
+		}
+	}
+
+	_, err := NewReconciler(s.Name).Apply(ctx, s.specs())
+	return err
+}
+
+// StopAll ~ Stops the running active members of the stack in reverse start order, dependents before their dependencies. Missing containers are skipped
+func (s *Stack) StopAll(ctx context.Context) error {
+	ordered, err := orderSpecs(s.specs())
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for i := len(ordered) - 1; i >= 0; i-- {
+		name := ordered[i].Name
+		containerJSON, err := DockerClient.ContainerInspect(ctx, name)
+		if client.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, "FAILED TO INSPECT CONTAINER "+name+" => "+err.Error())
+			continue
+		}
+		if containerJSON.State == nil || !containerJSON.State.Running {
+			continue
+		}
+		if err := StopContainerContext(ctx, containerJSON.ID); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [STACK] => FAILED TO STOP STACK " + s.Name + " => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// Status ~ Reports the state of every member of the stack in the order they were added
+func (s *Stack) Status(ctx context.Context) ([]StackMemberStatus, error) {
+	statuses := make([]StackMemberStatus, 0, len(s.members))
+	for _, member := range s.members {
+		status := StackMemberStatus{Name: member, State: StackStateMissing}
+		containerJSON, err := inspectContainer(ctx, s.ContainerName(member))
+		if err != nil && !client.IsErrNotFound(err) {
+			return nil, errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER " + s.ContainerName(member) + " => " + err.Error())
+		}
+		if err == nil {
+			status.ContainerID = containerJSON.ID
+			if containerJSON.State != nil {
+				status.State = containerJSON.State.Status
+				if containerJSON.State.Health != nil {
+					status.Health = containerJSON.State.Health.Status
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// specs turns the active members into reconciler specs, wiring members without a network mode into the shared network and giving
+// every member its name as alias on its stack network
+func (s *Stack) specs() []ContainerSpec {
+	members := s.ActiveMembers()
+	specs := make([]ContainerSpec, 0, len(members))
+	for _, member := range members {
+		config := s.configs[member]
+		config.Name = s.ContainerName(member)
+
+		hostConfig := container.HostConfig{}
+		if config.HostConfig != nil {
+			hostConfig = *config.HostConfig
+		}
+		if hostConfig.NetworkMode == "" {
+			hostConfig.NetworkMode = container.NetworkMode(s.NetworkName(stackDefaultNetwork))
+		}
+		config.HostConfig = &hostConfig
+
+		networkName := string(hostConfig.NetworkMode)
+		if s.isStackNetwork(networkName) {
+			endpoints := map[string]*network.EndpointSettings{}
+			if config.NetworkingConfig != nil {
+				for name, endpoint := range config.NetworkingConfig.EndpointsConfig {
+					endpoints[name] = endpoint
+				}
+			}
+			if _, ok := endpoints[networkName]; !ok {
+				endpoints[networkName] = &network.EndpointSettings{Aliases: []string{member}}
+			}
+			config.NetworkingConfig = &network.NetworkingConfig{EndpointsConfig: endpoints}
+		}
+
+		dependsOn := make(map[string]string, len(s.depends[member]))
+		for dependency, condition := range s.depends[member] {
+			dependsOn[s.ContainerName(dependency)] = condition
+		}
+
+		specs = append(specs, ContainerSpec{ContainerCreateConfig: config, DependsOn: dependsOn})
+	}
+	return specs
+}
+
+// isStackNetwork reports whether a network mode names the shared network or a declared network of the stack
+func (s *Stack) isStackNetwork(networkMode string) bool {
+	if networkMode == s.NetworkName(stackDefaultNetwork) {
+		return true
+	}
+	for name := range s.networks {
+		if networkMode == s.NetworkName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// labels returns a copy of labels with ProjectLabel set to the stack name
+func (s *Stack) labels(labels map[string]string) map[string]string {
+	stamped := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		stamped[key] = value
+	}
+	stamped[ProjectLabel] = s.Name
+	return stamped
+}
@@ -0,0 +1,104 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// GetContainerStats ~ Reads a single stats sample of a container and computes its resource usage
+func GetContainerStats(ctx context.Context, containerID string) (ContainerStats, error) {
+	// A non streaming request waits for the daemon to prime the previous CPU sample, which the CPU percentage needs
+	statsRes, err := retryResult(ctx, func() (container.StatsResponse, error) {
+		return DockerClient.ContainerStats(ctx, containerID, false)
+	})
+	if err != nil {
+		return ContainerStats{}, errors.New("[ERR:] [DOCKER] => FAILED TO GET STATS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer statsRes.Body.Close()
+
+	var raw types.StatsJSON
+	if err := json.NewDecoder(statsRes.Body).Decode(&raw); err != nil {
+		return ContainerStats{}, errors.New("[ERR:] [DOCKER] => FAILED TO DECODE STATS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+
+	return computeContainerStats(raw), nil
+}
+
+// computeContainerStats applies the same math as `docker stats` to a raw stats sample
+func computeContainerStats(raw types.StatsJSON) ContainerStats {
+	stats := ContainerStats{
+		Read:        raw.Read,
+		MemoryUsage: memoryUsageWithoutCache(raw.MemoryStats),
+		MemoryLimit: raw.MemoryStats.Limit,
+		PIDs:        raw.PidsStats.Current,
+	}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(raw.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100.0
+	}
+
+	if stats.MemoryLimit > 0 {
+		stats.MemoryPercent = float64(stats.MemoryUsage) / float64(stats.MemoryLimit) * 100.0
+	}
+
+	for _, network := range raw.Networks {
+		stats.NetworkRx += network.RxBytes
+		stats.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+
+	return stats
+}
+
+// memoryUsageWithoutCache subtracts the page cache from the memory usage, for both cgroup v1 and v2
+func memoryUsageWithoutCache(mem types.MemoryStats) uint64 {
+	if v, ok := mem.Stats["total_inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	if v, ok := mem.Stats["inactive_file"]; ok && v < mem.Usage {
+		return mem.Usage - v
+	}
+	return mem.Usage
+}
+
+// StreamContainerStats ~ Delivers computed stats samples of a container to fn until the context is cancelled or the container stops
+func StreamContainerStats(ctx context.Context, containerID string, fn func(ContainerStats)) error {
+	statsRes, err := DockerClient.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO STREAM STATS OF CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	}
+	defer statsRes.Body.Close()
+
+	decoder := json.NewDecoder(statsRes.Body)
+	for {
+		var raw types.StatsJSON
+		decodeErr := decoder.Decode(&raw)
+		if decodeErr == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if decodeErr != nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO DECODE STATS OF CONTAINER WITH ID: " + containerID + " => " + decodeErr.Error())
+		}
+		fn(computeContainerStats(raw))
+	}
+}
@@ -1,18 +1,30 @@
 package containers
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
-// ContainerCreateConfig A config wrapper for the creation of a container
+// ContainerCreateConfig A config wrapper for the creation of a container. PullPolicy is PullAlways, PullIfNotPresent or PullNever,
+// empty leaves a missing image to the daemon
 type ContainerCreateConfig struct {
 	Name             string
 	Config           *container.Config
 	HostConfig       *container.HostConfig
 	NetworkingConfig *network.NetworkingConfig
 	Platform         *v1.Platform
+	PullPolicy       string
 }
 
 type ImageBuildOut struct {
@@ -21,9 +33,708 @@ type ImageBuildOut struct {
 	Id             string         `json:"id"`
 	Progress       string         `json:"progress,omitempty"`
 	ProgressDetail ProgressDetail `json:"progressDetail,omitempty,mapstructure,squash"`
+	Error          string         `json:"error,omitempty"`
+	ErrorDetail    ErrorDetail    `json:"errorDetail,omitempty"`
+}
+
+// ErrorDetail ~ The structured error of a build or pull stream. Podman may only fill this one and leave Error empty
+type ErrorDetail struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type ProgressDetail struct {
 	Current int `json:"current,omitempty"`
 	Total   int `json:"total,omitempty"`
 }
+
+// HealthStatus ~ The health of a container, see GetContainerHealthStatus
+type HealthStatus string
+
+const (
+	// HealthStarting ~ The healthcheck has not passed yet and the container is within its start period
+	HealthStarting HealthStatus = "starting"
+	// HealthHealthy ~ The last healthcheck passed
+	HealthHealthy HealthStatus = "healthy"
+	// HealthUnhealthy ~ The healthcheck failed as many times in a row as it may
+	HealthUnhealthy HealthStatus = "unhealthy"
+	// HealthNoHealthcheck ~ The container runs without a healthcheck
+	HealthNoHealthcheck HealthStatus = "none"
+	// HealthCreated ~ The container was created but never started
+	HealthCreated HealthStatus = "created"
+	// HealthRestarting ~ The container exited and its restart policy is starting it again
+	HealthRestarting HealthStatus = "restarting"
+	// HealthPaused ~ The container is paused, so its healthcheck does not run
+	HealthPaused HealthStatus = "paused"
+	// HealthExited ~ The container stopped
+	HealthExited HealthStatus = "exited"
+	// HealthRemoving ~ The container is being removed
+	HealthRemoving HealthStatus = "removing"
+	// HealthDead ~ The daemon failed to remove the container and gave up on it
+	HealthDead HealthStatus = "dead"
+)
+
+const (
+	// LogStreamStdout ~ Marks a log line written to stdout
+	LogStreamStdout = "stdout"
+	// LogStreamStderr ~ Marks a log line written to stderr
+	LogStreamStderr = "stderr"
+)
+
+// LogLine ~ A single parsed line of container output
+type LogLine struct {
+	Timestamp time.Time
+	Stream    string
+	Message   string
+}
+
+// ContainerStats ~ A computed resource usage sample of a container
+type ContainerStats struct {
+	Read          time.Time
+	CPUPercent    float64
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+	NetworkRx     uint64
+	NetworkTx     uint64
+	BlockRead     uint64
+	BlockWrite    uint64
+	PIDs          uint64
+}
+
+// ResourceUpdate ~ The resource limits that can be changed on a running container. Zero values are left unchanged
+type ResourceUpdate struct {
+	CPUShares         int64
+	CPUPeriod         int64
+	CPUQuota          int64
+	NanoCPUs          int64
+	CpusetCpus        string
+	Memory            int64
+	MemoryReservation int64
+	MemorySwap        int64
+	PidsLimit         *int64
+	BlkioWeight       uint16
+}
+
+// ContainerProcess ~ A row of the process table of a container. Fields holds every column by its ps title
+type ContainerProcess struct {
+	User    string
+	PID     string
+	PPID    string
+	CPU     string
+	Memory  string
+	Started string
+	TTY     string
+	Time    string
+	Command string
+	Fields  map[string]string
+}
+
+// ContainerDiffResult ~ The filesystem changes of a container grouped by kind
+type ContainerDiffResult struct {
+	Added   []string
+	Changed []string
+	Deleted []string
+}
+
+// TerminalSize ~ The dimensions of a terminal in characters
+type TerminalSize struct {
+	Height uint
+	Width  uint
+}
+
+// TerminalOptions ~ Options of an interactive TTY session. Sizes sent on Resize are forwarded to the remote TTY
+type TerminalOptions struct {
+	Height uint
+	Width  uint
+	Resize <-chan TerminalSize
+}
+
+// ExecOptions ~ Options of a command executed on a running container. Env entries are in KEY=VALUE form
+type ExecOptions struct {
+	User       string
+	WorkingDir string
+	Env        []string
+	Privileged bool
+	Stdin      io.Reader
+}
+
+// ExecResult ~ The outcome of a command executed on a running container
+type ExecResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+}
+
+// AttachStreams ~ The local streams attached to a container. Nil streams are not attached
+type AttachStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// RunSpec ~ Describes a one-shot container run. Image, Cmd, Env and PullPolicy are shortcuts applied on top of Create, which may be nil
+type RunSpec struct {
+	Image      string
+	Cmd        []string
+	Env        []string
+	PullPolicy string
+	Create     *ContainerCreateConfig
+}
+
+// RunResult ~ The outcome of a one-shot container run
+type RunResult struct {
+	ContainerID string
+	ExitCode    int64
+	Stdout      string
+	Stderr      string
+	Duration    time.Duration
+}
+
+// HealthcheckOptions ~ Timing of a healthcheck. Zero values inherit the image or daemon defaults
+type HealthcheckOptions struct {
+	Interval      time.Duration
+	Timeout       time.Duration
+	StartPeriod   time.Duration
+	StartInterval time.Duration
+	Retries       int
+}
+
+// NetworkOptions ~ Options of a network. Subnet, IPRange and Gateway are in CIDR / IP form and configure a single IPAM pool
+type NetworkOptions struct {
+	Driver     string
+	Subnet     string
+	IPRange    string
+	Gateway    string
+	Internal   bool
+	Attachable bool
+	EnableIPv6 bool
+	Labels     map[string]string
+	Options    map[string]string
+}
+
+// EndpointOptions ~ Options of a container's connection to a network
+type EndpointOptions struct {
+	Aliases     []string
+	IPv4Address string
+	IPv6Address string
+}
+
+// NetworkFilter ~ Selects networks by name and labels. A label with an empty value matches on the key only
+type NetworkFilter struct {
+	Name   string
+	Labels map[string]string
+}
+
+// ContainerInfo ~ A simplified view of a container, see InspectContainer. Image is the image the container was created from and
+// ImageID what it resolved to. State is created, running, paused, restarting, removing, exited or dead. IPs maps network names to
+// the IPv4 address of the container on them
+type ContainerInfo struct {
+	ID           string
+	Name         string
+	Image        string
+	ImageID      string
+	State        string
+	ExitCode     int64
+	CreatedAt    time.Time
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	RestartCount int
+	IPs          map[string]string
+	Mounts       []MountInfo
+	Env          []string
+	Labels       map[string]string
+}
+
+// NetworkAddresses ~ The addresses of a container on a network, see GetContainerIPs. IPv6 is only set on IPv6 enabled networks
+type NetworkAddresses struct {
+	IPv4 string
+	IPv6 string
+}
+
+// MountInfo ~ A mount of a container. Name is only set for volumes
+type MountInfo struct {
+	Type        string
+	Name        string
+	Source      string
+	Destination string
+	ReadOnly    bool
+}
+
+// NetworkInfo ~ A simplified view of a network
+type NetworkInfo struct {
+	ID         string
+	Name       string
+	Driver     string
+	Scope      string
+	Internal   bool
+	Attachable bool
+	EnableIPv6 bool
+	Subnets    []string
+	Gateways   []string
+	Labels     map[string]string
+	Options    map[string]string
+	Containers []NetworkContainer
+}
+
+// NetworkContainer ~ A container attached to a network. Addresses are in CIDR form
+type NetworkContainer struct {
+	ID          string
+	Name        string
+	IPv4Address string
+	IPv6Address string
+}
+
+// VolumeOptions ~ Options of a named volume
+type VolumeOptions struct {
+	Driver     string
+	DriverOpts map[string]string
+	Labels     map[string]string
+}
+
+// VolumeFilter ~ Selects volumes. A label with an empty value matches on the key only, a nil Dangling matches both
+type VolumeFilter struct {
+	Name     string
+	Driver   string
+	Labels   map[string]string
+	Dangling *bool
+}
+
+// VolumePruneOptions ~ Options of a volume prune. All includes named volumes, not only anonymous ones
+type VolumePruneOptions struct {
+	All    bool
+	Labels map[string]string
+}
+
+// SystemPruneOptions ~ Options of a system wide prune. Until takes a duration or timestamp (e.g. "24h") and does not apply to volumes
+type SystemPruneOptions struct {
+	AllImages  bool
+	Volumes    bool
+	AllVolumes bool
+	Until      string
+	Labels     map[string]string
+}
+
+// SystemPruneReport ~ The combined outcome of a system wide prune
+type SystemPruneReport struct {
+	Containers     container.PruneReport
+	Images         image.PruneReport
+	Networks       network.PruneReport
+	Volumes        volume.PruneReport
+	BuildCache     types.BuildCachePruneReport
+	SpaceReclaimed uint64
+}
+
+// DiskUsageReport ~ Disk space used per category, see DiskUsage
+type DiskUsageReport struct {
+	Images     DiskUsageCategory
+	Containers DiskUsageCategory
+	Volumes    DiskUsageCategory
+	BuildCache DiskUsageCategory
+}
+
+// DiskUsageCategory ~ Disk space used by one category of objects. Sizes are in bytes
+type DiskUsageCategory struct {
+	Count       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// DaemonInfo ~ A summary of the daemon and its host
+type DaemonInfo struct {
+	ID                string
+	Name              string
+	ServerVersion     string
+	OSType            string
+	OperatingSystem   string
+	KernelVersion     string
+	Architecture      string
+	StorageDriver     string
+	CgroupDriver      string
+	CgroupVersion     string
+	Rootless          bool
+	NCPU              int
+	MemTotal          int64
+	DockerRootDir     string
+	Containers        int
+	ContainersRunning int
+	Images            int
+}
+
+// VersionInfo ~ The version of the daemon. ClientAPIVersion is the API version the client negotiated with it
+type VersionInfo struct {
+	Version          string
+	APIVersion       string
+	MinAPIVersion    string
+	ClientAPIVersion string
+	GitCommit        string
+	GoVersion        string
+	OS               string
+	Arch             string
+	KernelVersion    string
+	Experimental     bool
+}
+
+// Event ~ A daemon event. Type is e.g. container, image, network or volume and Action e.g. start, die, pull or health_status.
+// Name and Image are filled when the actor carries them, HealthStatus only for health_status events
+type Event struct {
+	Type         string
+	Action       string
+	ActorID      string
+	Name         string
+	Image        string
+	HealthStatus string
+	Attributes   map[string]string
+	Time         time.Time
+}
+
+// WatchdogOptions ~ Options of a Watchdog. Containers (names or IDs) and Labels select what is watched, nothing set watches every container.
+// Backoff doubles after every action on a container up to MaxBackoff. MaxRestarts of 0 means no limit. A nil Handler restarts the container
+type WatchdogOptions struct {
+	Containers  []string
+	Labels      map[string]string
+	MaxRestarts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Handler     func(ctx context.Context, event Event) error
+	OnError     func(event Event, err error)
+	OnGiveUp    func(event Event)
+}
+
+// ContainerSpec ~ The desired state of a container managed by a Reconciler. DependsOn maps the names of other specs to the
+// condition (DependencyStarted, DependencyHealthy or DependencyCompleted) they must meet before this container starts
+type ContainerSpec struct {
+	ContainerCreateConfig
+	DependsOn map[string]string
+}
+
+// PlannedChange ~ A change a Reconciler makes to converge a container. Reasons lists the drift behind a recreate
+type PlannedChange struct {
+	Name        string
+	ContainerID string
+	Action      string
+	Reasons     []string
+}
+
+// StackMemberStatus ~ The state of a container of a Stack. State is the docker state (running, exited, ...) or missing when the
+// container does not exist, Health is empty for containers without a healthcheck
+type StackMemberStatus struct {
+	Name        string
+	ContainerID string
+	State       string
+	Health      string
+}
+
+// DeployOptions ~ Options of Deploy. With Network and Alias set the new container joins Network under Alias before the old one
+// goes away, so clients resolving the alias are handed over. HealthTimeout defaults to one minute and HealthInterval to one second
+type DeployOptions struct {
+	Network        string
+	Alias          string
+	HealthTimeout  time.Duration
+	HealthInterval time.Duration
+}
+
+// RollingUpdateOptions ~ Options of RollingUpdate. BatchSize replicas are replaced at a time, one if unset. Deploy configures how each
+// replica is replaced and health checked. With Rollback set, a failed update puts every replica already replaced back on its previous config
+type RollingUpdateOptions struct {
+	BatchSize int
+	Deploy    DeployOptions
+	Rollback  bool
+}
+
+// CanaryOptions ~ Options of Canary. With Network and Alias set the canary joins the alias the stable replicas serve under; Docker's
+// DNS balances an alias evenly, so Weight extra canary containers (one if unset) set its share of the traffic. The canary must become ready
+// within HealthTimeout, then runs for Observe before Check decides on it. Rollout configures the promotion
+type CanaryOptions struct {
+	Network        string
+	Alias          string
+	Weight         int
+	HealthTimeout  time.Duration
+	HealthInterval time.Duration
+	Observe        time.Duration
+	Check          func(ctx context.Context, canaryIDs []string) error
+	Rollout        RollingUpdateOptions
+}
+
+// CanaryResult ~ The outcome of Canary. Reason explains a discarded canary, ReplicaIDs lists the replicas after a promotion
+type CanaryResult struct {
+	Promoted   bool
+	Reason     string
+	ReplicaIDs []string
+}
+
+// ShutdownOptions ~ Options of a ShutdownManager. Timeout is the default grace period of a tracked container, 10 seconds if unset.
+// HandleSignals shuts down on SIGINT/SIGTERM and reports the outcome to OnShutdown. TrackStarts tracks every container StartContainer starts
+type ShutdownOptions struct {
+	Timeout       time.Duration
+	HandleSignals bool
+	TrackStarts   bool
+	OnShutdown    func(err error)
+}
+
+// PoolOptions ~ Options of a Pool. Min workers are kept around, at most Max (Min if lower) exist at once. Recycle replaces a worker with a
+// fresh container on every Release. Idle workers above Min are removed once unused for IdleTimeout, never if it is zero
+type PoolOptions struct {
+	Min         int
+	Max         int
+	Recycle     bool
+	IdleTimeout time.Duration
+}
+
+// BuildSpec ~ An image to build with BuildImages. Context is the directory sent to the daemon, Dockerfile is relative to it and defaults to
+// Dockerfile. The first tag names the image in progress output and results
+type BuildSpec struct {
+	Context    string
+	Dockerfile string
+	Tags       []string
+	BuildArgs  map[string]string
+	Labels     map[string]string
+	NoCache    bool
+	PullParent bool
+}
+
+// BuildResult ~ The outcome of building one BuildSpec
+type BuildResult struct {
+	Image    string
+	ImageID  string
+	Duration time.Duration
+	Err      error
+}
+
+// RetryPolicy ~ How idempotent operations retry transient daemon errors. The wait starts at InitialBackoff and is multiplied by Multiplier
+// (2 if unset) after every attempt, capped at MaxBackoff. RetryOn decides which errors are retried, IsTransientError if nil.
+// A MaxAttempts below 2 disables retries
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	RetryOn        func(err error) bool
+}
+
+// PullLimits ~ Throttling of image pulls. Rate caps pulls per second with bursts of Burst (1 if unset), 0 leaves pulls unthrottled.
+// A pull rejected with 429 / toomanyrequests waits Backoff (30s if unset), doubling up to MaxBackoff (5m if unset), for up to MaxRetries
+// retries. Mirrors are set per registry with SetRegistryMirrors
+type PullLimits struct {
+	Rate       float64
+	Burst      int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	MaxRetries int
+}
+
+// AuditRecord ~ An entry of the audit log: who ran which mutating operation on what, when, with which parameters and how it ended.
+// Result is success or error
+type AuditRecord struct {
+	Time       time.Time         `json:"time"`
+	Actor      string            `json:"actor,omitempty"`
+	Operation  string            `json:"operation"`
+	Target     string            `json:"target,omitempty"`
+	Image      string            `json:"image,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+	Result     string            `json:"result"`
+	Error      string            `json:"error,omitempty"`
+	ErrorType  string            `json:"error_type,omitempty"`
+}
+
+// AuditOptions ~ Options of an Auditor. DefaultActor is recorded for operations whose context carries no actor (see WithActor).
+// OnError is told about records the sink failed to store
+type AuditOptions struct {
+	DefaultActor string
+	OnError      func(record AuditRecord, err error)
+}
+
+// Secret ~ A secret value materialized into a container as a file at Target, /run/secrets/<Name> by default. Mode defaults to 0400 and
+// UID/GID to root. Env additionally exposes the value as that environment variable, which is only done when asked for
+type Secret struct {
+	Name   string
+	Value  []byte
+	Target string
+	Mode   os.FileMode
+	UID    int
+	GID    int
+	Env    string
+}
+
+// SecretsOptions ~ Options of Secrets. SecretsCopy mode, the default, copies the values into the created container. SecretsHostFiles
+// mode writes the values under Dir, a memory backed directory by default, and bind mounts them read only. SecretsTmpfs mode mounts a
+// tmpfs in the container and writes the values into it once it runs
+type SecretsOptions struct {
+	Mode string
+	Dir  string
+}
+
+// SpecOptions ~ Options of LoadSpecWithOptions and LoadStackWithOptions. Variables come from the process environment, then EnvFiles
+// in order and then Env. With Template set the file is first rendered as a Go template with the variables as .Env, the env and
+// default functions and Funcs. Profiles are the profiles activated on loaded stacks
+type SpecOptions struct {
+	Env      map[string]string
+	EnvFiles []string
+	Template bool
+	Funcs    template.FuncMap
+	Profiles []string
+}
+
+// WebhookOptions ~ Options of a WebhookNotifier. Actions are the container events reported, start, stop, die and health_status by default.
+// Containers (names or IDs) and Labels select the containers, e.g. ManagedByLabel for managed ones. With Secret set every payload is
+// signed with HMAC-SHA256. Failed deliveries are retried MaxRetries times (3 by default) with a backoff doubling from Backoff (1s).
+// Timeout bounds every attempt (10s), OnError is told about deliveries that failed for good
+type WebhookOptions struct {
+	URLs       []string
+	Secret     string
+	Actions    []string
+	Containers []string
+	Labels     map[string]string
+	MaxRetries int
+	Backoff    time.Duration
+	Timeout    time.Duration
+	Client     *http.Client
+	OnError    func(url string, payload WebhookPayload, err error)
+}
+
+// WebhookPayload ~ The JSON body posted to webhooks. ExitCode is set for die events, HealthStatus for health_status events
+type WebhookPayload struct {
+	ID           string            `json:"id"`
+	Event        string            `json:"event"`
+	ContainerID  string            `json:"container_id"`
+	Name         string            `json:"name,omitempty"`
+	Image        string            `json:"image,omitempty"`
+	HealthStatus string            `json:"health_status,omitempty"`
+	ExitCode     *int              `json:"exit_code,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Time         time.Time         `json:"time"`
+}
+
+// ScanOptions ~ Options of ScanImage. Scanner is the Trivy image, Server the URL of a Trivy server to scan with instead of a local
+// database. Socket is the daemon socket mounted into the scanner, /var/run/docker.sock by default. CacheVolume keeps the vulnerability
+// database between scans. FailOn is the lowest severity that fails the scan, nothing fails when empty
+type ScanOptions struct {
+	Scanner       string
+	Server        string
+	Socket        string
+	CacheVolume   string
+	FailOn        string
+	IgnoreUnfixed bool
+	Timeout       time.Duration
+}
+
+// ScanReport ~ The vulnerabilities found in an image, with the number found per severity
+type ScanReport struct {
+	Image           string
+	Vulnerabilities []Vulnerability
+	Counts          map[string]int
+}
+
+// Vulnerability ~ A vulnerable package of an image. Target is the part of the image it was found in, e.g. the OS or a lock file
+type Vulnerability struct {
+	ID               string
+	Package          string
+	InstalledVersion string
+	FixedVersion     string
+	Severity         string
+	Title            string
+	URL              string
+	Target           string
+}
+
+// SignaturePolicy ~ The signatures VerifyImage accepts. PublicKey is the path of a cosign public key, without one Identity and Issuer
+// select the certificate of a keyless signature. IgnoreTlog skips the transparency log for private setups. Exempt lists the images
+// signature enforcement lets through unverified
+type SignaturePolicy struct {
+	PublicKey  string
+	Identity   string
+	Issuer     string
+	IgnoreTlog bool
+	Exempt     []string
+}
+
+// RegistryConfig ~ How a registry is reached, see ConfigureRegistry. IdentityToken is an OAuth refresh token and is used instead of
+// Username and Password when set. CAFile is a PEM bundle trusted on top of the system roots and Insecure allows plain HTTP, either
+// makes the package speak to the registry itself
+type RegistryConfig struct {
+	Username      string
+	Password      string
+	IdentityToken string
+	CAFile        string
+	Insecure      bool
+}
+
+// ImageGCPolicy ~ Which unused images GCImages removes. MaxAge removes images created longer ago, KeepPerRepository keeps only the
+// newest images of each repository, at least one of them must be set. Labels restricts the images considered and Keep lists tag
+// patterns such as "myapp:stable" or "registry.internal/*" that are never removed
+type ImageGCPolicy struct {
+	MaxAge            time.Duration
+	KeepPerRepository int
+	Labels            map[string]string
+	Keep              []string
+}
+
+// ImageGCReport ~ The images GCImages removed, by tag or ID, and their combined size, which counts layers shared between them once per image
+type ImageGCReport struct {
+	Removed        []string
+	SpaceReclaimed uint64
+}
+
+// ContainerGCPolicy ~ Which exited and dead containers GCContainers removes. MaxAge only removes containers that finished longer ago,
+// Labels only those carrying all of them. DryRun reports the containers without removing them
+type ContainerGCPolicy struct {
+	MaxAge time.Duration
+	Labels map[string]string
+	DryRun bool
+}
+
+// ContainerGCReport ~ The containers GCContainers removed, or would remove in a dry run, by name and the size of their writable layers
+type ContainerGCReport struct {
+	Removed        []string
+	SpaceReclaimed uint64
+}
+
+// ReaperOptions ~ Options of a Reaper. Labels restricts the containers reaped, OnReap is called with the ID of every container purged
+// and OnError with the failures of a check
+type ReaperOptions struct {
+	Interval time.Duration
+	Labels   map[string]string
+	OnReap   func(containerID string)
+	OnError  func(err error)
+}
+
+// SchedulerOptions ~ Options of a Scheduler. OnResult is called with the status of a job after each of its runs
+type SchedulerOptions struct {
+	Location *time.Location
+	OnResult func(status JobStatus)
+}
+
+// JobStatus ~ The state of a scheduled job. Runs counts finished runs, Failures those that errored or exited non-zero and Skipped
+// the runs dropped because the previous one was still going. LastResult holds the exit code, duration and output of the last run
+type JobStatus struct {
+	Name       string
+	Schedule   string
+	Running    bool
+	NextRun    time.Time
+	LastRun    time.Time
+	LastResult RunResult
+	LastError  string
+	Runs       int
+	Failures   int
+	Skipped    int
+}
+
+// LogRouterOptions ~ Options of a LogRouter. Logs selects what is read from every container (e.g. Tail or Since), Color colors the
+// name prefix of each container and Timestamps adds the time of every line after it
+type LogRouterOptions struct {
+	Writers    []io.Writer
+	Logs       container.LogsOptions
+	Color      bool
+	Timestamps bool
+}
+
+// LogRecord ~ A complete line of container output, see LogParser. Timestamp is only set when the logs were read with timestamps
+type LogRecord struct {
+	ContainerID string
+	Stream      string
+	Timestamp   time.Time
+	Message     string
+}
@@ -0,0 +1,202 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/system"
+)
+
+// SystemPrune ~ Prunes stopped containers, unused networks, dangling (or with AllImages all unused) images and the build cache,
+// plus unused volumes when Volumes is set, like `docker system prune`. Returns the combined report of everything removed
+func SystemPrune(ctx context.Context, opts SystemPruneOptions) (SystemPruneReport, error) {
+	if dryRun(OpSystemPrune, "", "", "all_images", strconv.FormatBool(opts.AllImages), "volumes", strconv.FormatBool(opts.Volumes)) {
+		return SystemPruneReport{}, nil
+	}
+	ctx, done := beginOperation(ctx, OpSystemPrune, "", "", "all_images", strconv.FormatBool(opts.AllImages), "volumes", strconv.FormatBool(opts.Volumes))
+	report, err := systemPrune(ctx, opts)
+	done(err)
+	return report, err
+}
+
+// systemPrune does the work of SystemPrune
+func systemPrune(ctx context.Context, opts SystemPruneOptions) (SystemPruneReport, error) {
+	var report SystemPruneReport
+
+	pruneFilters := filters.NewArgs()
+	if opts.Until != "" {
+		pruneFilters.Add("until", opts.Until)
+	}
+	addLabelFilters(pruneFilters, opts.Labels)
+
+	containerReport, err := DockerClient.ContainersPrune(ctx, pruneFilters)
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE CONTAINERS  | => " + err.Error())
+	}
+	report.Containers = containerReport
+
+	networkReport, err := DockerClient.NetworksPrune(ctx, pruneFilters)
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE NETWORKS  | => " + err.Error())
+	}
+	report.Networks = networkReport
+
+	imageFilters := pruneFilters.Clone()
+	imageFilters.Add("dangling", strconv.FormatBool(!opts.AllImages))
+	imageReport, err := DockerClient.ImagesPrune(ctx, imageFilters)
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE IMAGES  | => " + err.Error())
+	}
+	report.Images = imageReport
+
+	if opts.Volumes {
+		// Volumes do not support the until filter
+		volumeReport, err := PruneVolumes(ctx, VolumePruneOptions{All: opts.AllVolumes, Labels: opts.Labels})
+		if err != nil {
+			return report, err
+		}
+		report.Volumes = volumeReport
+	}
+
+	buildCacheFilters := filters.NewArgs()
+	if opts.Until != "" {
+		buildCacheFilters.Add("until", opts.Until)
+	}
+	buildCacheReport, err := DockerClient.BuildCachePrune(ctx, types.BuildCachePruneOptions{
+		All:     opts.AllImages,
+		Filters: buildCacheFilters,
+	})
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE BUILD CACHE  | => " + err.Error())
+	}
+	if buildCacheReport != nil {
+		report.BuildCache = *buildCacheReport
+	}
+
+	report.SpaceReclaimed = report.Containers.SpaceReclaimed +
+		report.Images.SpaceReclaimed +
+		report.Volumes.SpaceReclaimed +
+		report.BuildCache.SpaceReclaimed
+	return report, nil
+}
+
+// DiskUsage ~ Reports the disk space used by images, containers, volumes and the build cache and how much of it is reclaimable, like `docker system df`
+func DiskUsage(ctx context.Context) (DiskUsageReport, error) {
+	var report DiskUsageReport
+	usage, err := retryResult(ctx, func() (types.DiskUsage, error) {
+		return DockerClient.DiskUsage(ctx, types.DiskUsageOptions{})
+	})
+	if err != nil {
+		return report, errors.New("[ERR:] [DOCKER] => FAILED TO GET DISK USAGE => " + err.Error())
+	}
+
+	// Layers shared with images in use cannot be reclaimed
+	report.Images.Size = usage.LayersSize
+	var imagesInUse int64
+	for _, img := range usage.Images {
+		report.Images.Count++
+		if img.Containers > 0 {
+			report.Images.Active++
+			if img.Size >= 0 && img.SharedSize >= 0 {
+				imagesInUse += img.Size - img.SharedSize
+			}
+		}
+	}
+	report.Images.Reclaimable = usage.LayersSize - imagesInUse
+
+	for _, c := range usage.Containers {
+		report.Containers.Count++
+		report.Containers.Size += c.SizeRw
+		if c.State == "running" {
+			report.Containers.Active++
+		} else {
+			report.Containers.Reclaimable += c.SizeRw
+		}
+	}
+
+	for _, v := range usage.Volumes {
+		report.Volumes.Count++
+		if v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		report.Volumes.Size += v.UsageData.Size
+		if v.UsageData.RefCount > 0 {
+			report.Volumes.Active++
+		} else {
+			report.Volumes.Reclaimable += v.UsageData.Size
+		}
+	}
+
+	for _, cache := range usage.BuildCache {
+		report.BuildCache.Count++
+		report.BuildCache.Size += cache.Size
+		if cache.InUse {
+			report.BuildCache.Active++
+		} else if !cache.Shared {
+			report.BuildCache.Reclaimable += cache.Size
+		}
+	}
+
+	return report, nil
+}
+
+// Info ~ Returns a summary of the daemon and its host, useful for feature detection
+func Info(ctx context.Context) (DaemonInfo, error) {
+	info, err := retryResult(ctx, func() (system.Info, error) {
+		return DockerClient.Info(ctx)
+	})
+	if err != nil {
+		return DaemonInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO GET DAEMON INFO => " + err.Error())
+	}
+
+	daemonInfo := DaemonInfo{
+		ID:                info.ID,
+		Name:              info.Name,
+		ServerVersion:     info.ServerVersion,
+		OSType:            info.OSType,
+		OperatingSystem:   info.OperatingSystem,
+		KernelVersion:     info.KernelVersion,
+		Architecture:      info.Architecture,
+		StorageDriver:     info.Driver,
+		CgroupDriver:      info.CgroupDriver,
+		CgroupVersion:     info.CgroupVersion,
+		NCPU:              info.NCPU,
+		MemTotal:          info.MemTotal,
+		DockerRootDir:     info.DockerRootDir,
+		Containers:        info.Containers,
+		ContainersRunning: info.ContainersRunning,
+		Images:            info.Images,
+	}
+	for _, option := range info.SecurityOptions {
+		if option == "name=rootless" {
+			daemonInfo.Rootless = true
+		}
+	}
+	return daemonInfo, nil
+}
+
+// ServerVersion ~ Returns the version of the daemon and the API version negotiated by the client
+func ServerVersion(ctx context.Context) (VersionInfo, error) {
+	version, err := retryResult(ctx, func() (types.Version, error) {
+		return DockerClient.ServerVersion(ctx)
+	})
+	if err != nil {
+		return VersionInfo{}, errors.New("[ERR:] [DOCKER] => FAILED TO GET SERVER VERSION => " + err.Error())
+	}
+
+	return VersionInfo{
+		Version:          version.Version,
+		APIVersion:       version.APIVersion,
+		MinAPIVersion:    version.MinAPIVersion,
+		ClientAPIVersion: DockerClient.ClientVersion(),
+		GitCommit:        version.GitCommit,
+		GoVersion:        version.GoVersion,
+		OS:               version.Os,
+		Arch:             version.Arch,
+		KernelVersion:    version.KernelVersion,
+		Experimental:     version.Experimental,
+	}, nil
+}
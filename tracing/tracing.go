@@ -0,0 +1,64 @@
+// Package tracing records the operations of the containers package as OpenTelemetry spans
+package tracing
+
+import (
+	"context"
+
+	"github.com/G-MAKROGLOU/containers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName ~ The name spans of this package are recorded under
+const instrumentationName = "github.com/G-MAKROGLOU/containers"
+
+// Tracer ~ Starts a span for every operation of the containers package, as a child of the span in the context the operation was called with
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer ~ Creates a tracer recording to provider. A nil provider uses the global one of otel
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// Enable ~ Creates a tracer recording to provider and starts observing the containers package with it
+func Enable(provider trace.TracerProvider) *Tracer {
+	tracer := NewTracer(provider)
+	containers.AddObserver(tracer)
+	return tracer
+}
+
+// OperationStarted ~ Implements containers.Observer
+func (t *Tracer) OperationStarted(ctx context.Context, op containers.Operation) context.Context {
+	attributes := []attribute.KeyValue{attribute.String("containers.operation", op.Name)}
+	if op.Target != "" {
+		attributes = append(attributes, attribute.String("containers.target", op.Target))
+	}
+	if op.Image != "" {
+		attributes = append(attributes, attribute.String("container.image.name", op.Image))
+	}
+
+	ctx, _ = t.tracer.Start(ctx, "containers."+op.Name,
+		trace.WithTimestamp(op.Start),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	return ctx
+}
+
+// OperationFinished ~ Implements containers.Observer
+func (t *Tracer) OperationFinished(ctx context.Context, op containers.Operation) {
+	span := trace.SpanFromContext(ctx)
+	if op.Err != nil {
+		span.RecordError(op.Err)
+		span.SetAttributes(attribute.String("error.type", containers.ErrorType(op.Err)))
+		span.SetStatus(codes.Error, op.Err.Error())
+	}
+	span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+}
@@ -0,0 +1,122 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// TTLLabel ~ The label holding how long a container may live once started, as a Go duration, see SetTTL
+const TTLLabel = "com.github.g-makroglou.containers.ttl"
+
+// SetTTL ~ Labels the config of a container about to be created to live for ttl after every start. A Reaper stops and purges the
+// container once that lapses
+func SetTTL(config *ContainerCreateConfig, ttl time.Duration) {
+	ensureContainerConfigs(config)
+	labels := make(map[string]string, len(config.Config.Labels)+1)
+	for key, value := range config.Config.Labels {
+		labels[key] = value
+	}
+	labels[TTLLabel] = ttl.String()
+	config.Config.Labels = labels
+}
+
+// Reaper ~ Stops and purges containers whose TTL lapsed. The TTL lives in a label, so containers started by a previous process
+// are reaped as well
+type Reaper struct {
+	opts ReaperOptions
+	mu   sync.Mutex
+}
+
+// NewReaper ~ Creates a reaper checking every Interval, 30 seconds if unset
+func NewReaper(opts ReaperOptions) *Reaper {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	return &Reaper{opts: opts}
+}
+
+// Run ~ Reaps expired containers every Interval until the context is cancelled
+func (r *Reaper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Reap(ctx); err != nil && r.opts.OnError != nil {
+			r.opts.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap ~ Stops and purges the containers whose TTL lapsed, running or not, and returns their IDs. A TTL counts from the last start
+// of a container, or from its creation if it never started. Returns a *BatchError for the containers that could not be purged
+func (r *Reaper) Reap(ctx context.Context) ([]string, error) {
+	// Overlapping ticks and manual calls would race to purge the same containers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	listFilters := filters.NewArgs(filters.Arg("label", TTLLabel))
+	addLabelFilters(listFilters, r.opts.Labels)
+	containerList, err := retryResult(ctx, func() ([]types.Container, error) {
+		return DockerClient.ContainerList(ctx, container.ListOptions{All: true, Filters: listFilters})
+	})
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO LIST CONTAINERS => " + err.Error())
+	}
+
+	var reaped []string
+	failures := map[string]error{}
+	now := time.Now()
+	for _, c := range containerList {
+		ttl, err := time.ParseDuration(c.Labels[TTLLabel])
+		if err != nil {
+			failures[c.ID] = errors.New("[ERR:] [TTL] => INVALID TTL " + c.Labels[TTLLabel] + " => " + err.Error())
+			continue
+		}
+		containerJSON, err := DockerClient.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			// Removed since it was listed
+			continue
+		}
+		if containerJSON.State == nil {
+			failures[c.ID] = errors.New("[ERR:] [TTL] => CONTAINER WITH ID: " + c.ID + " HAS NO STATE")
+			continue
+		}
+		started, err := time.Parse(time.RFC3339Nano, containerJSON.State.StartedAt)
+		if err != nil || started.IsZero() {
+			started = time.Unix(c.Created, 0)
+		}
+		if now.Sub(started) < ttl {
+			continue
+		}
+
+		if containerJSON.State.Running {
+			if err := StopContainerContext(ctx, c.ID); err != nil {
+				failures[c.ID] = err
+				continue
+			}
+		}
+		if err := PurgeContainerContext(ctx, c.ID); err != nil {
+			failures[c.ID] = err
+			continue
+		}
+		reaped = append(reaped, c.ID)
+		if r.opts.OnReap != nil {
+			r.opts.OnReap(c.ID)
+		}
+	}
+
+	if len(failures) > 0 {
+		return reaped, &BatchError{Operation: "REAP CONTAINERS", Total: len(reaped) + len(failures), Failures: failures}
+	}
+	return reaped, nil
+}
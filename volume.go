@@ -0,0 +1,183 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// CreateVolume ~ Creates a named volume and returns its name. An empty driver defaults to local
+func CreateVolume(ctx context.Context, name string, opts VolumeOptions) (string, error) {
+	if dryRun(OpCreateVolume, name, "") {
+		return name, nil
+	}
+	ctx, done := beginOperation(ctx, OpCreateVolume, name, "")
+	vol, err := DockerClient.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       name,
+		Driver:     opts.Driver,
+		DriverOpts: opts.DriverOpts,
+		Labels:     withManagedLabel(opts.Labels),
+	})
+	done(err)
+	if err != nil {
+		return "", errors.New("[ERR:] [DOCKER] => FAILED TO CREATE VOLUME " + name + " => " + err.Error())
+	}
+	return vol.Name, nil
+}
+
+// RemoveVolume ~ Removes a volume. force also removes it when the daemon considers it in use
+func RemoveVolume(ctx context.Context, name string, force bool) error {
+	if dryRun(OpRemoveVolume, name, "", "force", strconv.FormatBool(force)) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpRemoveVolume, name, "", "force", strconv.FormatBool(force))
+	err := DockerClient.VolumeRemove(ctx, name, force)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO REMOVE VOLUME " + name + " => " + err.Error())
+	}
+	return nil
+}
+
+// ListVolumes ~ Lists volumes, optionally filtered by name, driver, labels and whether they are dangling (unused by any container)
+func ListVolumes(ctx context.Context, filter VolumeFilter) ([]*volume.Volume, error) {
+	listFilters := filters.NewArgs()
+	if filter.Name != "" {
+		listFilters.Add("name", filter.Name)
+	}
+	if filter.Driver != "" {
+		listFilters.Add("driver", filter.Driver)
+	}
+	if filter.Dangling != nil {
+		listFilters.Add("dangling", strconv.FormatBool(*filter.Dangling))
+	}
+	addLabelFilters(listFilters, filter.Labels)
+
+	volumeList, err := retryResult(ctx, func() (volume.ListResponse, error) {
+		return DockerClient.VolumeList(ctx, volume.ListOptions{Filters: listFilters})
+	})
+	if err != nil {
+		return nil, errors.New("[ERR:] [DOCKER] => FAILED TO LIST VOLUMES => " + err.Error())
+	}
+	return volumeList.Volumes, nil
+}
+
+// PruneVolumes ~ Removes volumes unused by any container. Only anonymous volumes are pruned unless opts.All is set
+func PruneVolumes(ctx context.Context, opts VolumePruneOptions) (volume.PruneReport, error) {
+	pruneFilters := filters.NewArgs()
+	if opts.All {
+		pruneFilters.Add("all", "true")
+	}
+	addLabelFilters(pruneFilters, opts.Labels)
+
+	if dryRun(OpPruneVolumes, "", "", "all", strconv.FormatBool(opts.All)) {
+		return volume.PruneReport{}, nil
+	}
+	ctx, done := beginOperation(ctx, OpPruneVolumes, "", "", "all", strconv.FormatBool(opts.All))
+	pruneReport, pruneErr := DockerClient.VolumesPrune(ctx, pruneFilters)
+	done(pruneErr)
+	if pruneErr != nil {
+		return pruneReport, errors.New("[ERR:] [DOCKER] => FAILED TO PRUNE VOLUMES  | => " + pruneErr.Error())
+	}
+
+	return pruneReport, nil
+}
+
+// VolumeHelperImage ~ The image of the temporary container BackupVolume and RestoreVolume run tar in. It must provide sh and tar
+var VolumeHelperImage = "busybox:latest"
+
+// BackupVolume ~ Writes the contents of a volume to w as a tar archive, using a temporary helper container
+func BackupVolume(ctx context.Context, name string, w io.Writer) error {
+	if dryRun(OpBackupVolume, name, VolumeHelperImage) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpBackupVolume, name, VolumeHelperImage)
+	err := runVolumeHelper(ctx, name, true, []string{"tar", "-C", "/volume", "-cf", "-", "."}, nil, w)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO BACK UP VOLUME " + name + " => " + err.Error())
+	}
+	return nil
+}
+
+// RestoreVolume ~ Extracts a tar archive read from r into a volume, using a temporary helper container. Existing files are overwritten
+func RestoreVolume(ctx context.Context, name string, r io.Reader) error {
+	if dryRun(OpRestoreVolume, name, VolumeHelperImage) {
+		return nil
+	}
+	ctx, done := beginOperation(ctx, OpRestoreVolume, name, VolumeHelperImage)
+	err := runVolumeHelper(ctx, name, false, []string{"tar", "-C", "/volume", "-xf", "-"}, r, io.Discard)
+	done(err)
+	if err != nil {
+		return errors.New("[ERR:] [DOCKER] => FAILED TO RESTORE VOLUME " + name + " => " + err.Error())
+	}
+	return nil
+}
+
+// runVolumeHelper runs cmd in a throwaway container that mounts the volume at /volume, streaming stdin to it and its stdout to stdout
+func runVolumeHelper(ctx context.Context, name string, readOnly bool, cmd []string, stdin io.Reader, stdout io.Writer) error {
+	if err := EnsureImage(ctx, VolumeHelperImage); err != nil {
+		return err
+	}
+
+	cont, err := DockerClient.ContainerCreate(ctx, &container.Config{
+		Image:       VolumeHelperImage,
+		Cmd:         cmd,
+		OpenStdin:   stdin != nil,
+		StdinOnce:   stdin != nil,
+		AttachStdin: stdin != nil,
+		Labels:      withManagedLabel(nil),
+	}, &container.HostConfig{
+		Mounts: []mount.Mount{VolumeMount(name, "/volume", readOnly)},
+	}, nil, nil, "")
+	if err != nil {
+		return errors.New("FAILED TO CREATE HELPER CONTAINER => " + err.Error())
+	}
+	defer PurgeContainerContext(context.WithoutCancel(ctx), cont.ID)
+
+	// Attach before starting so no output is lost
+	resp, err := DockerClient.ContainerAttach(ctx, cont.ID, container.AttachOptions{
+		Stream: true,
+		Stdin:  stdin != nil,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return errors.New("FAILED TO ATTACH TO HELPER CONTAINER => " + err.Error())
+	}
+	defer resp.Close()
+
+	if err := DockerClient.ContainerStart(ctx, cont.ID, container.StartOptions{}); err != nil {
+		return errors.New("FAILED TO START HELPER CONTAINER => " + err.Error())
+	}
+
+	if stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	var errBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(stdout, &errBuf, resp.Reader); err != nil {
+		return errors.New("FAILED TO COPY HELPER OUTPUT => " + err.Error())
+	}
+
+	exitCode, err := WaitForExit(ctx, cont.ID)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return errors.New("HELPER EXITED WITH CODE " + fmt.Sprint(exitCode) + " => " + errBuf.String())
+	}
+	return nil
+}
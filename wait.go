@@ -0,0 +1,135 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// WaitForExit ~ Blocks until a container stops and returns its exit code
+func WaitForExit(ctx context.Context, containerID string) (int64, error) {
+	statusCh, errCh := DockerClient.ContainerWait(ctx, containerID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return -1, errors.New("[ERR:] [DOCKER] => FAILED TO WAIT FOR CONTAINER WITH ID: " + containerID + " => " + err.Error())
+	case status := <-statusCh:
+		if status.Error != nil {
+			return status.StatusCode, errors.New("[ERR:] [DOCKER] => FAILED TO WAIT FOR CONTAINER WITH ID: " + containerID + " => " + status.Error.Message)
+		}
+		return status.StatusCode, nil
+	}
+}
+
+// WaitForHealthy ~ Polls a container every interval (a second if not positive) until it reports healthy or the timeout expires
+func WaitForHealthy(ctx context.Context, containerID string, timeout time.Duration, interval time.Duration) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastLog := "NO HEALTHCHECK RESULTS YET"
+	for {
+		containerJSON, err := inspectContainer(ctx, containerID)
+		if err != nil && ctx.Err() == nil {
+			return errors.New("[ERR:] [DOCKER] => FAILED TO INSPECT CONTAINER WITH ID: " + containerID + " => " + err.Error())
+		}
+		if err == nil {
+			state := containerJSON.State
+			if state == nil {
+				return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " HAS NO STATE")
+			}
+			if state.Health == nil {
+				return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " HAS NO HEALTHCHECK")
+			}
+			if !state.Running {
+				return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " IS NOT RUNNING => " + state.Status)
+			}
+			if state.Health.Status == types.Healthy {
+				return nil
+			}
+			if n := len(state.Health.Log); n > 0 {
+				last := state.Health.Log[n-1]
+				lastLog = "EXIT CODE " + fmt.Sprint(last.ExitCode) + " => " + strings.TrimSpace(last.Output)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " DID NOT BECOME HEALTHY WITHIN " + timeout.String() + " => " + lastLog)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForLogLine ~ Follows the logs of a container until a line matches pattern or the timeout expires. Use regexp.QuoteMeta to match a plain substring
+func WaitForLogLine(ctx context.Context, containerID string, pattern *regexp.Regexp, timeout time.Duration) (LogLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var match LogLine
+	found := false
+	err := StreamContainerLogs(ctx, containerID, container.LogsOptions{}, func(line LogLine) {
+		if !found && pattern.MatchString(line.Message) {
+			match = line
+			found = true
+			cancel()
+		}
+	})
+	if found {
+		return match, nil
+	}
+	if err != nil {
+		return match, err
+	}
+	if ctx.Err() != nil {
+		return match, errors.New("[ERR:] [DOCKER] => NO LOG LINE OF CONTAINER WITH ID: " + containerID + " MATCHED " + pattern.String() + " WITHIN " + timeout.String())
+	}
+	return match, errors.New("[ERR:] [DOCKER] => CONTAINER WITH ID: " + containerID + " STOPPED BEFORE A LOG LINE MATCHED " + pattern.String())
+}
+
+// WaitForPort ~ Resolves the host port mapped to a container port (e.g. "5432/tcp") and dials it until it accepts connections or the timeout expires. Returns the dialed host address
+func WaitForPort(ctx context.Context, containerID string, containerPort string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	address := ""
+	lastErr := "PORT NOT PUBLISHED YET"
+	for {
+		if address == "" {
+			hostAddr, err := resolveHostAddress(ctx, containerID, containerPort)
+			if err != nil {
+				lastErr = err.Error()
+			}
+			address = hostAddr
+		}
+		if address != "" {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err == nil {
+				conn.Close()
+				return address, nil
+			}
+			lastErr = err.Error()
+		}
+
+		select {
+		case <-ctx.Done():
+			return address, errors.New("[ERR:] [DOCKER] => PORT " + containerPort + " OF CONTAINER WITH ID: " + containerID + " NOT READY WITHIN " + timeout.String() + " => " + lastErr)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package containers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+// Watchdog ~ Watches containers and restarts them (or runs a custom handler) when they turn unhealthy or die unexpectedly
+type Watchdog struct {
+	opts     WatchdogOptions
+	mu       sync.Mutex
+	restarts map[string]int
+	stopping map[string]bool
+	inFlight map[string]bool
+}
+
+// NewWatchdog ~ Creates a watchdog, see Watchdog.Run
+func NewWatchdog(opts WatchdogOptions) *Watchdog {
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+	return &Watchdog{
+		opts:     opts,
+		restarts: map[string]int{},
+		stopping: map[string]bool{},
+		inFlight: map[string]bool{},
+	}
+}
+
+// Run ~ Watches the daemon events until the context is cancelled. Containers stopped or killed on purpose are not restarted
+func (w *Watchdog) Run(ctx context.Context) error {
+	eventFilters := filters.NewArgs(filters.Arg("type", "container"))
+	for _, c := range w.opts.Containers {
+		eventFilters.Add("container", c)
+	}
+	addLabelFilters(eventFilters, w.opts.Labels)
+
+	var wg sync.WaitGroup
+	err := SubscribeEvents(ctx, eventFilters, func(event Event) {
+		if !w.shouldHandle(event) {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.handle(ctx, event)
+		}()
+	})
+	wg.Wait()
+	return err
+}
+
+// Restarts ~ Returns how many times the watchdog acted on a container
+func (w *Watchdog) Restarts(containerID string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.restarts[containerID]
+}
+
+// shouldHandle tracks intentional stops and reports whether an event needs action
+func (w *Watchdog) shouldHandle(event Event) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch event.Action {
+	case "kill", "stop", "pause":
+		w.stopping[event.ActorID] = true
+		return false
+	case "start", "unpause":
+		w.stopping[event.ActorID] = false
+		return false
+	case "die":
+		if w.stopping[event.ActorID] {
+			return false
+		}
+	case "health_status":
+		if event.HealthStatus != "unhealthy" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if w.inFlight[event.ActorID] {
+		return false
+	}
+	if w.opts.MaxRestarts > 0 && w.restarts[event.ActorID] >= w.opts.MaxRestarts {
+		if w.opts.OnGiveUp != nil {
+			go w.opts.OnGiveUp(event)
+		}
+		return false
+	}
+	w.inFlight[event.ActorID] = true
+	w.restarts[event.ActorID]++
+	return true
+}
+
+// handle waits for the backoff of a container and then restarts it or runs the custom handler
+func (w *Watchdog) handle(ctx context.Context, event Event) {
+	w.mu.Lock()
+	attempt := w.restarts[event.ActorID]
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.inFlight[event.ActorID] = false
+		w.mu.Unlock()
+	}()
+
+	delay := w.opts.Backoff
+	for i := 1; i < attempt && delay < w.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.opts.MaxBackoff {
+		delay = w.opts.MaxBackoff
+	}
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(delay):
+	}
+
+	var err error
+	if w.opts.Handler != nil {
+		err = w.opts.Handler(ctx, event)
+	} else {
+		err = RestartContainer(ctx, event.ActorID, 10*time.Second)
+	}
+	if err != nil && w.opts.OnError != nil {
+		w.opts.OnError(event, err)
+	}
+}
@@ -0,0 +1,203 @@
+package containers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+const (
+	// WebhookSignatureHeader ~ The header carrying "sha256=<hex HMAC of the body>" when the notifier has a secret
+	WebhookSignatureHeader = "X-Containers-Signature"
+	// WebhookEventHeader ~ The header carrying the event of a payload
+	WebhookEventHeader = "X-Containers-Event"
+	// WebhookDeliveryHeader ~ The header carrying the ID of a payload, the same for every retry
+	WebhookDeliveryHeader = "X-Containers-Delivery"
+)
+
+// webhookQueueSize ~ How many payloads wait for delivery per URL before new ones are dropped
+const webhookQueueSize = 256
+
+// WebhookNotifier ~ Posts JSON payloads to webhooks when containers start, stop, die or change health status
+type WebhookNotifier struct {
+	opts WebhookOptions
+}
+
+// NewWebhookNotifier ~ Creates a webhook notifier, see WebhookNotifier.Run
+func NewWebhookNotifier(opts WebhookOptions) *WebhookNotifier {
+	if len(opts.Actions) == 0 {
+		opts.Actions = []string{"start", "stop", "die", "health_status"}
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	return &WebhookNotifier{opts: opts}
+}
+
+// Run ~ Watches the daemon events and notifies the webhooks until the context is cancelled. Every URL has its own queue, so a slow
+// webhook delays no other and receives the payloads in order
+func (n *WebhookNotifier) Run(ctx context.Context) error {
+	eventFilters := filters.NewArgs(filters.Arg("type", "container"))
+	for _, action := range n.opts.Actions {
+		eventFilters.Add("event", action)
+	}
+	for _, c := range n.opts.Containers {
+		eventFilters.Add("container", c)
+	}
+	addLabelFilters(eventFilters, n.opts.Labels)
+
+	var wg sync.WaitGroup
+	queues := make([]chan WebhookPayload, len(n.opts.URLs))
+	for i, url := range n.opts.URLs {
+		queues[i] = make(chan WebhookPayload, webhookQueueSize)
+		wg.Add(1)
+		go func(url string, queue <-chan WebhookPayload) {
+			defer wg.Done()
+			for payload := range queue {
+				if err := n.deliver(ctx, url, payload); err != nil && n.opts.OnError != nil {
+					n.opts.OnError(url, payload, err)
+				}
+			}
+		}(url, queues[i])
+	}
+
+	err := SubscribeEvents(ctx, eventFilters, func(event Event) {
+		payload := webhookPayload(event)
+		for i, queue := range queues {
+			select {
+			case queue <- payload:
+			default:
+				if n.opts.OnError != nil {
+					n.opts.OnError(n.opts.URLs[i], payload, errors.New("[ERR:] [WEBHOOK] => QUEUE OF "+n.opts.URLs[i]+" IS FULL, PAYLOAD DROPPED"))
+				}
+			}
+		}
+	})
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	return err
+}
+
+// Notify ~ Posts a payload for event to every webhook right away, with retries. Returns the failures of all of them
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	payload := webhookPayload(event)
+	var failures []string
+	for _, url := range n.opts.URLs {
+		if err := n.deliver(ctx, url, payload); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New("[ERR:] [WEBHOOK] => FAILED TO NOTIFY " + payload.Event + " OF " + payload.ContainerID + " => " + strings.Join(failures, " | "))
+	}
+	return nil
+}
+
+// deliver posts a payload to url, retrying network errors, 429 and 5xx answers with backoff
+func (n *WebhookNotifier) deliver(ctx context.Context, url string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.New("[ERR:] [WEBHOOK] => FAILED TO ENCODE PAYLOAD " + payload.ID + " => " + err.Error())
+	}
+
+	backoff := n.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(ctx, url, payload, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.opts.MaxRetries {
+			return errors.New("[ERR:] [WEBHOOK] => FAILED TO DELIVER " + payload.Event + " OF " + payload.ContainerID + " TO " + url + " => " + err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("[ERR:] [WEBHOOK] => FAILED TO DELIVER " + payload.Event + " OF " + payload.ContainerID + " TO " + url + " => " + ctx.Err().Error())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying
+func (n *WebhookNotifier) post(ctx context.Context, url string, payload WebhookPayload, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, payload.Event)
+	req.Header.Set(WebhookDeliveryHeader, payload.ID)
+	if n.opts.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.opts.Secret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	res, err := n.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return retry, errors.New("WEBHOOK ANSWERED " + res.Status)
+}
+
+// webhookPayload turns a container event into the payload posted for it
+func webhookPayload(event Event) WebhookPayload {
+	id := make([]byte, 16)
+	rand.Read(id)
+	payload := WebhookPayload{
+		ID:           hex.EncodeToString(id),
+		Event:        event.Action,
+		ContainerID:  event.ActorID,
+		Name:         event.Name,
+		Image:        event.Image,
+		HealthStatus: event.HealthStatus,
+		Time:         event.Time,
+	}
+	if exitCode, err := strconv.Atoi(event.Attributes["exitCode"]); err == nil {
+		payload.ExitCode = &exitCode
+	}
+	// Besides the labels of the container the attributes hold the name, image and exit code, which have fields of their own
+	for key, value := range event.Attributes {
+		if key == "name" || key == "image" || key == "exitCode" || key == "execDuration" || key == "signal" {
+			continue
+		}
+		if payload.Labels == nil {
+			payload.Labels = map[string]string{}
+		}
+		payload.Labels[key] = value
+	}
+	return payload
+}